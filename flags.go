@@ -0,0 +1,507 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/sapslaj/eks-pricing-exporter/pkg/app"
+	"github.com/sapslaj/eks-pricing-exporter/pkg/collector"
+	"github.com/sapslaj/eks-pricing-exporter/pkg/model"
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing"
+)
+
+// rawFlags holds the flag values which are parsed into app.Options once all flags have been parsed.
+type rawFlags struct {
+	port                  int
+	podBindingStrategy    string
+	systemOverhead        string
+	attributionBasis      string
+	defaultCapacityType   string
+	capacityTypeLabels    string
+	excludeNamespaces     string
+	debugInstanceTypes    string
+	instanceTypeAllowlist string
+	instanceTypeDenylist  string
+	licenseModels         string
+	fargateRegions        string
+	metricOverrides       string
+	renameLabels          string
+	pricingFilters        string
+}
+
+// parseFlags parses the command line flags into app.Options.
+func parseFlags() (app.Options, error) {
+	var opts app.Options
+	var raw rawFlags
+	registerServerFlags(&opts, &raw)
+	registerMetricFlags(&opts, &raw)
+	registerNodeFlags(&opts, &raw)
+	registerRefreshFlags(&opts)
+	registerPricingFlags(&opts, &raw)
+	registerAWSFlags(&opts)
+	flag.Parse()
+
+	explicit := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+	if explicit["port"] && !explicit["listen-address"] {
+		opts.ListenAddress = fmt.Sprintf(":%d", raw.port)
+	}
+	return opts, raw.apply(&opts)
+}
+
+// registerServerFlags registers the flags of how the metrics are served, written or pushed.
+func registerServerFlags(opts *app.Options, raw *rawFlags) {
+	flag.StringVar(
+		&opts.ListenAddress,
+		"listen-address",
+		":9523",
+		"host:port to run exporter on, IPv6 addresses must be bracketed, e.g. [::]:9523",
+	)
+	flag.IntVar(&raw.port, "port", 9523, "port to run exporter on, deprecated in favor of -listen-address")
+	flag.DurationVar(&opts.ReadTimeout, "read-timeout", app.DefaultReadTimeout, "maximum duration for reading a request")
+	flag.DurationVar(
+		&opts.WriteTimeout,
+		"write-timeout",
+		app.DefaultWriteTimeout,
+		"maximum duration for collecting metrics and writing the response, longer than collecting takes",
+	)
+	flag.DurationVar(
+		&opts.IdleTimeout,
+		"idle-timeout",
+		app.DefaultIdleTimeout,
+		"how long to keep idle keep-alive connections open",
+	)
+	flag.IntVar(
+		&opts.MetricsCompression,
+		"metrics-compression",
+		app.MetricsCompressionDefault,
+		"gzip level to compress /metrics with for scrapers which accept it, from 1 (fastest) to 9 (smallest), "+
+			"0 for the default or -1 to disable compression",
+	)
+	flag.StringVar(
+		&opts.TextfileOutput,
+		"textfile-output",
+		"",
+		"path of a .prom file to write the metrics to on every refresh interval, for the node-exporter textfile collector",
+	)
+	flag.StringVar(
+		&opts.PushgatewayURL,
+		"pushgateway-url",
+		"",
+		"URL of a Pushgateway to push the metrics to once and exit instead of serving them, e.g. http://pushgateway:9091",
+	)
+	flag.StringVar(
+		&opts.PushgatewayJob,
+		"pushgateway-job",
+		app.DefaultPushgatewayJob,
+		"job to push the metrics to the Pushgateway under, replacing the metrics last pushed under it",
+	)
+	flag.StringVar(
+		&opts.OTLPEndpoint,
+		"otlp-endpoint",
+		"",
+		"URL of an OTLP/HTTP endpoint to also export node price and info metrics to, e.g. http://otel-collector:4318",
+	)
+}
+
+// registerMetricFlags registers the flags of which metrics are emitted and how.
+func registerMetricFlags(opts *app.Options, raw *rawFlags) {
+	flag.IntVar(
+		&opts.MaxSeries,
+		"max-series",
+		0,
+		"maximum number of per-node series to emit before falling back to aggregates only, 0 for no limit",
+	)
+	flag.IntVar(
+		&opts.MaxInstanceTypeSeries,
+		"max-instance-type-series",
+		0,
+		"maximum number of per-instance type pricing series to emit at all, 0 for no limit",
+	)
+	flag.BoolVar(
+		&opts.UnitSuffixes,
+		"unit-suffixes",
+		false,
+		"suffix price metric names with their currency, e.g. eks_node_hourly_price_usd or _cny in the China regions",
+	)
+	flag.BoolVar(
+		&opts.SpotPriceHistogram,
+		"spot-price-histogram",
+		false,
+		"also emit the prices of spot nodes as the native histogram eks_spot_price_distribution",
+	)
+	flag.BoolVar(
+		&opts.MonthlyPrices,
+		"monthly-prices",
+		false,
+		"also emit node and cluster prices per month of 730 hours as eks_node_monthly_price and eks_cluster_monthly_price",
+	)
+	flag.BoolVar(
+		&opts.FargateAccumulatedCost,
+		"fargate-accumulated-cost",
+		false,
+		"also emit the cost of each Fargate pod since it started as eks_fargate_pod_accumulated_cost_total",
+	)
+	flag.StringVar(
+		&raw.debugInstanceTypes,
+		"debug-instance-types",
+		"",
+		"comma separated instance types to emit the resolved prices of as eks_debug_price, e.g. m5.large,c6g.xlarge",
+	)
+	flag.IntVar(
+		&opts.PricePrecision,
+		"price-precision",
+		0,
+		"number of decimal places to round emitted prices to, 0 for no rounding",
+	)
+	flag.BoolVar(
+		&opts.DisableNodeMetrics,
+		"disable-node-metrics",
+		false,
+		"only export the metrics of the pricing data and AWS API calls, leaving out the node, pod and cluster metrics",
+	)
+	flag.BoolVar(
+		&opts.DisablePricingMetrics,
+		"disable-pricing-metrics",
+		false,
+		"only export the node, pod and cluster metrics, leaving out the metrics of the pricing data and AWS API calls",
+	)
+	flag.StringVar(
+		&raw.metricOverrides,
+		"metric-overrides",
+		"",
+		"path of a JSON file with help text by metric name and label names by default label name to emit metrics with, "+
+			`e.g. {"help": {"eks_node_hourly_price": "..."}, "labels": {"zone": "availability_zone"}}`,
+	)
+	flag.StringVar(
+		&raw.renameLabels,
+		"rename-labels",
+		"",
+		"comma separated label renames, e.g. zone=availability_zone, taking precedence over -metric-overrides",
+	)
+}
+
+// registerNodeFlags registers the flags of how nodes are priced and their prices attributed to pods.
+func registerNodeFlags(opts *app.Options, raw *rawFlags) {
+	flag.BoolVar(&opts.ExcludeCordoned, "exclude-cordoned", false, "exclude cordoned nodes from price metrics and totals")
+	flag.DurationVar(
+		&opts.NodeGracePeriod,
+		"node-grace-period",
+		0,
+		"skip nodes younger than this from the unknown price metrics while their labels are filled in, e.g. 60s",
+	)
+	flag.IntVar(&opts.CollectorWorkers, "collector-workers", 8, "number of nodes to price and collect concurrently")
+	flag.StringVar(
+		&raw.podBindingStrategy,
+		"pod-binding-strategy",
+		string(model.PodBindingActive),
+		"which scheduled pods count towards node usage and price attribution, active (pending and running) or all",
+	)
+	flag.StringVar(
+		&raw.systemOverhead,
+		"system-overhead",
+		string(model.SystemOverheadNone),
+		"how DaemonSet and kube-system pod prices are handled, none, proportional (spread over workload pods) or separate",
+	)
+	flag.StringVar(
+		&raw.attributionBasis,
+		"attribution-basis",
+		string(model.AttributionBasisRequests),
+		"which pod resources node prices are attributed to pods by, requests or limits",
+	)
+	flag.BoolVar(
+		&opts.WorkloadLabels,
+		"workload-labels",
+		false,
+		"add the workload_kind and workload labels of the controller managing each pod to eks_pod_hourly_price",
+	)
+	flag.StringVar(
+		&raw.excludeNamespaces,
+		"exclude-namespaces",
+		"",
+		"comma separated namespaces to leave out of pod prices and the namespace and billable totals, e.g. kube-system",
+	)
+	flag.BoolVar(
+		&opts.ExcludeFargateTypes,
+		"exclude-fargate-instance-types",
+		false,
+		"leave Fargate nodes out of eks_cluster_distinct_instance_types",
+	)
+	flag.StringVar(
+		&raw.instanceTypeAllowlist,
+		"instance-type-allowlist",
+		"",
+		"comma separated instance type patterns, e.g. m5.*,c6g.*, to only emit price metrics for nodes matching them",
+	)
+	flag.StringVar(
+		&raw.instanceTypeDenylist,
+		"instance-type-denylist",
+		"",
+		"comma separated instance type patterns, e.g. t3.*, to leave nodes matching them out of the price metrics",
+	)
+	flag.StringVar(
+		&raw.capacityTypeLabels,
+		"capacity-type-labels",
+		"",
+		"comma separated node labels to read the capacity type of nodes without EKS or Karpenter labels from, "+
+			"e.g. node.kubernetes.io/lifecycle",
+	)
+	flag.StringVar(
+		&raw.defaultCapacityType,
+		"default-capacity-type",
+		"",
+		"capacity type of nodes without any capacity type label, on-demand or spot, e.g. for self-managed nodes",
+	)
+	flag.Float64Var(
+		&opts.HybridHourlyPrice,
+		"hybrid-hourly-price",
+		0,
+		"flat hourly price of EKS Hybrid Nodes, which have no EC2 price, e.g. the amortized cost of on-premises hardware",
+	)
+	flag.BoolVar(
+		&opts.PendingNodeClaims,
+		"pending-nodeclaims",
+		false,
+		"price Karpenter NodeClaims which haven't registered a node yet, needs permission to list nodeclaims.karpenter.sh",
+	)
+}
+
+// registerRefreshFlags registers the flags of how often pricing is refreshed and how spot prices are kept.
+func registerRefreshFlags(opts *app.Options) {
+	flag.DurationVar(&opts.RefreshInterval, "refresh-interval", 0, "how often to refresh pricing, 0 for one hour")
+	flag.DurationVar(
+		&opts.SpotRefreshInterval,
+		"spot-refresh-interval",
+		0,
+		"how often to refresh spot pricing on its own schedule, 0 to refresh it every -refresh-interval",
+	)
+	flag.DurationVar(
+		&opts.FargateRefreshInterval,
+		"fargate-refresh-interval",
+		0,
+		"how often to refresh Fargate pricing on its own schedule, 0 to refresh it every -refresh-interval",
+	)
+	flag.BoolVar(
+		&opts.StaggerRefresh,
+		"stagger-refresh",
+		false,
+		"refresh on-demand, spot and Fargate pricing a third of their interval apart instead of all at once",
+	)
+	flag.BoolVar(
+		&opts.ScopeToClusterZones,
+		"scope-to-cluster-zones",
+		false,
+		"only fetch spot prices for the zones of the nodes in the cluster, looked up again before each spot refresh",
+	)
+	flag.DurationVar(
+		&opts.SpotPriceTTL,
+		"spot-price-ttl",
+		0,
+		"how long to keep a spot price missing from refreshes before dropping it, 0 for three spot refresh intervals",
+	)
+	flag.Float64Var(
+		&opts.SpotPriceSmoothing,
+		"spot-price-smoothing",
+		0,
+		"weight of the newest spot price between 0 and 1 in the moving average emitted as eks_node_spot_price_smoothed, "+
+			"0 to disable it",
+	)
+	flag.IntVar(
+		&opts.MaxSpotPricePages,
+		"max-spot-price-pages",
+		0,
+		"maximum number of spot price history pages to fetch per refresh, 0 for no limit",
+	)
+	flag.StringVar(
+		&opts.SpotPriceChangeWebhookURL,
+		"spot-price-change-webhook-url",
+		"",
+		"URL to POST significant spot price changes to",
+	)
+	flag.Float64Var(
+		&opts.SpotPriceChangeThreshold,
+		"spot-price-change-threshold",
+		20,
+		"minimum spot price change in percent to notify the spot price change webhook about",
+	)
+}
+
+// registerPricingFlags registers the flags of which prices are fetched and where from.
+func registerPricingFlags(opts *app.Options, raw *rawFlags) {
+	flag.StringVar(
+		&raw.licenseModels,
+		"license-models",
+		"",
+		"comma separated license included operating systems to fetch on-demand prices for, windows or rhel",
+	)
+	flag.StringVar(
+		&raw.fargateRegions,
+		"fargate-regions",
+		"",
+		"comma separated regions to additionally fetch Fargate pricing for, used for Fargate nodes in those regions",
+	)
+	flag.BoolVar(
+		&opts.CostExplorer,
+		"cost-explorer",
+		false,
+		"use effective on-demand rates from Cost Explorer including Reserved Instance and Savings Plan discounts",
+	)
+	flag.BoolVar(
+		&opts.StaticFallback,
+		"static-fallback",
+		false,
+		"fall back to the static pricing snapshot for pricing the AWS APIs fail to return instead of failing to start",
+	)
+	flag.BoolVar(
+		&opts.SeedFromStatic,
+		"seed-from-static",
+		false,
+		"price nodes with the static pricing snapshot at startup while the initial AWS pricing update runs in the background",
+	)
+	flag.BoolVar(
+		&opts.PriceDrift,
+		"price-drift",
+		false,
+		"periodically compare the on-demand prices in use with live AWS prices and report eks_price_drift_ratio",
+	)
+	flag.StringVar(
+		&raw.pricingFilters,
+		"pricing-filters",
+		"",
+		"comma separated overrides of the AWS pricing API product filters, e.g. operatingSystem=Linux,capacityStatus=Used",
+	)
+	flag.BoolVar(
+		&opts.DescribeInstances,
+		"describe-instances",
+		true,
+		"look up the instance type and zone of nodes missing the labels for them using ec2:DescribeInstances",
+	)
+}
+
+// registerAWSFlags registers the flags of how the AWS APIs are called.
+func registerAWSFlags(opts *app.Options) {
+	flag.StringVar(
+		&opts.AWSHTTPProxy,
+		"aws-http-proxy",
+		"",
+		"URL of an HTTP proxy to send AWS API requests through, HTTPS_PROXY is honored if unset",
+	)
+	flag.StringVar(
+		&opts.AWSRegion,
+		"region",
+		"",
+		"AWS region of the cluster, defaults to the region of the AWS environment",
+	)
+	flag.StringVar(
+		&opts.AssumeRoleARN,
+		"assume-role-arn",
+		"",
+		"ARN of an IAM role to assume for all AWS API requests, e.g. one in the account the cluster runs in",
+	)
+	flag.IntVar(
+		&opts.AWSMaxRetries,
+		"aws-max-retries",
+		0,
+		"maximum number of retries of failed AWS API calls, 0 for the SDK default",
+	)
+	flag.StringVar(
+		&opts.PricingEndpoint,
+		"pricing-endpoint",
+		"",
+		"URL to send AWS pricing API requests to instead of AWS, e.g. for localstack or a pricing proxy",
+	)
+	flag.BoolVar(
+		&opts.PricingRegionFallback,
+		"pricing-region-fallback",
+		false,
+		"use the us-east-1 pricing API if the pricing API region closest to the cluster is unreachable",
+	)
+	flag.StringVar(&opts.EC2Endpoint, "ec2-endpoint", "", "URL to send EC2 API requests to instead of AWS")
+}
+
+// apply parses the raw flag values into opts.
+func (raw *rawFlags) apply(opts *app.Options) error {
+	opts.CapacityTypeLabels = splitList(raw.capacityTypeLabels)
+	opts.ExcludeNamespaces = splitList(raw.excludeNamespaces)
+	opts.DebugInstanceTypes = splitList(raw.debugInstanceTypes)
+	opts.InstanceTypeAllowlist = splitList(raw.instanceTypeAllowlist)
+	opts.InstanceTypeDenylist = splitList(raw.instanceTypeDenylist)
+	opts.FargateRegions = splitList(raw.fargateRegions)
+	if err := raw.applyNodes(opts); err != nil {
+		return err
+	}
+	if err := raw.applyPricing(opts); err != nil {
+		return err
+	}
+	return raw.applyMetricOverrides(opts)
+}
+
+// applyNodes parses the flags of how nodes are priced and their prices attributed to pods.
+func (raw *rawFlags) applyNodes(opts *app.Options) error {
+	var err error
+	if opts.PodBindingStrategy, err = model.ParsePodBindingStrategy(raw.podBindingStrategy); err != nil {
+		return err
+	}
+	if opts.SystemOverhead, err = model.ParseSystemOverhead(raw.systemOverhead); err != nil {
+		return err
+	}
+	if opts.AttributionBasis, err = model.ParseAttributionBasis(raw.attributionBasis); err != nil {
+		return err
+	}
+	if raw.defaultCapacityType != "" {
+		if opts.DefaultCapacityType, err = model.ParseCapacityType(raw.defaultCapacityType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyPricing parses the flags of which prices are fetched.
+func (raw *rawFlags) applyPricing(opts *app.Options) error {
+	for _, name := range splitList(raw.licenseModels) {
+		licenseModel, err := pricing.ParseLicenseModel(name)
+		if err != nil {
+			return err
+		}
+		opts.LicenseModels = append(opts.LicenseModels, licenseModel)
+	}
+	var err error
+	opts.PricingFilters, err = pricing.ParsePricingFilters(splitList(raw.pricingFilters))
+	return err
+}
+
+// applyMetricOverrides loads the metric overrides file and applies the label renames on top of it.
+func (raw *rawFlags) applyMetricOverrides(opts *app.Options) error {
+	if raw.metricOverrides != "" {
+		metricOverrides, err := collector.LoadMetricOverrides(raw.metricOverrides)
+		if err != nil {
+			return err
+		}
+		opts.MetricOverrides = metricOverrides
+	}
+	renamedLabels, err := collector.ParseLabelRenames(splitList(raw.renameLabels))
+	if err != nil {
+		return err
+	}
+	for label, name := range renamedLabels {
+		if opts.MetricOverrides.Labels == nil {
+			opts.MetricOverrides.Labels = map[string]string{}
+		}
+		opts.MetricOverrides.Labels[label] = name
+	}
+	return nil
+}
+
+// splitList splits a comma separated flag value, ignoring empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
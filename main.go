@@ -2,104 +2,105 @@ package main
 
 import (
 	"context"
-	"errors"
 	"flag"
 	"fmt"
 	"log"
-	"net"
-	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
-	"time"
 
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
-	"k8s.io/client-go/kubernetes"
-	ctrl "sigs.k8s.io/controller-runtime"
-
-	"github.com/sapslaj/eks-pricing-exporter/pkg/collector"
-	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing"
+	"github.com/sapslaj/eks-pricing-exporter/pkg/app"
 )
 
 func main() {
-	port := flag.Int("port", 9523, "port to run exporter on")
-
-	flag.Parse()
+	opts, err := parseFlags()
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	go handleSigterm(cancel)
 
-	cs := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
-	cfg, err := config.LoadDefaultConfig(ctx)
+	err = run(ctx, opts)
 	if err != nil {
-		log.Fatalf("loading aws config: %s", err)
+		log.Fatal(err)
 	}
+}
 
-	pricingProvider := pricing.NewAWSProvider(cfg)
-	// sanity check
-	_, err = pricingProvider.GetFargatePricing(ctx)
-	if err != nil {
-		log.Fatalf("could not load AWS pricing data: %s", err)
+// run runs the subcommand given on the command line, serving the metrics if there is none.
+func run(ctx context.Context, opts app.Options) error {
+	if flag.Arg(0) == "generate-static-prices" {
+		if flag.NArg() != 2 {
+			return fmt.Errorf("usage: %s [flags] generate-static-prices <file>", os.Args[0])
+		}
+		return generateStaticPrices(ctx, flag.Arg(1), opts)
 	}
-	pricingRepository := pricing.NewRepository(pricingProvider)
-	log.Printf("updating pricing...")
-	err = pricingRepository.UpdatePricing(ctx)
+
+	a, err := app.New(ctx, opts)
 	if err != nil {
-		log.Fatalf("could not update pricing repository: %s", err)
+		if ctx.Err() != nil {
+			// terminated before the initial pricing update finished
+			log.Printf("shutting down: %s", err)
+			return nil
+		}
+		return err
 	}
 
-	prometheus.MustRegister(collector.NewCollector(ctx, cs, pricingRepository))
-
-	mux := http.NewServeMux()
-	mux.Handle("/metrics", promhttp.Handler())
-	mux.HandleFunc("/admin/pricing/update", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPost {
-			w.WriteHeader(http.StatusBadRequest)
-			fmt.Fprintln(w, "Only POST method is allowed on this endpoint.")
-			return
+	if flag.Arg(0) == "price-node" {
+		if flag.NArg() != 2 {
+			return fmt.Errorf("usage: %s [flags] price-node <nodename>", os.Args[0])
 		}
-		log.Println("updating pricing via /admin/pricing/update")
-		err := pricingRepository.UpdatePricing(r.Context())
+		err := a.PriceNode(ctx, os.Stdout, flag.Arg(1))
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			fmt.Fprintf(w, "error updating pricing: %s", err)
-			return
+			return fmt.Errorf("could not price node: %w", err)
 		}
-		fmt.Fprintln(w, "success")
-	})
-
-	addr := fmt.Sprintf(":%d", *port)
+		return nil
+	}
 
-	server := &http.Server{
-		Addr:        addr,
-		Handler:     mux,
-		BaseContext: func(_ net.Listener) context.Context { return ctx },
-		ReadTimeout: time.Minute,
+	if opts.PushgatewayURL != "" {
+		err := a.Push(ctx)
+		if err != nil {
+			return fmt.Errorf("could not push metrics: %w", err)
+		}
+		return nil
 	}
 
-	log.Printf("Starting eks-pricing-exporter/%s on %s", VERSION, addr)
+	log.Printf("Starting eks-pricing-exporter/%s on %s", VERSION, opts.ListenAddress)
+	return a.Serve(ctx)
+}
 
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-time.Tick(1 * time.Hour):
-				log.Println("updating pricing on schedule")
-				err := pricingRepository.UpdatePricing(ctx)
-				if err != nil {
-					log.Fatalf("could not update pricing repository: %s", err)
-				}
-			}
+// generateStaticPrices writes a static pricing snapshot to path. The snapshot is written to a temporary file next to
+// path first and renamed into place, so a failure never leaves a truncated file behind.
+func generateStaticPrices(ctx context.Context, path string, opts app.Options) (err error) {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("could not create %s: %w", path, err)
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
 		}
 	}()
-
-	err = server.ListenAndServe()
-	if !errors.Is(err, http.ErrServerClosed) {
-		log.Fatalf("error running server: %s", err)
+	// CreateTemp only grants the owner access, give the snapshot the permissions os.Create would have
+	err = f.Chmod(0o644)
+	if err != nil {
+		return fmt.Errorf("could not create %s: %w", path, err)
+	}
+	err = app.GenerateStaticPrices(ctx, f, opts)
+	if err != nil {
+		return fmt.Errorf("could not generate static prices: %w", err)
+	}
+	err = f.Close()
+	if err != nil {
+		return fmt.Errorf("could not write %s: %w", path, err)
+	}
+	err = os.Rename(f.Name(), path)
+	if err != nil {
+		return fmt.Errorf("could not replace %s: %w", path, err)
 	}
+	return nil
 }
 
 func handleSigterm(cancel func()) {
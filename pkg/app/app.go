@@ -0,0 +1,950 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/samber/lo"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/sapslaj/eks-pricing-exporter/pkg/collector"
+	"github.com/sapslaj/eks-pricing-exporter/pkg/model"
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing"
+)
+
+// Default timeouts of the HTTP server. Collecting metrics can take up to five minutes on large clusters, so the write
+// timeout leaves room for both collecting and sending a large /metrics response.
+const (
+	DefaultReadTimeout  = time.Minute
+	DefaultWriteTimeout = 10 * time.Minute
+	DefaultIdleTimeout  = 2 * time.Minute
+)
+
+// maxLookupBatchBytes limits the size of the request body accepted by /admin/pricing/lookup-batch.
+const maxLookupBatchBytes = 1 << 20
+
+// Options configures the exporter. The zero value is usable: everything that is not injected is loaded from the
+// environment the same way the eks-pricing-exporter binary does.
+type Options struct {
+	// ListenAddress is the host:port to serve on, defaults to ":9523". IPv6 addresses must be bracketed, e.g.
+	// "[::]:9523". Ignored if Listener is set.
+	ListenAddress string
+	// Listener, if set, is used to serve instead of listening on ListenAddress.
+	Listener net.Listener
+	// MetricsCompression is the gzip level metrics are compressed with for scrapers which accept gzip, from
+	// gzip.BestSpeed to gzip.BestCompression, or MetricsCompressionDefault or MetricsCompressionDisabled.
+	MetricsCompression int
+	// ReadTimeout, WriteTimeout and IdleTimeout are the timeouts of the HTTP server for reading a request, writing a
+	// response and keeping an idle connection open, defaulting to DefaultReadTimeout, DefaultWriteTimeout and
+	// DefaultIdleTimeout.
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+
+	// KubernetesClient is the client used to list nodes and pods, defaults to a client for the kubeconfig of the
+	// -kubeconfig flag registered by controller-runtime, or else the in-cluster or default kubeconfig.
+	KubernetesClient kubernetes.Interface
+	// DynamicClient is the client used to list Karpenter NodeClaims if PendingNodeClaims is set, defaults to a client
+	// for the same cluster as KubernetesClient.
+	DynamicClient dynamic.Interface
+	// PendingNodeClaims prices Karpenter NodeClaims which haven't registered a node yet. This needs permission to list
+	// nodeclaims.karpenter.sh.
+	PendingNodeClaims bool
+	// AWSConfig is the AWS configuration to use, defaults to the default config chain.
+	AWSConfig *aws.Config
+	// PricingProvider, if set, is used instead of the AWS pricing and EC2 APIs.
+	PricingProvider pricing.Provider
+	// CostExplorer uses effective on-demand rates from Cost Explorer, which include Reserved Instance and Savings Plan
+	// discounts, instead of public on-demand prices. Ignored if PricingProvider is set.
+	CostExplorer bool
+	// StaticFallback falls back to the static pricing snapshot for any pricing type the AWS APIs fail to return,
+	// instead of failing to start. Ignored if PricingProvider is set.
+	StaticFallback bool
+	// PriceDrift periodically fetches live on-demand prices from AWS and reports the drift of the on-demand prices in
+	// use from them, e.g. to see how stale the snapshot of a StaticProvider has become.
+	PriceDrift bool
+	// PricingFilters overrides the attribute values used to select EC2 products from the AWS pricing API.
+	PricingFilters pricing.PricingFilters
+
+	// Registerer and Gatherer default to the prometheus default registry.
+	Registerer prometheus.Registerer
+	Gatherer   prometheus.Gatherer
+
+	// RefreshInterval is how often pricing is refreshed, defaults to one hour.
+	RefreshInterval time.Duration
+	// SpotRefreshInterval and FargateRefreshInterval refresh spot and Fargate pricing on their own schedule, leaving
+	// RefreshInterval for the other pricing types. Zero refreshes them every RefreshInterval.
+	SpotRefreshInterval    time.Duration
+	FargateRefreshInterval time.Duration
+	// StaggerRefresh refreshes on-demand, spot and Fargate pricing on their own schedules, offset by a third of their
+	// interval from each other, e.g. at :00, :20 and :40 every hour, rather than refreshing everything at once.
+	StaggerRefresh bool
+	// ScopeToClusterZones only fetches spot prices for the zones of the nodes in the cluster, if the pricing provider
+	// supports it. The zones are looked up again before each spot refresh, so nodes launched in other zones have no
+	// spot price until the next one.
+	ScopeToClusterZones bool
+	// SpotPriceTTL is how long a spot price missing from refreshes is kept, defaults to three spot refresh intervals.
+	SpotPriceTTL time.Duration
+	// SpotPriceSmoothing is the weight of the newest spot price in the moving average of the spot prices emitted next to
+	// the raw prices, between 0 and 1. 0 disables the moving average.
+	SpotPriceSmoothing float64
+	// DisableNodeMetrics and DisablePricingMetrics leave out the node, pod and cluster metrics or the metrics of the
+	// pricing data and AWS API calls, for scrape targets which only need the other. At most one can be set.
+	DisableNodeMetrics    bool
+	DisablePricingMetrics bool
+	// SeedFromStatic prices nodes with the static pricing snapshot until the initial pricing update finishes, which then
+	// runs in the background instead of delaying startup.
+	SeedFromStatic bool
+	// PushgatewayURL is the URL of the Pushgateway Push pushes the metrics to, e.g. http://pushgateway:9091.
+	// PushgatewayJob is the job they are pushed under, defaulting to DefaultPushgatewayJob.
+	PushgatewayURL string
+	PushgatewayJob string
+	// MetricOverrides replaces the help text and renames the labels of the metrics of the collector.
+	MetricOverrides collector.MetricOverrides
+
+	MaxSeries                 int
+	MaxInstanceTypeSeries     int
+	ExcludeCordoned           bool
+	UnitSuffixes              bool
+	SpotPriceHistogram        bool
+	MonthlyPrices             bool
+	FargateAccumulatedCost    bool
+	NodeGracePeriod           time.Duration
+	CollectorWorkers          int
+	PodBindingStrategy        model.PodBindingStrategy
+	CapacityTypeLabels        []string
+	DefaultCapacityType       model.NodeCapacityType
+	HybridHourlyPrice         float64
+	SystemOverhead            model.SystemOverhead
+	AttributionBasis          model.AttributionBasis
+	WorkloadLabels            bool
+	ExcludeNamespaces         []string
+	DebugInstanceTypes        []string
+	PricePrecision            int
+	ExcludeFargateTypes       bool
+	InstanceTypeAllowlist     []string
+	InstanceTypeDenylist      []string
+	OTLPEndpoint              string
+	TextfileOutput            string
+	LicenseModels             []pricing.LicenseModel
+	FargateRegions            []string
+	MaxSpotPricePages         int
+	DescribeInstances         bool
+	AWSHTTPProxy              string
+	AWSRegion                 string
+	AssumeRoleARN             string
+	AWSMaxRetries             int
+	PricingEndpoint           string
+	PricingRegionFallback     bool
+	EC2Endpoint               string
+	SpotPriceChangeWebhookURL string
+	SpotPriceChangeThreshold  float64
+}
+
+// App is a configured exporter with an up to date pricing repository.
+type App struct {
+	opts              Options
+	cs                kubernetes.Interface
+	awsConfig         *aws.Config
+	apiMetrics        *pricing.APIMetrics
+	pricingRepository *pricing.Repository
+	// initialUpdate is the initial pricing update running in the background if SeedFromStatic is set, and the initial
+	// price drift update if PriceDrift is set
+	initialUpdate sync.WaitGroup
+	// collector is the collector of the metrics served by Handler
+	collector *collector.Collector
+	// zoneScopedProvider is the pricing provider to scope to the zones of the cluster before each spot refresh if
+	// ScopeToClusterZones is set and the provider supports it
+	zoneScopedProvider pricing.ZoneScopedProvider
+	// exporterMetrics holds only the collectors of the exporter, without the Go runtime and process metrics of the
+	// default registry, for the textfile output and the Pushgateway
+	exporterMetrics *prometheus.Registry
+}
+
+// New loads configuration, builds the pricing repository and performs the initial pricing update, in the background
+// if SeedFromStatic is set. The initial price drift update always runs in the background.
+func New(ctx context.Context, opts Options) (*App, error) {
+	if err := opts.setDefaults(); err != nil {
+		return nil, err
+	}
+
+	a := &App{
+		opts:       opts,
+		cs:         opts.KubernetesClient,
+		apiMetrics: pricing.NewAPIMetrics(),
+	}
+	if a.cs == nil {
+		restConfig, err := ctrl.GetConfig()
+		if err != nil {
+			return nil, fmt.Errorf("loading kubernetes config: %w", err)
+		}
+		a.cs, err = kubernetes.NewForConfig(restConfig)
+		if err != nil {
+			return nil, fmt.Errorf("creating kubernetes client: %w", err)
+		}
+	}
+
+	pricingProvider, err := a.newPricingProvider(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if scoped, ok := pricingProvider.(pricing.ZoneScopedProvider); ok && opts.ScopeToClusterZones {
+		a.zoneScopedProvider = scoped
+		if err := a.scopeToClusterZones(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	repositoryOpts, err := a.repositoryOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	a.pricingRepository = pricing.NewRepository(pricingProvider, repositoryOpts...)
+	if err := a.updateInitialPricing(ctx); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// setDefaults validates opts and fills in the defaults of unset options.
+func (opts *Options) setDefaults() error {
+	if opts.ListenAddress == "" {
+		opts.ListenAddress = ":9523"
+	}
+	if opts.ReadTimeout == 0 {
+		opts.ReadTimeout = DefaultReadTimeout
+	}
+	if opts.WriteTimeout == 0 {
+		opts.WriteTimeout = DefaultWriteTimeout
+	}
+	if opts.IdleTimeout == 0 {
+		opts.IdleTimeout = DefaultIdleTimeout
+	}
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	if opts.Registerer == nil {
+		opts.Registerer = prometheus.DefaultRegisterer
+	}
+	if opts.Gatherer == nil {
+		opts.Gatherer = prometheus.DefaultGatherer
+	}
+	if opts.RefreshInterval == 0 {
+		opts.RefreshInterval = time.Hour
+	}
+	if opts.PushgatewayJob == "" {
+		opts.PushgatewayJob = DefaultPushgatewayJob
+	}
+	if opts.SpotPriceTTL == 0 {
+		opts.SpotPriceTTL = 3 * opts.RefreshInterval
+		if opts.SpotRefreshInterval != 0 {
+			opts.SpotPriceTTL = 3 * opts.SpotRefreshInterval
+		}
+	}
+	return nil
+}
+
+// validate returns an error if any of opts are invalid.
+func (opts *Options) validate() error {
+	if opts.Listener == nil {
+		if err := ValidateListenAddress(opts.ListenAddress); err != nil {
+			return err
+		}
+	}
+	if err := ValidateMetricsCompression(opts.MetricsCompression); err != nil {
+		return err
+	}
+	for _, patterns := range [][]string{opts.InstanceTypeAllowlist, opts.InstanceTypeDenylist} {
+		if err := ValidateInstanceTypePatterns(patterns); err != nil {
+			return err
+		}
+	}
+	if err := ValidateSpotPriceSmoothing(opts.SpotPriceSmoothing); err != nil {
+		return err
+	}
+	if err := opts.MetricOverrides.Validate(); err != nil {
+		return err
+	}
+	if opts.DisableNodeMetrics && opts.DisablePricingMetrics {
+		return errors.New("node and pricing metrics can't both be disabled")
+	}
+	return nil
+}
+
+// newPricingProvider returns the PricingProvider option, or else the AWS pricing provider wrapped by the static
+// fallback and Cost Explorer providers if they're enabled.
+func (a *App) newPricingProvider(ctx context.Context) (pricing.Provider, error) {
+	if a.opts.PricingProvider != nil {
+		return a.opts.PricingProvider, nil
+	}
+	cfg, err := a.loadAWSConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	awsProvider := pricing.NewAWSProvider(*cfg, a.awsProviderOptions()...)
+	awsProvider.MaxSpotPricePages = a.opts.MaxSpotPricePages
+	awsProvider.Filters = a.opts.PricingFilters
+	// sanity check
+	_, err = awsProvider.GetFargatePricing(ctx)
+	if err != nil && !a.opts.StaticFallback {
+		return nil, fmt.Errorf("could not load AWS pricing data: %w", err)
+	}
+	var pricingProvider pricing.Provider = awsProvider
+	if a.opts.StaticFallback {
+		pricingProvider = pricing.NewFallbackProvider(awsProvider, pricing.NewStaticProvider())
+	}
+	if a.opts.CostExplorer {
+		pricingProvider = pricing.NewCostExplorerProvider(*cfg, pricingProvider)
+	}
+	return pricingProvider, nil
+}
+
+// repositoryOptions returns the options to build the pricing repository with.
+func (a *App) repositoryOptions(ctx context.Context) ([]pricing.RepositoryOption, error) {
+	repositoryOpts := []pricing.RepositoryOption{
+		pricing.WithLicenseModels(a.opts.LicenseModels...),
+		pricing.WithFargateRegions(a.opts.FargateRegions...),
+		pricing.WithProviderRegion(a.region()),
+		pricing.WithSpotPriceTTL(a.opts.SpotPriceTTL),
+		pricing.WithSpotPriceSmoothing(a.opts.SpotPriceSmoothing),
+	}
+	if a.opts.PriceDrift {
+		cfg, err := a.loadAWSConfig(ctx)
+		if err != nil {
+			return nil, err
+		}
+		liveProvider := pricing.NewAWSProvider(*cfg, a.awsProviderOptions()...)
+		liveProvider.Filters = a.opts.PricingFilters
+		repositoryOpts = append(repositoryOpts, pricing.WithPriceDriftReference(liveProvider))
+	}
+	if a.opts.SpotPriceChangeWebhookURL != "" {
+		repositoryOpts = append(
+			repositoryOpts,
+			pricing.WithSpotPriceChangeWebhook(
+				pricing.NewSpotPriceChangeWebhook(a.opts.SpotPriceChangeWebhookURL, a.opts.SpotPriceChangeThreshold),
+			),
+		)
+	}
+	return repositoryOpts, nil
+}
+
+// updateInitialPricing performs the initial pricing update, in the background over the static snapshot if
+// SeedFromStatic is set, and starts the initial price drift update in the background.
+func (a *App) updateInitialPricing(ctx context.Context) error {
+	if a.opts.SeedFromStatic {
+		if err := a.pricingRepository.Seed(ctx, pricing.NewStaticProvider()); err != nil {
+			return err
+		}
+		a.initialUpdate.Add(1)
+		go func() {
+			defer a.initialUpdate.Done()
+			a.updateSeededPricing(ctx)
+		}()
+		return nil
+	}
+	log.Printf("updating pricing...")
+	err := a.pricingRepository.UpdatePricing(ctx)
+	if err != nil {
+		return fmt.Errorf("could not update pricing repository: %w", err)
+	}
+	if a.opts.PriceDrift {
+		// fetching the live prices takes as long as the update itself, so don't delay startup for the informational drift
+		a.initialUpdate.Add(1)
+		go func() {
+			defer a.initialUpdate.Done()
+			a.updatePriceDrift(ctx)
+		}()
+	}
+	return nil
+}
+
+// updateSeededPricing performs the initial pricing update over the pricing seeded from the static snapshot. On failure
+// the static pricing is kept until the next scheduled refresh.
+func (a *App) updateSeededPricing(ctx context.Context) {
+	log.Printf("updating pricing in the background, using static pricing until done...")
+	err := a.pricingRepository.UpdatePricing(ctx)
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		log.Printf("could not update pricing repository: %s", err)
+	}
+	a.updatePriceDrift(ctx)
+}
+
+// scopeToClusterZones restricts the spot pricing of the pricing provider to the zones of the nodes currently in the
+// cluster, if ScopeToClusterZones is set.
+func (a *App) scopeToClusterZones(ctx context.Context) error {
+	if a.zoneScopedProvider == nil {
+		return nil
+	}
+	zones, err := model.NodeZones(ctx, a.cs)
+	if err != nil {
+		return fmt.Errorf("discovering cluster zones: %w", err)
+	}
+	// without any zones there is nothing to scope to, so keep fetching the zones scoped to before
+	if len(zones) == 0 {
+		return nil
+	}
+	log.Printf("scoping spot pricing to the zones of the cluster: %s", strings.Join(zones, ", "))
+	a.zoneScopedProvider.ScopeToZones(zones)
+	return nil
+}
+
+// ValidateListenAddress returns an error if addr is not a valid host:port to listen on.
+func ValidateListenAddress(addr string) error {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid listen address %q: %w", addr, err)
+	}
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return fmt.Errorf("invalid listen address %q: port must be a number between 0 and 65535", addr)
+	}
+	return nil
+}
+
+// ValidateInstanceTypePatterns returns an error if any of the instance type allowlist or denylist patterns isn't a
+// valid path.Match pattern.
+func ValidateInstanceTypePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid instance type pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// ValidateSpotPriceSmoothing returns an error if alpha isn't a weight between 0 and 1 for the spot price moving
+// average.
+func ValidateSpotPriceSmoothing(alpha float64) error {
+	if !(alpha >= 0 && alpha <= 1) {
+		return fmt.Errorf("invalid spot price smoothing %v: must be between 0 and 1", alpha)
+	}
+	return nil
+}
+
+// updatePriceDrift updates the drift of the on-demand prices from live prices if enabled. Failures are only logged
+// since the drift is informational.
+func (a *App) updatePriceDrift(ctx context.Context) {
+	if !a.opts.PriceDrift {
+		return
+	}
+	err := a.pricingRepository.UpdatePriceDrift(ctx)
+	if err != nil {
+		log.Printf("could not update price drift: %s", err)
+	}
+}
+
+func (a *App) awsProviderOptions() []pricing.AWSProviderOption {
+	return []pricing.AWSProviderOption{
+		pricing.WithPricingEndpoint(a.opts.PricingEndpoint),
+		pricing.WithEC2Endpoint(a.opts.EC2Endpoint),
+		pricing.WithPricingRegionFallback(a.opts.PricingRegionFallback),
+	}
+}
+
+func (a *App) instrumentAWSConfig(cfg *aws.Config) {
+	if a.apiMetrics != nil {
+		a.apiMetrics.Instrument(cfg)
+	}
+}
+
+func (a *App) loadAWSConfig(ctx context.Context) (*aws.Config, error) {
+	if a.awsConfig != nil {
+		return a.awsConfig, nil
+	}
+	if a.opts.AWSConfig != nil {
+		cfg := a.opts.AWSConfig.Copy()
+		a.instrumentAWSConfig(&cfg)
+		a.awsConfig = &cfg
+		return a.awsConfig, nil
+	}
+	var awsConfigOpts []func(*config.LoadOptions) error
+	if a.opts.AWSRegion != "" {
+		awsConfigOpts = append(awsConfigOpts, config.WithRegion(a.opts.AWSRegion))
+	}
+	if a.opts.AWSMaxRetries > 0 {
+		awsConfigOpts = append(awsConfigOpts, config.WithRetryMaxAttempts(a.opts.AWSMaxRetries+1))
+	}
+	if a.opts.AWSHTTPProxy != "" {
+		proxyURL, err := url.Parse(a.opts.AWSHTTPProxy)
+		if err != nil {
+			return nil, fmt.Errorf("parsing aws http proxy: %w", err)
+		}
+		awsConfigOpts = append(awsConfigOpts, config.WithHTTPClient(pricing.NewProxyHTTPClient(proxyURL)))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, awsConfigOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("loading aws config: %w", err)
+	}
+	if cfg.Region == "" {
+		return nil, errors.New(
+			"no AWS region configured, set -region, the AWS_REGION environment variable or a region in the AWS profile",
+		)
+	}
+	// make sure credentials are cached and refreshed before they expire so that rotated IRSA or assumed role
+	// credentials are picked up by long-running exporters
+	if _, ok := cfg.Credentials.(*aws.CredentialsCache); !ok && cfg.Credentials != nil {
+		cfg.Credentials = aws.NewCredentialsCache(cfg.Credentials)
+	}
+	if a.opts.AssumeRoleARN != "" {
+		cfg = pricing.NewRoleCredentialsCache(sts.NewFromConfig(cfg)).Config(cfg, a.opts.AssumeRoleARN)
+	}
+	a.instrumentAWSConfig(&cfg)
+	a.awsConfig = &cfg
+	return a.awsConfig, nil
+}
+
+// PricingRepository returns the pricing repository backing the app.
+func (a *App) PricingRepository() *pricing.Repository {
+	return a.pricingRepository
+}
+
+// PriceNode writes the resolved price for the named node to w.
+func (a *App) PriceNode(ctx context.Context, w io.Writer, name string) error {
+	return PriceNode(ctx, w, a.cs, a.pricingRepository, name, model.WithNodeOptions(a.nodeOptions()...))
+}
+
+// nodeOptions returns the options nodes are created with.
+func (a *App) nodeOptions() []model.NodeOption {
+	var nodeOpts []model.NodeOption
+	if len(a.opts.CapacityTypeLabels) != 0 {
+		nodeOpts = append(nodeOpts, model.WithCapacityTypeLabels(a.opts.CapacityTypeLabels...))
+	}
+	if a.opts.DefaultCapacityType != model.NodeUnknownCapacityType {
+		nodeOpts = append(nodeOpts, model.WithDefaultCapacityType(a.opts.DefaultCapacityType))
+	}
+	if a.opts.HybridHourlyPrice != 0 {
+		nodeOpts = append(nodeOpts, model.WithHybridHourlyPrice(a.opts.HybridHourlyPrice))
+	}
+	return nodeOpts
+}
+
+// region returns the AWS region the exporter prices nodes in, or an empty string if it isn't known, e.g. with a custom
+// PricingProvider.
+func (a *App) region() string {
+	if a.awsConfig != nil {
+		return a.awsConfig.Region
+	}
+	return a.opts.AWSRegion
+}
+
+// register registers c with the Registerer and the exporter registry.
+func (a *App) register(c prometheus.Collector) error {
+	if err := a.opts.Registerer.Register(c); err != nil {
+		return err
+	}
+	return a.exporterMetrics.Register(c)
+}
+
+// Handler registers the collector and returns the HTTP handler serving metrics and admin endpoints.
+func (a *App) Handler(ctx context.Context) (http.Handler, error) {
+	if err := a.registerCollectors(ctx); err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	var metricsHandler http.Handler = promhttp.HandlerFor(a.opts.Gatherer, promhttp.HandlerOpts{
+		EnableOpenMetrics: true,
+		// promhttp only compresses at the default level, so other levels are compressed by gzipHandler instead
+		DisableCompression: a.opts.MetricsCompression != MetricsCompressionDefault,
+	})
+	if a.opts.MetricsCompression > 0 {
+		metricsHandler = gzipHandler(metricsHandler, a.opts.MetricsCompression)
+	}
+	mux.Handle("/metrics", promhttp.InstrumentMetricHandler(a.opts.Registerer, metricsHandler))
+	mux.HandleFunc("/admin/pricing/update", a.handleUpdatePricing)
+	mux.HandleFunc("/admin/pricing/update/", a.handleUpdatePricingType)
+	mux.HandleFunc("/admin/pricing/compare", a.handleComparePricing)
+	mux.HandleFunc("/admin/pricing/diff", a.handlePricingDiff)
+	mux.HandleFunc("/admin/pricing/lookup-batch", a.handleLookupBatch)
+	return mux, nil
+}
+
+// collectorOptions returns the options to build the collector with.
+func (a *App) collectorOptions(ctx context.Context) ([]collector.Option, error) {
+	collectorOpts := []collector.Option{
+		collector.WithMaxSeries(a.opts.MaxSeries),
+		collector.WithMaxInstanceTypeSeries(a.opts.MaxInstanceTypeSeries),
+		collector.WithExcludeCordoned(a.opts.ExcludeCordoned),
+		collector.WithUnitSuffixes(a.opts.UnitSuffixes),
+		collector.WithPriceCurrency(pricing.RegionCurrency(a.region())),
+		collector.WithMonthlyPrices(a.opts.MonthlyPrices),
+		collector.WithSmoothedSpotPrices(a.opts.SpotPriceSmoothing > 0),
+		collector.WithFargateAccumulatedCost(a.opts.FargateAccumulatedCost),
+		collector.WithSpotPriceHistogram(a.opts.SpotPriceHistogram),
+		collector.WithMetricOverrides(a.opts.MetricOverrides),
+		collector.WithNodeGracePeriod(a.opts.NodeGracePeriod),
+		collector.WithPodBindingStrategy(a.opts.PodBindingStrategy),
+		collector.WithWorkloadLabels(a.opts.WorkloadLabels),
+		collector.WithExcludeNamespaces(a.opts.ExcludeNamespaces),
+		collector.WithCapacityTypeLabels(a.opts.CapacityTypeLabels),
+		collector.WithDefaultCapacityType(a.opts.DefaultCapacityType),
+		collector.WithHybridHourlyPrice(a.opts.HybridHourlyPrice),
+		collector.WithDebugInstanceTypes(a.opts.DebugInstanceTypes),
+		collector.WithPricePrecision(a.opts.PricePrecision),
+		collector.WithExcludeFargateInstanceTypes(a.opts.ExcludeFargateTypes),
+		collector.WithInstanceTypeAllowlist(a.opts.InstanceTypeAllowlist),
+		collector.WithInstanceTypeDenylist(a.opts.InstanceTypeDenylist),
+	}
+	if a.opts.SystemOverhead != "" {
+		collectorOpts = append(collectorOpts, collector.WithSystemOverhead(a.opts.SystemOverhead))
+	}
+	if a.opts.AttributionBasis != "" {
+		collectorOpts = append(collectorOpts, collector.WithAttributionBasis(a.opts.AttributionBasis))
+	}
+	if a.opts.CollectorWorkers > 0 {
+		collectorOpts = append(collectorOpts, collector.WithWorkers(a.opts.CollectorWorkers))
+	}
+	if a.opts.DescribeInstances {
+		cfg, err := a.loadAWSConfig(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ec2Client := pricing.NewEC2Client(*cfg, a.awsProviderOptions()...)
+		collectorOpts = append(collectorOpts, collector.WithInstanceLookup(pricing.NewInstanceLookup(ec2Client)))
+	}
+	return collectorOpts, nil
+}
+
+// registerCollectors builds the collector and registers it along with the nodeclaim collector and AWS API metrics,
+// leaving out the metrics which are disabled.
+func (a *App) registerCollectors(ctx context.Context) error {
+	collectorOpts, err := a.collectorOptions(ctx)
+	if err != nil {
+		return err
+	}
+	a.exporterMetrics = prometheus.NewRegistry()
+	c := collector.NewCollector(ctx, a.cs, a.pricingRepository, collectorOpts...)
+	a.collector = c
+	var metrics prometheus.Collector = c
+	if a.opts.DisableNodeMetrics {
+		metrics = c.PricingMetrics()
+	} else if a.opts.DisablePricingMetrics {
+		metrics = c.NodeMetrics()
+	}
+	if err := a.register(metrics); err != nil {
+		return fmt.Errorf("registering collector: %w", err)
+	}
+	if a.opts.PendingNodeClaims && !a.opts.DisableNodeMetrics {
+		if err := a.registerNodeClaimCollector(c); err != nil {
+			return err
+		}
+	}
+	if !a.opts.DisablePricingMetrics {
+		if err := a.register(a.apiMetrics); err != nil {
+			return fmt.Errorf("registering aws api metrics: %w", err)
+		}
+	}
+	return nil
+}
+
+// registerNodeClaimCollector registers the collector of pending Karpenter NodeClaims, named like the metrics of c.
+func (a *App) registerNodeClaimCollector(c *collector.Collector) error {
+	client := a.opts.DynamicClient
+	if client == nil {
+		restConfig, err := ctrl.GetConfig()
+		if err != nil {
+			return fmt.Errorf("loading kubernetes config: %w", err)
+		}
+		client, err = dynamic.NewForConfig(restConfig)
+		if err != nil {
+			return fmt.Errorf("creating kubernetes dynamic client: %w", err)
+		}
+	}
+	err := a.register(c.NewNodeClaimCollector(client))
+	if err != nil {
+		return fmt.Errorf("registering nodeclaim collector: %w", err)
+	}
+	return nil
+}
+
+// handleUpdatePricing updates all pricing.
+func (a *App) handleUpdatePricing(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, "Only POST method is allowed on this endpoint.")
+		return
+	}
+	log.Println("updating pricing via /admin/pricing/update")
+	err := a.pricingRepository.UpdatePricing(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "error updating pricing: %s", err)
+		return
+	}
+	fmt.Fprintln(w, "success")
+}
+
+// handleUpdatePricingType updates the pricing of the type at the end of the path.
+func (a *App) handleUpdatePricingType(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, "Only POST method is allowed on this endpoint.")
+		return
+	}
+	pricingType := strings.TrimPrefix(r.URL.Path, "/admin/pricing/update/")
+	update, ok := map[string]func(context.Context) error{
+		"ondemand": a.pricingRepository.UpdateOnDemandPricing,
+		"spot":     a.pricingRepository.UpdateSpotPricing,
+		"fargate":  a.pricingRepository.UpdateFargatePricing,
+	}[pricingType]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, "unknown pricing type %q, must be one of ondemand, spot, or fargate\n", pricingType)
+		return
+	}
+	log.Printf("updating %s pricing via %s", pricingType, r.URL.Path)
+	err := update(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "error updating %s pricing: %s", pricingType, err)
+		return
+	}
+	fmt.Fprintln(w, "success")
+}
+
+// handleComparePricing writes the comparison of on-demand and spot pricing of every instance type as JSON.
+func (a *App) handleComparePricing(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, "Only GET method is allowed on this endpoint.")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(a.pricingRepository.Compare())
+	if err != nil {
+		log.Printf("error writing pricing comparison: %s", err)
+	}
+}
+
+// handlePricingDiff writes the prices which changed in the last refresh as JSON.
+func (a *App) handlePricingDiff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, "Only GET method is allowed on this endpoint.")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(a.pricingRepository.PriceDiff())
+	if err != nil {
+		log.Printf("error writing pricing diff: %s", err)
+	}
+}
+
+// handleLookupBatch writes the prices of a JSON array of lookup requests as JSON.
+func (a *App) handleLookupBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, "Only POST method is allowed on this endpoint.")
+		return
+	}
+	var requests []pricing.LookupRequest
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxLookupBatchBytes)).Decode(&requests)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, "error decoding lookup requests: %s", err)
+		return
+	}
+	results := make([]pricing.LookupResult, 0, len(requests))
+	for _, req := range requests {
+		results = append(results, a.pricingRepository.Lookup(req))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(results)
+	if err != nil {
+		log.Printf("error writing lookup results: %s", err)
+	}
+}
+
+// refreshSchedule is a set of pricing types refreshed together every interval, starting offset after the first
+// interval.
+type refreshSchedule struct {
+	pricingTypes []pricing.PricingType
+	interval     time.Duration
+	offset       time.Duration
+}
+
+// refreshSchedules returns the schedules pricing is refreshed on. All pricing is refreshed at once every
+// RefreshInterval, unless spot and Fargate pricing are refreshed separately.
+func (a *App) refreshSchedules() []refreshSchedule {
+	if !a.opts.StaggerRefresh && a.opts.SpotRefreshInterval == 0 && a.opts.FargateRefreshInterval == 0 {
+		return []refreshSchedule{{pricingTypes: pricing.PricingTypes, interval: a.opts.RefreshInterval}}
+	}
+	schedules := []refreshSchedule{
+		{
+			pricingTypes: []pricing.PricingType{
+				pricing.PricingTypeOnDemand,
+				pricing.PricingTypeCapacityBlock,
+				pricing.PricingTypeInstanceSpecs,
+				pricing.PricingTypeControlPlane,
+			},
+			interval: a.opts.RefreshInterval,
+		},
+		{pricingTypes: []pricing.PricingType{pricing.PricingTypeSpot}, interval: a.opts.SpotRefreshInterval},
+		{pricingTypes: []pricing.PricingType{pricing.PricingTypeFargate}, interval: a.opts.FargateRefreshInterval},
+	}
+	for i := range schedules {
+		if schedules[i].interval == 0 {
+			schedules[i].interval = a.opts.RefreshInterval
+		}
+		if a.opts.StaggerRefresh {
+			schedules[i].offset = schedules[i].interval * time.Duration(i) / time.Duration(len(schedules))
+		}
+	}
+	return schedules
+}
+
+// refreshPricing updates the pricing types of schedule on schedule until ctx is done.
+func (a *App) refreshPricing(ctx context.Context, schedule refreshSchedule) {
+	if schedule.offset != 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(schedule.offset):
+		}
+	}
+	ticker := time.NewTicker(schedule.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if lo.Contains(schedule.pricingTypes, pricing.PricingTypeSpot) {
+				// nodes may have been launched in new zones since the last refresh
+				if err := a.scopeToClusterZones(ctx); err != nil {
+					log.Printf("could not rescope spot pricing, keeping the previous zones: %s", err)
+				}
+			}
+			log.Printf("updating %v pricing on schedule", schedule.pricingTypes)
+			err := a.pricingRepository.UpdatePricingTypes(ctx, schedule.pricingTypes...)
+			if ctx.Err() != nil {
+				log.Printf("stopped updating pricing for shutdown: %s", err)
+				return
+			}
+			if err != nil {
+				// keep serving the last known pricing and try again on the next refresh
+				log.Printf("could not update pricing repository: %s", err)
+			}
+			if lo.Contains(schedule.pricingTypes, pricing.PricingTypeOnDemand) {
+				a.updatePriceDrift(ctx)
+			}
+		}
+	}
+}
+
+// Serve serves the exporter and refreshes pricing on schedule until ctx is cancelled.
+func (a *App) Serve(ctx context.Context) error {
+	handler, err := a.Handler(ctx)
+	if err != nil {
+		return err
+	}
+
+	if a.opts.OTLPEndpoint != "" {
+		provider, err := newOTLPMeterProvider(ctx, a.opts.OTLPEndpoint)
+		if err != nil {
+			return err
+		}
+		// flush the last metrics once the server has stopped
+		defer func() {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := provider.Shutdown(shutdownCtx); err != nil {
+				log.Printf("shutting down otlp exporter failed: %s", err)
+			}
+		}()
+		if err := a.registerOTelCollector(provider); err != nil {
+			return err
+		}
+	}
+
+	// listen before starting the refresh loops, which only stop once ctx is done, so failing to listen returns
+	listener := a.opts.Listener
+	if listener == nil {
+		listener, err = net.Listen("tcp", a.opts.ListenAddress)
+		if err != nil {
+			return fmt.Errorf("listening on %s: %w", a.opts.ListenAddress, err)
+		}
+	}
+
+	// the refresh loops are waited for before returning so an in-flight update is never cut off mid-way, and are
+	// stopped by cancelling ctx when the server stops for any reason
+	ctx, cancel := context.WithCancel(ctx)
+	var refresh sync.WaitGroup
+	defer a.initialUpdate.Wait()
+	defer refresh.Wait()
+	defer cancel()
+
+	server := &http.Server{
+		Addr:         a.opts.ListenAddress,
+		Handler:      handler,
+		BaseContext:  func(_ net.Listener) context.Context { return ctx },
+		ReadTimeout:  a.opts.ReadTimeout,
+		WriteTimeout: a.opts.WriteTimeout,
+		IdleTimeout:  a.opts.IdleTimeout,
+	}
+
+	a.startRefreshLoops(ctx, &refresh)
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	err = server.Serve(listener)
+	if !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("error running server: %w", err)
+	}
+	return nil
+}
+
+// startRefreshLoops starts refreshing pricing on schedule and writing the textfile, if enabled, until ctx is done,
+// adding them to refresh.
+func (a *App) startRefreshLoops(ctx context.Context, refresh *sync.WaitGroup) {
+	for _, schedule := range a.refreshSchedules() {
+		schedule := schedule
+		refresh.Add(1)
+		go func() {
+			defer refresh.Done()
+			a.refreshPricing(ctx, schedule)
+		}()
+	}
+	if a.opts.TextfileOutput != "" {
+		refresh.Add(1)
+		go func() {
+			defer refresh.Done()
+			a.writeTextfiles(ctx)
+		}()
+	}
+}
+
+// Run builds the exporter from opts and serves it until ctx is cancelled.
+func Run(ctx context.Context, opts Options) error {
+	a, err := New(ctx, opts)
+	if err != nil {
+		return err
+	}
+	return a.Serve(ctx)
+}
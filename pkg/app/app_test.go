@@ -0,0 +1,1172 @@
+package app_test
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/sapslaj/eks-pricing-exporter/pkg/app"
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing"
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing/pricingtest"
+)
+
+func TestRunServesMetrics(t *testing.T) {
+	cs := fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mynode",
+			Labels: map[string]string{
+				"karpenter.sh/capacity-type": "on-demand",
+				v1.LabelInstanceTypeStable:   "m5.large",
+				v1.LabelTopologyZone:         "us-east-1a",
+				v1.LabelTopologyRegion:       "us-east-1",
+			},
+		},
+	})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error listening: %s", err)
+	}
+	registry := prometheus.NewRegistry()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Run(ctx, app.Options{
+			Listener:         listener,
+			KubernetesClient: cs,
+			PricingProvider:  pricing.NewStaticProvider(),
+			Registerer:       registry,
+			Gatherer:         registry,
+		})
+	}()
+
+	var body string
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		resp, err := http.Get("http://" + listener.Addr().String() + "/metrics")
+		if err == nil {
+			b, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			body = string(b)
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server did not start: %s", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if !strings.Contains(body, `eks_node_hourly_price{`) || !strings.Contains(body, `node="mynode"`) {
+		t.Errorf("expected node price metric in response, got:\n%s", body)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("unexpected error from Run: %s", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Run did not return after the context was cancelled")
+	}
+}
+
+func TestServeTextfileOutput(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error listening: %s", err)
+	}
+	path := filepath.Join(t.TempDir(), "eks_pricing.prom")
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Run(ctx, app.Options{
+			Listener:         listener,
+			KubernetesClient: fake.NewSimpleClientset(),
+			PricingProvider:  pricing.NewStaticProvider(),
+			Registerer:       registry,
+			Gatherer:         registry,
+			TextfileOutput:   path,
+		})
+	}()
+
+	var f *os.File
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if f, err = os.Open(path); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatalf("expected the metrics to be written to %s: %s", path, err)
+	}
+	defer f.Close()
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(f)
+	if err != nil {
+		t.Fatalf("expected valid metrics in the textfile, got %s", err)
+	}
+	if _, ok := families["eks_cluster_control_plane_hourly_price"]; !ok {
+		t.Errorf("expected eks_cluster_control_plane_hourly_price in the textfile, got %v", lo.Keys(families))
+	}
+	for name := range families {
+		if strings.HasPrefix(name, "go_") {
+			t.Errorf("expected no Go runtime metrics in the textfile, got %s", name)
+		}
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error from Run: %s", err)
+	}
+}
+
+func TestPush(t *testing.T) {
+	var method, path string
+	families := map[string]*dto.MetricFamily{}
+	pushgateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		decoder := expfmt.NewDecoder(r.Body, expfmt.ResponseFormat(r.Header))
+		for {
+			family := &dto.MetricFamily{}
+			if err := decoder.Decode(family); err != nil {
+				break
+			}
+			families[family.GetName()] = family
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer pushgateway.Close()
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector())
+	a, err := app.New(context.Background(), app.Options{
+		KubernetesClient: fake.NewSimpleClientset(&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "mynode",
+				Labels: map[string]string{
+					"karpenter.sh/capacity-type": "on-demand",
+					v1.LabelInstanceTypeStable:   "m5.large",
+				},
+			},
+		}),
+		PricingProvider: pricing.NewStaticProvider(),
+		Registerer:      registry,
+		Gatherer:        registry,
+		PushgatewayURL:  pushgateway.URL,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error creating app: %s", err)
+	}
+	if err := a.Push(context.Background()); err != nil {
+		t.Fatalf("unexpected error pushing metrics: %s", err)
+	}
+
+	if method != http.MethodPut || path != "/metrics/job/"+app.DefaultPushgatewayJob {
+		t.Errorf("expected the metrics to replace the default job, got %s %s", method, path)
+	}
+	family, ok := families["eks_node_hourly_price"]
+	if !ok || len(family.GetMetric()) != 1 {
+		t.Fatalf("expected the push to contain eks_node_hourly_price of mynode, got %v", family)
+	}
+	if exp, got := 0.096, family.GetMetric()[0].GetGauge().GetValue(); exp != got {
+		t.Errorf("expected eks_node_hourly_price == %v, got %v", exp, got)
+	}
+	for name := range families {
+		if strings.HasPrefix(name, "go_") {
+			t.Errorf("expected no Go runtime metrics in the push, got %s", name)
+		}
+	}
+}
+
+func TestServeTimeouts(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error listening: %s", err)
+	}
+	registry := prometheus.NewRegistry()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Run(ctx, app.Options{
+			Listener:         listener,
+			KubernetesClient: fake.NewSimpleClientset(),
+			PricingProvider:  pricing.NewStaticProvider(),
+			Registerer:       registry,
+			Gatherer:         registry,
+			ReadTimeout:      200 * time.Millisecond,
+			WriteTimeout:     10 * time.Second,
+			IdleTimeout:      200 * time.Millisecond,
+		})
+	}()
+
+	// closed returns how long it took the server to close conn after it was last written to
+	closed := func(conn net.Conn) time.Duration {
+		t.Helper()
+		start := time.Now()
+		if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+			t.Fatalf("unexpected error setting deadline: %s", err)
+		}
+		if _, err := io.Copy(io.Discard, conn); err != nil {
+			t.Fatalf("expected the server to close the connection, got %s", err)
+		}
+		return time.Since(start)
+	}
+	dial := func() net.Conn {
+		t.Helper()
+		deadline := time.Now().Add(10 * time.Second)
+		for {
+			resp, err := http.Get("http://" + listener.Addr().String() + "/metrics")
+			if err == nil {
+				resp.Body.Close()
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("server did not start: %s", err)
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		conn, err := net.Dial("tcp", listener.Addr().String())
+		if err != nil {
+			t.Fatalf("unexpected error dialing: %s", err)
+		}
+		return conn
+	}
+
+	t.Run("read timeout", func(t *testing.T) {
+		conn := dial()
+		defer conn.Close()
+		// a request which never finishes sending its headers
+		if _, err := io.WriteString(conn, "GET /metrics HTTP/1.1\r\nHost: exporter\r\n"); err != nil {
+			t.Fatalf("unexpected error writing request: %s", err)
+		}
+		if took := closed(conn); took > 3*time.Second {
+			t.Errorf("expected the read timeout to close the connection, took %s", took)
+		}
+	})
+	t.Run("idle timeout", func(t *testing.T) {
+		conn := dial()
+		defer conn.Close()
+		// a complete keep-alive request, after which the connection is idle
+		if _, err := io.WriteString(conn, "GET /metrics HTTP/1.1\r\nHost: exporter\r\n\r\n"); err != nil {
+			t.Fatalf("unexpected error writing request: %s", err)
+		}
+		if took := closed(conn); took > 3*time.Second {
+			t.Errorf("expected the idle timeout to close the connection, took %s", took)
+		}
+	})
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("unexpected error from Run: %s", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Run did not return after the context was cancelled")
+	}
+}
+
+func TestRunListenAddress(t *testing.T) {
+	for _, network := range []struct{ name, host string }{
+		{"ipv4", "127.0.0.1"},
+		{"ipv6", "[::1]"},
+	} {
+		t.Run(network.name, func(t *testing.T) {
+			// find a free port to bind to
+			l, err := net.Listen("tcp", network.host+":0")
+			if err != nil {
+				t.Skipf("%s is not available: %s", network.name, err)
+			}
+			addr := l.Addr().String()
+			l.Close()
+
+			registry := prometheus.NewRegistry()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			done := make(chan error, 1)
+			go func() {
+				done <- app.Run(ctx, app.Options{
+					ListenAddress:    addr,
+					KubernetesClient: fake.NewSimpleClientset(),
+					PricingProvider:  pricing.NewStaticProvider(),
+					Registerer:       registry,
+					Gatherer:         registry,
+				})
+			}()
+
+			deadline := time.Now().Add(10 * time.Second)
+			for {
+				resp, err := http.Get("http://" + addr + "/metrics")
+				if err == nil {
+					resp.Body.Close()
+					if resp.StatusCode != http.StatusOK {
+						t.Errorf("expected status 200, got %d", resp.StatusCode)
+					}
+					break
+				}
+				select {
+				case err := <-done:
+					t.Fatalf("Run returned early: %v", err)
+				default:
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("server did not start on %s: %s", addr, err)
+				}
+				time.Sleep(50 * time.Millisecond)
+			}
+
+			cancel()
+			if err := <-done; err != nil {
+				t.Errorf("unexpected error from Run: %s", err)
+			}
+		})
+	}
+}
+
+func TestRunListenAddressInUse(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error listening: %s", err)
+	}
+	defer l.Close()
+
+	registry := prometheus.NewRegistry()
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Run(context.Background(), app.Options{
+			ListenAddress:    l.Addr().String(),
+			KubernetesClient: fake.NewSimpleClientset(),
+			PricingProvider:  pricing.NewStaticProvider(),
+			Registerer:       registry,
+			Gatherer:         registry,
+			RefreshInterval:  time.Hour,
+		})
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Errorf("expected an error listening on an address in use")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Run did not return after failing to listen")
+	}
+}
+
+func TestValidateListenAddress(t *testing.T) {
+	for addr, valid := range map[string]bool{
+		":9523":            true,
+		"0.0.0.0:9523":     true,
+		"[::]:9523":        true,
+		"[2001:db8::1]:80": true,
+		"localhost:9523":   true,
+		"9523":             false,
+		"::1:9523":         false,
+		":http":            false,
+		":70000":           false,
+	} {
+		err := app.ValidateListenAddress(addr)
+		if valid && err != nil {
+			t.Errorf("expected %q to be valid, got %s", addr, err)
+		}
+		if !valid && err == nil {
+			t.Errorf("expected %q to be invalid", addr)
+		}
+	}
+}
+
+func TestMetricsCompression(t *testing.T) {
+	for _, level := range []int{app.MetricsCompressionDefault, gzip.BestSpeed, gzip.BestCompression} {
+		registry := prometheus.NewRegistry()
+		a, err := app.New(context.Background(), app.Options{
+			KubernetesClient:   fake.NewSimpleClientset(),
+			PricingProvider:    pricing.NewStaticProvider(),
+			Registerer:         registry,
+			Gatherer:           registry,
+			MetricsCompression: level,
+		})
+		if err != nil {
+			t.Fatalf("level %d: unexpected error creating app: %s", level, err)
+		}
+		handler, err := a.Handler(context.Background())
+		if err != nil {
+			t.Fatalf("level %d: unexpected error creating handler: %s", level, err)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if exp, got := "gzip", rec.Header().Get("Content-Encoding"); exp != got {
+			t.Fatalf("level %d: expected Content-Encoding == %s, got %q", level, exp, got)
+		}
+		reader, err := gzip.NewReader(rec.Body)
+		if err != nil {
+			t.Fatalf("level %d: unexpected error reading gzip response: %s", level, err)
+		}
+		body, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("level %d: unexpected error decompressing response: %s", level, err)
+		}
+		if !strings.Contains(string(body), "eks_cluster_control_plane_hourly_price") {
+			t.Errorf("level %d: expected metrics in the decompressed response, got %s", level, body)
+		}
+
+		// scrapers which don't accept gzip get uncompressed metrics
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		if got := rec.Header().Get("Content-Encoding"); got != "" {
+			t.Errorf("level %d: expected no Content-Encoding without Accept-Encoding, got %s", level, got)
+		}
+	}
+
+	_, err := app.New(context.Background(), app.Options{
+		KubernetesClient:   fake.NewSimpleClientset(),
+		PricingProvider:    pricing.NewStaticProvider(),
+		MetricsCompression: 10,
+	})
+	if err == nil {
+		t.Errorf("expected an error for an invalid compression level")
+	}
+}
+
+func testHandler(t *testing.T, provider pricing.Provider) (*app.App, http.Handler) {
+	t.Helper()
+	ctx := context.Background()
+	registry := prometheus.NewRegistry()
+	a, err := app.New(ctx, app.Options{
+		KubernetesClient: fake.NewSimpleClientset(),
+		PricingProvider:  provider,
+		Registerer:       registry,
+		Gatherer:         registry,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error creating app: %s", err)
+	}
+	handler, err := a.Handler(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error creating handler: %s", err)
+	}
+	return a, handler
+}
+
+func TestDisableMetrics(t *testing.T) {
+	for name, tc := range map[string]struct {
+		opts       app.Options
+		present    []string
+		suppressed []string
+	}{
+		"node metrics": {
+			opts:       app.Options{DisableNodeMetrics: true},
+			present:    []string{"eks_cluster_control_plane_hourly_price", "eks_pricing_update_errors_total"},
+			suppressed: []string{"eks_node_info", "eks_cluster_hourly_price", "eks_node_count"},
+		},
+		"pricing metrics": {
+			opts:       app.Options{DisablePricingMetrics: true},
+			present:    []string{"eks_node_info", "eks_cluster_hourly_price", "eks_node_count"},
+			suppressed: []string{"eks_cluster_control_plane_hourly_price", "eks_pricing_update_errors_total"},
+		},
+	} {
+		ctx := context.Background()
+		registry := prometheus.NewRegistry()
+		opts := tc.opts
+		opts.KubernetesClient = fake.NewSimpleClientset(&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "mynode",
+				Labels: map[string]string{
+					"karpenter.sh/capacity-type": "on-demand",
+					v1.LabelInstanceTypeStable:   "m5.large",
+				},
+			},
+		})
+		opts.PricingProvider = pricing.NewStaticProvider()
+		opts.Registerer = registry
+		opts.Gatherer = registry
+		a, err := app.New(ctx, opts)
+		if err != nil {
+			t.Fatalf("%s: unexpected error creating app: %s", name, err)
+		}
+		if _, err := a.Handler(ctx); err != nil {
+			t.Fatalf("%s: unexpected error creating handler: %s", name, err)
+		}
+		families, err := registry.Gather()
+		if err != nil {
+			t.Fatalf("%s: unexpected error gathering metrics: %s", name, err)
+		}
+		gathered := map[string]bool{}
+		for _, family := range families {
+			gathered[family.GetName()] = true
+		}
+		for _, metric := range tc.present {
+			if !gathered[metric] {
+				t.Errorf("%s: expected %s to be gathered", name, metric)
+			}
+		}
+		for _, metric := range tc.suppressed {
+			if gathered[metric] {
+				t.Errorf("%s: expected %s to be suppressed", name, metric)
+			}
+		}
+	}
+
+	_, err := app.New(context.Background(), app.Options{DisableNodeMetrics: true, DisablePricingMetrics: true})
+	if err == nil {
+		t.Errorf("expected an error disabling both node and pricing metrics")
+	}
+}
+
+func TestPricingCompare(t *testing.T) {
+	_, handler := testHandler(t, &pricingtest.Provider{
+		OnDemand: pricing.OnDemandPriceList{
+			"m5.large": 0.1,
+			"c5.large": 0.085,
+		},
+		Spot: pricing.SpotPriceList{
+			"m5.large": {
+				"us-east-1a": 0.04,
+				"us-east-1b": 0.03,
+			},
+		},
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/pricing/compare", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var rows []pricing.PriceComparison
+	if err := json.NewDecoder(rec.Body).Decode(&rows); err != nil {
+		t.Fatalf("unexpected error decoding response: %s", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d: %+v", len(rows), rows)
+	}
+
+	c5 := rows[0]
+	if c5.InstanceType != "c5.large" || c5.OnDemand == nil || *c5.OnDemand != 0.085 {
+		t.Errorf("unexpected c5.large row: %+v", c5)
+	}
+	if c5.SpotMin != nil || c5.SpotMinZone != "" || c5.SavingsPct != nil {
+		t.Errorf("expected no spot pricing for c5.large, got %+v", c5)
+	}
+
+	m5 := rows[1]
+	if m5.InstanceType != "m5.large" || m5.OnDemand == nil || *m5.OnDemand != 0.1 {
+		t.Errorf("unexpected m5.large row: %+v", m5)
+	}
+	if m5.SpotMin == nil || *m5.SpotMin != 0.03 || m5.SpotMinZone != "us-east-1b" {
+		t.Errorf("expected cheapest spot price 0.03 in us-east-1b, got %+v", m5)
+	}
+	if m5.SavingsPct == nil || *m5.SavingsPct < 69.99 || *m5.SavingsPct > 70.01 {
+		t.Errorf("expected ~70%% savings, got %+v", m5.SavingsPct)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/pricing/compare", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for POST, got %d", rec.Code)
+	}
+}
+
+func TestPricingDiff(t *testing.T) {
+	provider := &pricingtest.Provider{
+		OnDemand: pricing.OnDemandPriceList{"m5.large": 0.1, "c5.large": 0.085},
+		Spot:     pricing.SpotPriceList{"m5.large": {"us-east-1a": 0.04}},
+	}
+	a, handler := testHandler(t, provider)
+	diff := func() []pricing.PriceChange {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/pricing/diff", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var changes []pricing.PriceChange
+		if err := json.NewDecoder(rec.Body).Decode(&changes); err != nil {
+			t.Fatalf("unexpected error decoding response: %s", err)
+		}
+		return changes
+	}
+
+	if changes := diff(); len(changes) != 0 {
+		t.Errorf("expected no changes before a refresh, got %+v", changes)
+	}
+
+	provider.OnDemand = pricing.OnDemandPriceList{"m5.large": 0.125, "c5.large": 0.085}
+	provider.Spot = pricing.SpotPriceList{"m5.large": {"us-east-1a": 0.05}}
+	if err := a.PricingRepository().UpdatePricing(context.Background()); err != nil {
+		t.Fatalf("unexpected error updating pricing: %s", err)
+	}
+	changes := diff()
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %+v", changes)
+	}
+	onDemand := changes[0]
+	if onDemand.PricingType != pricing.PricingTypeOnDemand || onDemand.InstanceType != "m5.large" ||
+		onDemand.OldPrice == nil || *onDemand.OldPrice != 0.1 || onDemand.NewPrice == nil || *onDemand.NewPrice != 0.125 {
+		t.Errorf("expected m5.large on-demand to change from 0.1 to 0.125, got %+v", onDemand)
+	}
+	spot := changes[1]
+	if spot.PricingType != pricing.PricingTypeSpot || spot.InstanceType != "m5.large" || spot.Zone != "us-east-1a" ||
+		spot.OldPrice == nil || *spot.OldPrice != 0.04 || spot.NewPrice == nil || *spot.NewPrice != 0.05 {
+		t.Errorf("expected m5.large spot in us-east-1a to change from 0.04 to 0.05, got %+v", spot)
+	}
+
+	// only the snapshot before the last refresh is kept
+	if err := a.PricingRepository().UpdatePricing(context.Background()); err != nil {
+		t.Fatalf("unexpected error updating pricing: %s", err)
+	}
+	if changes := diff(); len(changes) != 0 {
+		t.Errorf("expected no changes after an unchanged refresh, got %+v", changes)
+	}
+}
+
+func TestPricingLookupBatch(t *testing.T) {
+	_, handler := testHandler(t, &pricingtest.Provider{
+		OnDemand: pricing.OnDemandPriceList{
+			"m5.large": 0.096,
+		},
+		Spot: pricing.SpotPriceList{
+			"m5.large": {"us-east-1a": 0.035},
+		},
+	})
+
+	body := `[
+		{"type": "m5.large", "capacityType": "on-demand"},
+		{"type": "m5.large", "capacityType": "spot", "zone": "us-east-1a"},
+		{"type": "m5.large", "capacityType": "spot", "zone": "us-east-1b"},
+		{"type": "c5.large", "capacityType": "on-demand"},
+		{"type": "m5.large", "capacityType": "dedicated"}
+	]`
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/pricing/lookup-batch", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var results []pricing.LookupResult
+	if err := json.NewDecoder(rec.Body).Decode(&results); err != nil {
+		t.Fatalf("unexpected error decoding response: %s", err)
+	}
+	if exp, got := 5, len(results); exp != got {
+		t.Fatalf("expected %d results, got %d: %+v", exp, got, results)
+	}
+	for i, exp := range []*float64{aws.Float64(0.096), aws.Float64(0.035), nil, nil, nil} {
+		got := results[i].Price
+		if (exp == nil) != (got == nil) || (exp != nil && *exp != *got) {
+			t.Errorf("result %d (%+v): expected price %v, got %v", i, results[i].LookupRequest, exp, got)
+		}
+	}
+	if exp, got := "no on-demand price for c5.large", results[3].Reason; exp != got {
+		t.Errorf("expected reason %q, got %q", exp, got)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/pricing/lookup-batch", strings.NewReader("{")))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for invalid JSON, got %d", rec.Code)
+	}
+}
+
+func TestPricingUpdateType(t *testing.T) {
+	provider := &pricingtest.Provider{
+		OnDemand: pricing.OnDemandPriceList{"m5.large": 0.096},
+		Spot:     pricing.SpotPriceList{"m5.large": {"us-east-1a": 0.035}},
+		Fargate:  pricing.FargatePrice{VCPUPerHour: 0.04048, GBPerHour: 0.004445},
+	}
+	a, handler := testHandler(t, provider)
+	pr := a.PricingRepository()
+
+	provider.OnDemand = pricing.OnDemandPriceList{"m5.large": 0.1}
+	provider.Spot = pricing.SpotPriceList{"m5.large": {"us-east-1a": 0.04}}
+	provider.Fargate = pricing.FargatePrice{VCPUPerHour: 0.05, GBPerHour: 0.005}
+
+	for _, tc := range []struct {
+		pricingType string
+		onDemand    float64
+		spot        float64
+		fargate     float64
+	}{
+		// each update only picks up the new price for its own type
+		{"ondemand", 0.1, 0.035, 0.04048 + 0.004445},
+		{"spot", 0.1, 0.04, 0.04048 + 0.004445},
+		{"fargate", 0.1, 0.04, 0.05 + 0.005},
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/pricing/update/"+tc.pricingType, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d: %s", tc.pricingType, rec.Code, rec.Body.String())
+		}
+		if got, _ := pr.OnDemandPrice("m5.large"); tc.onDemand != got {
+			t.Errorf("%s: expected on-demand price == %f, got %f", tc.pricingType, tc.onDemand, got)
+		}
+		if got, _ := pr.SpotPrice("m5.large", "us-east-1a"); tc.spot != got {
+			t.Errorf("%s: expected spot price == %f, got %f", tc.pricingType, tc.spot, got)
+		}
+		if got, _ := pr.FargatePrice("", 1, 1); tc.fargate != got {
+			t.Errorf("%s: expected fargate price == %f, got %f", tc.pricingType, tc.fargate, got)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/pricing/update/reserved", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an unknown type, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/pricing/update/spot", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for GET, got %d", rec.Code)
+	}
+}
+
+// blockingPricingProvider returns spot pricing once, then blocks every later spot pricing update until it is cancelled.
+type blockingPricingProvider struct {
+	*pricingtest.Provider
+	calls   int32
+	blocked chan struct{}
+	once    sync.Once
+}
+
+func (p *blockingPricingProvider) GetSpotPricing(ctx context.Context) (pricing.SpotPriceList, error) {
+	if atomic.AddInt32(&p.calls, 1) == 1 {
+		return p.Spot, nil
+	}
+	p.once.Do(func() { close(p.blocked) })
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestServeShutdownDuringUpdate(t *testing.T) {
+	provider := &blockingPricingProvider{
+		Provider: &pricingtest.Provider{
+			OnDemand: pricing.OnDemandPriceList{"m5.large": 0.096},
+			Spot:     pricing.SpotPriceList{"m5.large": {"us-east-1a": 0.035}},
+		},
+		blocked: make(chan struct{}),
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error listening: %s", err)
+	}
+	registry := prometheus.NewRegistry()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a, err := app.New(ctx, app.Options{
+		Listener:         listener,
+		KubernetesClient: fake.NewSimpleClientset(),
+		PricingProvider:  provider,
+		Registerer:       registry,
+		Gatherer:         registry,
+		RefreshInterval:  10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error creating app: %s", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- a.Serve(ctx)
+	}()
+	select {
+	case <-provider.blocked:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("scheduled pricing update did not start")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("unexpected error from Serve: %s", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Serve did not return after the context was cancelled")
+	}
+	pr := a.PricingRepository()
+	if exp, got := 0, pr.UpdateErrors(); exp != got {
+		t.Errorf("expected an update interrupted by shutdown not to count as an error, got UpdateErrors == %d", got)
+	}
+	if price, ok := pr.SpotPrice("m5.large", "us-east-1a"); !ok || price != 0.035 {
+		t.Errorf("expected the last known spot price to be kept, got %v (%v)", price, ok)
+	}
+}
+
+// gatedPricingProvider blocks fetching on-demand prices until released.
+type gatedPricingProvider struct {
+	*pricingtest.Provider
+	release chan struct{}
+}
+
+func (p *gatedPricingProvider) GetOnDemandPricing(ctx context.Context) (pricing.OnDemandPriceList, error) {
+	select {
+	case <-p.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return p.Provider.GetOnDemandPricing(ctx)
+}
+
+func TestNewSeedFromStatic(t *testing.T) {
+	provider := &gatedPricingProvider{
+		Provider: &pricingtest.Provider{OnDemand: pricing.OnDemandPriceList{"m5.large": 0.125}},
+		release:  make(chan struct{}),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a, err := app.New(ctx, app.Options{
+		KubernetesClient: fake.NewSimpleClientset(),
+		PricingProvider:  provider,
+		SeedFromStatic:   true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error creating app: %s", err)
+	}
+
+	// the live update is still blocked, so the price is the one of the static snapshot
+	pr := a.PricingRepository()
+	if price, ok := pr.OnDemandPrice("m5.large"); !ok || price != 0.096 {
+		t.Errorf("expected the static on-demand price 0.096 before the first update, got %v (%v)", price, ok)
+	}
+	if exp, got := pricing.PricingSourceStatic, pr.PricingSources()[pricing.PricingTypeOnDemand]; exp != got {
+		t.Errorf("expected the on-demand pricing source to be %q, got %q", exp, got)
+	}
+
+	close(provider.release)
+	for deadline := time.Now().Add(10 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if price, _ := pr.OnDemandPrice("m5.large"); price == 0.125 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the live on-demand price to replace the static price")
+		}
+	}
+}
+
+// countingPricingProvider counts the updates of each pricing type.
+type countingPricingProvider struct {
+	*pricingtest.Provider
+	onDemandCalls int32
+	spotCalls     int32
+	fargateCalls  int32
+}
+
+func (p *countingPricingProvider) GetOnDemandPricing(ctx context.Context) (pricing.OnDemandPriceList, error) {
+	atomic.AddInt32(&p.onDemandCalls, 1)
+	return p.Provider.GetOnDemandPricing(ctx)
+}
+
+func (p *countingPricingProvider) GetSpotPricing(ctx context.Context) (pricing.SpotPriceList, error) {
+	atomic.AddInt32(&p.spotCalls, 1)
+	return p.Provider.GetSpotPricing(ctx)
+}
+
+func (p *countingPricingProvider) GetFargatePricing(ctx context.Context) (pricing.FargatePrice, error) {
+	atomic.AddInt32(&p.fargateCalls, 1)
+	return p.Provider.GetFargatePricing(ctx)
+}
+
+func TestServeRefreshesPricingTypesOnOwnSchedule(t *testing.T) {
+	for name, stagger := range map[string]bool{"simultaneous": false, "staggered": true} {
+		stagger := stagger
+		t.Run(name, func(t *testing.T) {
+			testServeSpotRefreshInterval(t, stagger)
+		})
+	}
+}
+
+// testServeSpotRefreshInterval checks spot pricing is refreshed on its own fast schedule without refreshing the other
+// pricing types with it.
+func testServeSpotRefreshInterval(t *testing.T, stagger bool) {
+	provider := &countingPricingProvider{
+		Provider: &pricingtest.Provider{
+			OnDemand: pricing.OnDemandPriceList{"m5.large": 0.096},
+			Spot:     pricing.SpotPriceList{"m5.large": {"us-east-1a": 0.035}},
+		},
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error listening: %s", err)
+	}
+	registry := prometheus.NewRegistry()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a, err := app.New(ctx, app.Options{
+		Listener:            listener,
+		KubernetesClient:    fake.NewSimpleClientset(),
+		PricingProvider:     provider,
+		Registerer:          registry,
+		Gatherer:            registry,
+		RefreshInterval:     time.Hour,
+		SpotRefreshInterval: 10 * time.Millisecond,
+		StaggerRefresh:      stagger,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error creating app: %s", err)
+	}
+	onDemandCalls := atomic.LoadInt32(&provider.onDemandCalls)
+	spotCalls := atomic.LoadInt32(&provider.spotCalls)
+	fargateCalls := atomic.LoadInt32(&provider.fargateCalls)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- a.Serve(ctx)
+	}()
+	deadline := time.After(10 * time.Second)
+	for atomic.LoadInt32(&provider.spotCalls) < spotCalls+3 {
+		select {
+		case <-deadline:
+			t.Fatalf("spot pricing was not refreshed on its own schedule")
+		case <-time.After(time.Millisecond):
+		}
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error from Serve: %s", err)
+	}
+	if exp, got := onDemandCalls, atomic.LoadInt32(&provider.onDemandCalls); exp != got {
+		t.Errorf("expected on-demand pricing not to be refreshed with spot pricing, got %d updates", got-exp)
+	}
+	if exp, got := fargateCalls, atomic.LoadInt32(&provider.fargateCalls); exp != got {
+		t.Errorf("expected Fargate pricing not to be refreshed with spot pricing, got %d updates", got-exp)
+	}
+}
+
+// zoneScopedPricingProvider only returns the spot prices of the zones it is scoped to, like the AWS provider.
+type zoneScopedPricingProvider struct {
+	*pricingtest.Provider
+	zones []string
+}
+
+func (p *zoneScopedPricingProvider) ScopeToZones(zones []string) {
+	p.zones = zones
+}
+
+func (p *zoneScopedPricingProvider) GetSpotPricing(_ context.Context) (pricing.SpotPriceList, error) {
+	if len(p.zones) == 0 {
+		return p.Spot, nil
+	}
+	prices := pricing.SpotPriceList{}
+	for instanceType, zones := range p.Spot {
+		prices[instanceType] = map[string]float64{}
+		for _, zone := range p.zones {
+			if price, ok := zones[zone]; ok {
+				prices[instanceType][zone] = price
+			}
+		}
+	}
+	return prices, nil
+}
+
+func TestNewScopeToClusterZones(t *testing.T) {
+	var nodes []runtime.Object
+	for _, zone := range []string{"us-east-1b", "us-east-1a", "us-east-1b"} {
+		nodes = append(nodes, &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "node-" + strconv.Itoa(len(nodes)),
+				Labels: map[string]string{v1.LabelTopologyZone: zone},
+			},
+		})
+	}
+	provider := &zoneScopedPricingProvider{
+		Provider: &pricingtest.Provider{
+			Spot: pricing.SpotPriceList{
+				"m5.large": {"us-east-1a": 0.035, "us-east-1b": 0.0375, "us-east-1c": 0.04},
+			},
+		},
+	}
+	registry := prometheus.NewRegistry()
+	a, err := app.New(context.Background(), app.Options{
+		KubernetesClient:    fake.NewSimpleClientset(nodes...),
+		PricingProvider:     provider,
+		Registerer:          registry,
+		Gatherer:            registry,
+		ScopeToClusterZones: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error creating app: %s", err)
+	}
+	if exp, got := []string{"us-east-1a", "us-east-1b"}, provider.zones; strings.Join(exp, ",") != strings.Join(got, ",") {
+		t.Errorf("expected spot pricing scoped to %v, got %v", exp, got)
+	}
+	pr := a.PricingRepository()
+	if _, ok := pr.SpotPrice("m5.large", "us-east-1c"); ok {
+		t.Errorf("expected no spot price outside of the zones of the cluster")
+	}
+	if price, ok := pr.SpotPrice("m5.large", "us-east-1b"); !ok || price != 0.0375 {
+		t.Errorf("expected the spot price in us-east-1b, got %v (%v)", price, ok)
+	}
+}
+
+func TestServeRescopesToClusterZones(t *testing.T) {
+	node := func(name, zone string) *v1.Node {
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{v1.LabelTopologyZone: zone},
+			},
+		}
+	}
+	cs := fake.NewSimpleClientset(node("node-0", "us-east-1a"))
+	provider := &zoneScopedPricingProvider{
+		Provider: &pricingtest.Provider{
+			Spot: pricing.SpotPriceList{
+				"m5.large": {"us-east-1a": 0.035, "us-east-1b": 0.0375, "us-east-1c": 0.04},
+			},
+		},
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error listening: %s", err)
+	}
+	registry := prometheus.NewRegistry()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a, err := app.New(ctx, app.Options{
+		Listener:            listener,
+		KubernetesClient:    cs,
+		PricingProvider:     provider,
+		Registerer:          registry,
+		Gatherer:            registry,
+		ScopeToClusterZones: true,
+		RefreshInterval:     10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error creating app: %s", err)
+	}
+	pr := a.PricingRepository()
+	if _, ok := pr.SpotPrice("m5.large", "us-east-1c"); ok {
+		t.Fatalf("expected no spot price outside of the zones of the cluster")
+	}
+
+	// a node launched in a new zone gets a spot price from the next refresh on
+	_, err = cs.CoreV1().Nodes().Create(ctx, node("node-1", "us-east-1c"), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error creating node: %s", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- a.Serve(ctx)
+	}()
+	deadline := time.After(10 * time.Second)
+	for {
+		if _, ok := pr.SpotPrice("m5.large", "us-east-1c"); ok {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("spot pricing was not rescoped to the new zone of the cluster")
+		case <-time.After(time.Millisecond):
+		}
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error from Serve: %s", err)
+	}
+	if _, ok := pr.SpotPrice("m5.large", "us-east-1b"); ok {
+		t.Errorf("expected no spot price outside of the zones of the cluster")
+	}
+}
+
+func TestNewNoAWSRegion(t *testing.T) {
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_CONFIG_FILE", t.TempDir()+"/config")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", t.TempDir()+"/credentials")
+
+	registry := prometheus.NewRegistry()
+	_, err := app.New(context.Background(), app.Options{
+		KubernetesClient: fake.NewSimpleClientset(),
+		Registerer:       registry,
+		Gatherer:         registry,
+	})
+	if err == nil {
+		t.Fatal("expected error without an AWS region")
+	}
+	if !strings.Contains(err.Error(), "no AWS region configured") {
+		t.Errorf("expected a clear error about the missing region, got %q", err)
+	}
+}
+
+func TestNewKubeconfig(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"apiVersion":"v1","kind":"List","items":[]}`)
+	}))
+	defer server.Close()
+
+	kubeconfig := t.TempDir() + "/kubeconfig"
+	err := os.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: `+server.URL+`
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+current-context: test
+users:
+- name: test
+  user:
+    token: test
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the -kubeconfig flag is registered by controller-runtime, which loads the client config from it
+	if err := flag.Set("kubeconfig", kubeconfig); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = flag.Set("kubeconfig", "")
+	})
+
+	registry := prometheus.NewRegistry()
+	a, err := app.New(context.Background(), app.Options{
+		PricingProvider: pricing.NewStaticProvider(),
+		Registerer:      registry,
+		Gatherer:        registry,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error creating app: %s", err)
+	}
+	// the node doesn't exist, only the requests made to the server matter
+	_ = a.PriceNode(context.Background(), io.Discard, "mynode")
+	if len(requests) == 0 {
+		t.Fatal("expected requests to the API server in the kubeconfig")
+	}
+	if exp, got := "/api/v1/pods", requests[0]; exp != got {
+		t.Errorf("expected first request to %s, got %s", exp, got)
+	}
+}
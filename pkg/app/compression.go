@@ -0,0 +1,79 @@
+package app
+
+import (
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Metrics compression settings besides the gzip levels from gzip.BestSpeed to gzip.BestCompression.
+const (
+	// MetricsCompressionDefault leaves compressing metrics to promhttp, which uses gzip.DefaultCompression.
+	MetricsCompressionDefault = 0
+	// MetricsCompressionDisabled never compresses metrics.
+	MetricsCompressionDisabled = -1
+)
+
+// ValidateMetricsCompression returns an error if level is neither a gzip level from gzip.BestSpeed to
+// gzip.BestCompression nor MetricsCompressionDefault or MetricsCompressionDisabled.
+func ValidateMetricsCompression(level int) error {
+	if level < MetricsCompressionDisabled || level > gzip.BestCompression {
+		return fmt.Errorf(
+			"invalid metrics compression %d, must be a gzip level from %d to %d, %d for the default or %d to disable it",
+			level,
+			gzip.BestSpeed,
+			gzip.BestCompression,
+			MetricsCompressionDefault,
+			MetricsCompressionDisabled,
+		)
+	}
+	return nil
+}
+
+// gzipHandler compresses the responses of handler at level for clients which accept gzip.
+func gzipHandler(handler http.Handler, level int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		gz, err := gzip.NewWriterLevel(w, level)
+		if err != nil {
+			// the level is validated up front, but serve uncompressed rather than failing the scrape
+			handler.ServeHTTP(w, r)
+			return
+		}
+		defer gz.Close()
+		w.Header().Set("Content-Encoding", "gzip")
+		handler.ServeHTTP(&gzipResponseWriter{ResponseWriter: w, writer: gz}, r)
+	})
+}
+
+// acceptsGzip returns true if the Accept-Encoding header of r includes gzip.
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, _, _ := strings.Cut(strings.TrimSpace(encoding), ";")
+		if strings.EqualFold(name, "gzip") {
+			return true
+		}
+	}
+	return false
+}
+
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	writer *gzip.Writer
+}
+
+func (w *gzipResponseWriter) WriteHeader(statusCode int) {
+	// the length of the uncompressed body doesn't apply to the compressed one
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	w.Header().Del("Content-Length")
+	return w.writer.Write(b)
+}
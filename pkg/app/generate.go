@@ -0,0 +1,27 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing"
+)
+
+// GenerateStaticPrices fetches the current on-demand prices from AWS and writes them to w as the Go source of the
+// snapshot embedded in the StaticProvider. Only the AWS options are used, no Kubernetes access is required.
+func GenerateStaticPrices(ctx context.Context, w io.Writer, opts Options) error {
+	a := &App{opts: opts}
+	cfg, err := a.loadAWSConfig(ctx)
+	if err != nil {
+		return err
+	}
+	provider := pricing.NewAWSProvider(*cfg, a.awsProviderOptions()...)
+	provider.Filters = opts.PricingFilters
+	prices, err := provider.GetOnDemandPricing(ctx)
+	if err != nil {
+		return fmt.Errorf("fetching on-demand pricing: %w", err)
+	}
+	return pricing.WriteStaticOnDemandPrices(w, provider.Region, time.Now(), prices)
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+
+	"github.com/sapslaj/eks-pricing-exporter/pkg/collector"
+)
+
+// otlpMeterName is the name of the meter the OTLP metrics are recorded with.
+const otlpMeterName = "github.com/sapslaj/eks-pricing-exporter"
+
+// newOTLPMeterProvider returns a meter provider periodically exporting metrics to the OTLP/HTTP endpoint, a URL like
+// http://otel-collector:4318. The export interval is taken from OTEL_METRIC_EXPORT_INTERVAL, defaulting to a minute.
+func newOTLPMeterProvider(ctx context.Context, endpoint string) (*sdkmetric.MeterProvider, error) {
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("parsing otlp endpoint: %w", err)
+	}
+	if endpointURL.Host == "" {
+		return nil, fmt.Errorf("invalid otlp endpoint %q: must be a URL like http://otel-collector:4318", endpoint)
+	}
+	exporterOpts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(endpointURL.Host)}
+	if endpointURL.Scheme == "http" {
+		exporterOpts = append(exporterOpts, otlpmetrichttp.WithInsecure())
+	}
+	if endpointURL.Path != "" && endpointURL.Path != "/" {
+		exporterOpts = append(exporterOpts, otlpmetrichttp.WithURLPath(endpointURL.Path))
+	}
+	exporter, err := otlpmetrichttp.New(ctx, exporterOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("creating otlp exporter: %w", err)
+	}
+	return sdkmetric.NewMeterProvider(sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter))), nil
+}
+
+// registerOTelCollector records the node metrics of the collector registered by Handler with the meter provider.
+func (a *App) registerOTelCollector(provider *sdkmetric.MeterProvider) error {
+	_, err := collector.NewOTelCollector(provider.Meter(otlpMeterName), a.collector)
+	if err != nil {
+		return fmt.Errorf("registering otel collector: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/sapslaj/eks-pricing-exporter/pkg/model"
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing"
+)
+
+// PriceNode writes the resolved price for the named node to w along with which lookup matched.
+func PriceNode(
+	ctx context.Context,
+	w io.Writer,
+	cs kubernetes.Interface,
+	pricingRepository *pricing.Repository,
+	name string,
+	opts ...model.ClusterOption,
+) error {
+	cluster := model.NewCluster(opts...)
+	err := cluster.Populate(ctx, cs)
+	if err != nil {
+		return fmt.Errorf("getting cluster information failed: %w", err)
+	}
+	node, ok := cluster.GetNode(name)
+	if !ok {
+		return fmt.Errorf("node %q not found", name)
+	}
+	node.UpdatePrice(pricingRepository)
+
+	fmt.Fprintf(w, "node:          %s\n", node.Name())
+	fmt.Fprintf(w, "capacity type: %s\n", node.CapacityType())
+	fmt.Fprintf(w, "instance type: %s\n", node.InstanceType())
+	fmt.Fprintf(w, "zone:          %s\n", node.Zone())
+	fmt.Fprintf(w, "region:        %s\n", node.Region())
+	fmt.Fprintf(w, "hourly price:  %g\n", node.Price)
+	fmt.Fprintf(w, "resolved by:   %s\n", node.PriceReason)
+	return nil
+}
@@ -0,0 +1,54 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/sapslaj/eks-pricing-exporter/pkg/app"
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing"
+)
+
+func TestPriceNode(t *testing.T) {
+	cs := fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mynode",
+			Labels: map[string]string{
+				"karpenter.sh/capacity-type": "on-demand",
+				v1.LabelInstanceTypeStable:   "m5.large",
+				v1.LabelTopologyZone:         "us-east-1a",
+				v1.LabelTopologyRegion:       "us-east-1",
+			},
+		},
+	})
+	pr := pricing.NewRepository(pricing.NewStaticProvider())
+	ctx := context.Background()
+	if err := pr.UpdatePricing(ctx); err != nil {
+		t.Fatalf("unexpected error updating repository: %s", err)
+	}
+
+	var out bytes.Buffer
+	if err := app.PriceNode(ctx, &out, cs, pr, "mynode"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for _, line := range []string{
+		"node:          mynode",
+		"capacity type: on-demand",
+		"instance type: m5.large",
+		"hourly price:  0.096",
+		"resolved by:   on-demand price for m5.large",
+	} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("expected output to contain %q, got:\n%s", line, out.String())
+		}
+	}
+
+	if err := app.PriceNode(ctx, &out, cs, pr, "missing"); err == nil {
+		t.Errorf("expected an error for a missing node")
+	}
+}
@@ -0,0 +1,27 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+// DefaultPushgatewayJob is the job metrics are pushed to the Pushgateway under unless configured otherwise.
+const DefaultPushgatewayJob = "eks_pricing_exporter"
+
+// Push collects the metrics once and pushes them to the Pushgateway at PushgatewayURL, replacing the metrics last
+// pushed under PushgatewayJob, e.g. for cost snapshots taken by a CronJob instead of serving /metrics.
+func (a *App) Push(ctx context.Context) error {
+	// push the live pricing rather than the static pricing it was seeded with
+	a.initialUpdate.Wait()
+	if _, err := a.Handler(ctx); err != nil {
+		return err
+	}
+	// the Go runtime and process metrics of a short-lived job are of no use
+	err := push.New(a.opts.PushgatewayURL, a.opts.PushgatewayJob).Gatherer(a.exporterMetrics).PushContext(ctx)
+	if err != nil {
+		return fmt.Errorf("pushing metrics to %s: %w", a.opts.PushgatewayURL, err)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package app
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// writeTextfiles writes the metrics to the TextfileOutput for the node-exporter textfile collector right away and then
+// every RefreshInterval until ctx is done. Each write goes to a temporary file which is renamed over the output, so
+// the textfile collector never reads a partially written file.
+func (a *App) writeTextfiles(ctx context.Context) {
+	ticker := time.NewTicker(a.opts.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		// the Go runtime and process metrics of the exporter would collide with those of node-exporter
+		if err := prometheus.WriteToTextfile(a.opts.TextfileOutput, a.exporterMetrics); err != nil {
+			// keep the last written metrics and try again on the next refresh
+			log.Printf("could not write metrics to %s: %s", a.opts.TextfileOutput, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
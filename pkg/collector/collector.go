@@ -3,89 +3,1786 @@ package collector
 import (
 	"context"
 	"log"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/samber/lo"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/sapslaj/eks-pricing-exporter/pkg/model"
 	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing"
 )
 
+// defaultWorkers is the number of nodes priced and collected concurrently unless configured with WithWorkers.
+const defaultWorkers = 8
+
+var nodeLabels = []string{"node", "capacity_type", "instance_type", "zone", "region", "status"}
+
+func nodeLabelValues(node *model.Node) []string {
+	return []string{
+		node.Name(),                  // "node"
+		node.CapacityType().String(), // "capacity_type"
+		node.InstanceType(),          // "instance_type"
+		node.Zone(),                  // "zone"
+		node.Region(),                // "region"
+		node.Status().String(),       // "status"
+	}
+}
+
 type collectorMetricDesc struct {
-	nodeInfo    *prometheus.Desc
-	hourlyPrice *prometheus.Desc
+	nodeInfo           *prometheus.Desc
+	nodeReady          *prometheus.Desc
+	nodeCordoned       *prometheus.Desc
+	nodeTaintCount     *prometheus.Desc
+	nodeEmpty          *prometheus.Desc
+	nodeTainted        *prometheus.Desc
+	priceArchMismatch  *prometheus.Desc
+	nodeHardwareInfo   *prometheus.Desc
+	hourlyPrice        *prometheus.Desc
+	hourlyPricePerVCPU *prometheus.Desc
+	hourlyPricePerGB   *prometheus.Desc
+	hourlyPricePerPod  *prometheus.Desc
+	hourlyPricePerGPU  *prometheus.Desc
+	physicalGPUs       *prometheus.Desc
+	advertisedGPUs     *prometheus.Desc
+	priceChangeRatio   *prometheus.Desc
+	clusterHourlyPrice *prometheus.Desc
+	capacityTypePrice  *prometheus.Desc
+	nodePriceMin       *prometheus.Desc
+	nodePriceMax       *prometheus.Desc
+	nodePriceMinByType *prometheus.Desc
+	nodePriceMaxByType *prometheus.Desc
+	emptyHourlyPrice   *prometheus.Desc
+	controlPlanePrice  *prometheus.Desc
+	podHourlyPrice     *prometheus.Desc
+	namespacePrice     *prometheus.Desc
+	billablePrice      *prometheus.Desc
+	systemOverhead     *prometheus.Desc
+	nodeCount          *prometheus.Desc
+	instanceTypeCount  *prometheus.Desc
+	priceUnknownCount  *prometheus.Desc
+	pricedNodeRatio    *prometheus.Desc
+	updateErrors       *prometheus.Desc
+	priceDrift         *prometheus.Desc
+	pricingSource      *prometheus.Desc
+	pricingStaleness   *prometheus.Desc
+	spotPriceMin       *prometheus.Desc
+	familyPricePerVCPU *prometheus.Desc
+	nodePoolSpotPrice  *prometheus.Desc
+	nodePoolPrice      *prometheus.Desc
+	spotPriceAvg       *prometheus.Desc
+	spotZonesKnown     *prometheus.Desc
+	effectiveDate      *prometheus.Desc
+	dataAge            *prometheus.Desc
+	// spotPriceDistribution is only set if WithSpotPriceHistogram is enabled
+	spotPriceDistribution *prometheus.Desc
+	// monthlyPrice and clusterMonthlyPrice are only set if WithMonthlyPrices is enabled
+	monthlyPrice        *prometheus.Desc
+	clusterMonthlyPrice *prometheus.Desc
+	// debugPrice is only set if WithDebugInstanceTypes is given instance types
+	debugPrice *prometheus.Desc
+	// smoothedSpotPrice is only set if WithSmoothedSpotPrices is enabled
+	smoothedSpotPrice *prometheus.Desc
+	// fargateAccumulatedCost is only set if WithFargateAccumulatedCost is enabled
+	fargateAccumulatedCost *prometheus.Desc
 }
 
+// perNode returns the metric descriptions which produce one series per node, or per taint of a node for nodeTainted.
+func (d collectorMetricDesc) perNode() []*prometheus.Desc {
+	descs := []*prometheus.Desc{
+		d.nodeInfo,
+		d.nodeReady,
+		d.nodeCordoned,
+		d.nodeTaintCount,
+		d.nodeTainted,
+		d.nodeEmpty,
+		d.nodeHardwareInfo,
+		d.hourlyPrice,
+		d.hourlyPricePerVCPU,
+		d.hourlyPricePerGB,
+		d.hourlyPricePerPod,
+		d.hourlyPricePerGPU,
+		d.physicalGPUs,
+		d.advertisedGPUs,
+		d.priceChangeRatio,
+	}
+	if d.monthlyPrice != nil {
+		descs = append(descs, d.monthlyPrice)
+	}
+	if d.smoothedSpotPrice != nil {
+		descs = append(descs, d.smoothedSpotPrice)
+	}
+	if d.fargateAccumulatedCost != nil {
+		descs = append(descs, d.fargateAccumulatedCost)
+	}
+	return descs
+}
+
+// hoursPerMonth is the number of hours AWS uses to convert hourly prices to monthly prices.
+const hoursPerMonth = 730
+
 type Collector struct {
-	metricDesc        collectorMetricDesc
-	parentCtx         context.Context
-	cs                *kubernetes.Clientset
-	pricingRepository *pricing.Repository
+	metricDesc         collectorMetricDesc
+	namespace          string
+	parentCtx          context.Context
+	cs                 kubernetes.Interface
+	pricingRepository  *pricing.Repository
+	maxSeries          int
+	excludeCordoned    bool
+	priceUnitSuffix    string
+	unitSuffixes       bool
+	priceCurrency      string
+	instanceLookup     *pricing.InstanceLookup
+	nodeGracePeriod    time.Duration
+	workers            int
+	spotPriceHistogram bool
+	monthlyPrices      bool
+	podBinding         model.PodBindingStrategy
+	nodeOpts           []model.NodeOption
+	podAttribution     model.PodAttribution
+	workloadLabels     bool
+	excludeNamespaces  map[string]bool
+	debugInstanceTypes []string
+	pricePrecision     int
+	excludeFargateType bool
+	fargateAccumulated bool
+	smoothedSpot       bool
+	metricOverrides    MetricOverrides
+	// instanceTypeAllowlist and instanceTypeDenylist are path.Match patterns of the instance types to price
+	instanceTypeAllowlist []string
+	instanceTypeDenylist  []string
+	// maxInstanceTypeSeries limits the pricing metrics with one series per instance type
+	maxInstanceTypeSeries int
+	// spotPriceDistribution accumulates the prices of the spot nodes across collections
+	spotPriceDistribution prometheus.Histogram
+
+	// snapshotMu guards the last successfully populated nodes, which are re-emitted if populating the cluster fails
+	snapshotMu        sync.Mutex
+	snapshotNodes     []*model.Node
+	snapshotPopulated time.Time
+}
+
+// Option configures optional behavior of the Collector.
+type Option func(*Collector)
+
+// WithMaxSeries limits the number of per-node series the collector will emit. If the number of nodes multiplied by
+// the number of per-node metrics would exceed maxSeries, only the cluster-level aggregates are emitted. A value of 0
+// disables the limit.
+func WithMaxSeries(maxSeries int) Option {
+	return func(c *Collector) {
+		c.maxSeries = maxSeries
+	}
+}
+
+// WithMaxInstanceTypeSeries limits the number of series of the pricing metrics with one series per instance type, like
+// the spot price aggregates and on-demand effective dates. If they would produce more than maxSeries series, they
+// aren't emitted. A value of 0 disables the limit.
+func WithMaxInstanceTypeSeries(maxSeries int) Option {
+	return func(c *Collector) {
+		c.maxInstanceTypeSeries = maxSeries
+	}
+}
+
+// WithExcludeCordoned omits cordoned nodes from the price metrics and totals.
+func WithExcludeCordoned(excludeCordoned bool) Option {
+	return func(c *Collector) {
+		c.excludeCordoned = excludeCordoned
+	}
+}
+
+// WithUnitSuffixes suffixes the price metric names with their unit, the currency of the prices, e.g.
+// eks_node_hourly_price_usd, following the Prometheus and OpenMetrics naming conventions.
+func WithUnitSuffixes(unitSuffixes bool) Option {
+	return func(c *Collector) {
+		c.unitSuffixes = unitSuffixes
+	}
+}
+
+// WithPriceCurrency sets the currency of the prices for their unit suffix, see pricing.RegionCurrency. Defaults to
+// USD.
+func WithPriceCurrency(currency string) Option {
+	return func(c *Collector) {
+		c.priceCurrency = currency
+	}
+}
+
+// WithInstanceLookup uses instanceLookup to fill in the instance type and zone of nodes which are missing the labels
+// for them from their provider ID.
+func WithInstanceLookup(instanceLookup *pricing.InstanceLookup) Option {
+	return func(c *Collector) {
+		c.instanceLookup = instanceLookup
+	}
+}
+
+// WithNodeGracePeriod skips nodes younger than nodeGracePeriod from the unknown price metrics, since brand-new nodes
+// are often missing the labels needed to price them for a few seconds. They are still included in the node counts.
+func WithNodeGracePeriod(nodeGracePeriod time.Duration) Option {
+	return func(c *Collector) {
+		c.nodeGracePeriod = nodeGracePeriod
+	}
+}
+
+// WithWorkers sets the number of nodes priced and collected concurrently. Values less than 1 are treated as 1.
+func WithWorkers(workers int) Option {
+	return func(c *Collector) {
+		c.workers = workers
+	}
+}
+
+// WithSpotPriceHistogram additionally emits the prices of all spot nodes as a native histogram so the spread of spot
+// prices across the cluster can be seen in a single metric.
+func WithSpotPriceHistogram(spotPriceHistogram bool) Option {
+	return func(c *Collector) {
+		c.spotPriceHistogram = spotPriceHistogram
+	}
+}
+
+// WithMonthlyPrices additionally emits the node and cluster prices per month of 730 hours, the convention AWS uses for
+// monthly estimates.
+func WithMonthlyPrices(monthlyPrices bool) Option {
+	return func(c *Collector) {
+		c.monthlyPrices = monthlyPrices
+	}
+}
+
+// WithCapacityTypeLabels reads the capacity type of nodes without the EKS or Karpenter capacity type labels from
+// labels, see model.WithCapacityTypeLabels.
+func WithCapacityTypeLabels(labels []string) Option {
+	return func(c *Collector) {
+		if len(labels) != 0 {
+			c.nodeOpts = append(c.nodeOpts, model.WithCapacityTypeLabels(labels...))
+		}
+	}
+}
+
+// WithDefaultCapacityType sets the capacity type of nodes without any capacity type label, see
+// model.WithDefaultCapacityType.
+func WithDefaultCapacityType(capacityType model.NodeCapacityType) Option {
+	return func(c *Collector) {
+		if capacityType != model.NodeUnknownCapacityType {
+			c.nodeOpts = append(c.nodeOpts, model.WithDefaultCapacityType(capacityType))
+		}
+	}
+}
+
+// WithHybridHourlyPrice sets the flat hourly price of EKS Hybrid Nodes, see model.WithHybridHourlyPrice.
+func WithHybridHourlyPrice(price float64) Option {
+	return func(c *Collector) {
+		if price != 0 {
+			c.nodeOpts = append(c.nodeOpts, model.WithHybridHourlyPrice(price))
+		}
+	}
+}
+
+// WithDebugInstanceTypes additionally emits the price the pricing repository resolves for each capacity type and zone
+// of instanceTypes along with the reason and pricing source, with unknown prices as NaN, to debug their pricing.
+func WithDebugInstanceTypes(instanceTypes []string) Option {
+	return func(c *Collector) {
+		c.debugInstanceTypes = instanceTypes
+	}
+}
+
+// WithPricePrecision rounds the emitted prices to precision decimal places, since not every consumer of the metrics
+// copes with the full precision of the computed prices. A precision of 0 or less disables rounding, the default.
+func WithPricePrecision(precision int) Option {
+	return func(c *Collector) {
+		c.pricePrecision = precision
+	}
+}
+
+// WithExcludeFargateInstanceTypes leaves Fargate nodes out of the number of distinct instance types, since each
+// Fargate pod size counts as its own synthetic instance type.
+func WithExcludeFargateInstanceTypes(excludeFargate bool) Option {
+	return func(c *Collector) {
+		c.excludeFargateType = excludeFargate
+	}
+}
+
+// WithInstanceTypeAllowlist only emits price metrics for nodes whose instance type matches one of patterns, e.g.
+// m5.* for every m5 instance type, and leaves other nodes out of the totals like WithExcludeCordoned. Patterns use the
+// syntax of path.Match. Fargate nodes are always priced.
+func WithInstanceTypeAllowlist(patterns []string) Option {
+	return func(c *Collector) {
+		c.instanceTypeAllowlist = patterns
+	}
+}
+
+// WithInstanceTypeDenylist leaves nodes whose instance type matches one of patterns out of the price metrics and
+// totals, even if they match the allowlist. Patterns use the syntax of path.Match. Fargate nodes are always priced.
+func WithInstanceTypeDenylist(patterns []string) Option {
+	return func(c *Collector) {
+		c.instanceTypeDenylist = patterns
+	}
+}
+
+// WithPodBindingStrategy sets which pods count towards the resources used on their node and the attribution of its
+// price, defaults to model.PodBindingActive.
+func WithPodBindingStrategy(podBinding model.PodBindingStrategy) Option {
+	return func(c *Collector) {
+		c.podBinding = podBinding
+	}
+}
+
+// WithSystemOverhead sets how the share of node prices attributed to DaemonSet and kube-system pods is handled in the
+// pod prices, defaults to model.SystemOverheadNone.
+func WithSystemOverhead(systemOverhead model.SystemOverhead) Option {
+	return func(c *Collector) {
+		c.podAttribution.SystemOverhead = systemOverhead
+	}
 }
 
-func NewCollector(ctx context.Context, cs *kubernetes.Clientset, pricingRepository *pricing.Repository) *Collector {
-	namespace := "eks"
-	return &Collector{
+// WithAttributionBasis sets which pod resources node prices are attributed to pods by, defaults to
+// model.AttributionBasisRequests.
+func WithAttributionBasis(basis model.AttributionBasis) Option {
+	return func(c *Collector) {
+		c.podAttribution.Basis = basis
+	}
+}
+
+// WithWorkloadLabels adds the kind and name of the controller managing each pod, e.g. its Deployment, as the
+// workload_kind and workload labels of the pod price metric so costs can be grouped by workload.
+func WithWorkloadLabels(workloadLabels bool) Option {
+	return func(c *Collector) {
+		c.workloadLabels = workloadLabels
+	}
+}
+
+// WithExcludeNamespaces leaves pods in the given namespaces, e.g. kube-system, out of the pod prices and the namespace
+// and billable totals.
+func WithExcludeNamespaces(namespaces []string) Option {
+	return func(c *Collector) {
+		c.excludeNamespaces = map[string]bool{}
+		for _, namespace := range namespaces {
+			c.excludeNamespaces[namespace] = true
+		}
+	}
+}
+
+// WithFargateAccumulatedCost emits the cost of each Fargate pod accumulated since it started, its hourly price
+// prorated by its runtime.
+func WithFargateAccumulatedCost(accumulatedCost bool) Option {
+	return func(c *Collector) {
+		c.fargateAccumulated = accumulatedCost
+	}
+}
+
+// WithSmoothedSpotPrices emits the moving average of the spot price of each spot node next to its hourly price, see
+// pricing.WithSpotPriceSmoothing.
+func WithSmoothedSpotPrices(smoothedSpot bool) Option {
+	return func(c *Collector) {
+		c.smoothedSpot = smoothedSpot
+	}
+}
+
+func NewCollector(
+	ctx context.Context,
+	cs kubernetes.Interface,
+	pricingRepository *pricing.Repository,
+	opts ...Option,
+) *Collector {
+	namespace := pricing.MetricsNamespace
+	c := &Collector{
+		namespace:         namespace,
 		parentCtx:         ctx,
 		cs:                cs,
 		pricingRepository: pricingRepository,
-		metricDesc: collectorMetricDesc{
-			nodeInfo: prometheus.NewDesc(
-				prometheus.BuildFQName(namespace, "node", "info"),
-				"info labels about the node",
-				[]string{"node", "capacity_type", "instance_type", "zone", "region", "status"},
-				nil,
-			),
-			hourlyPrice: prometheus.NewDesc(
-				prometheus.BuildFQName(namespace, "node", "hourly_price"),
-				"hourly price of node",
-				[]string{"node", "capacity_type", "instance_type", "zone", "region", "status"},
-				nil,
-			),
+		workers:           defaultWorkers,
+		priceCurrency:     "USD",
+		podAttribution: model.PodAttribution{
+			Basis:          model.AttributionBasisRequests,
+			SystemOverhead: model.SystemOverheadNone,
 		},
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.unitSuffixes {
+		c.priceUnitSuffix = "_" + strings.ToLower(c.priceCurrency)
+	}
+	c.newNodeDescs(namespace)
+	c.newNodePriceDescs(namespace)
+	c.newClusterDescs(namespace)
+	c.newGroupDescs(namespace)
+	c.newPricingDescs(namespace)
+	c.newOptionalDescs(namespace)
+	return c
+}
+
+// newNodeDescs builds the descs of the per-node metrics which aren't prices.
+func (c *Collector) newNodeDescs(namespace string) {
+	d := &c.metricDesc
+	d.nodeInfo = c.newDesc(
+		prometheus.BuildFQName(namespace, "node", "info"),
+		"info labels about the node",
+		append(nodeLabels, "os_image", "os_distribution", "instance_family"),
+		nil,
+	)
+	d.nodeHardwareInfo = c.newDesc(
+		prometheus.BuildFQName(namespace, "node", "hardware_info"),
+		"info labels about the hardware of the instance type of the node, with the EBS bandwidth in Mbps and the size "+
+			"of the instance store in GB",
+		[]string{"node", "instance_type", "network_performance", "ebs_bandwidth", "instance_storage_gb"},
+		nil,
+	)
+	d.nodeReady = c.newDesc(
+		prometheus.BuildFQName(namespace, "node", "ready"),
+		"1 if the node is ready, 0 otherwise",
+		[]string{"node", "instance_type"},
+		nil,
+	)
+	d.nodeCordoned = c.newDesc(
+		prometheus.BuildFQName(namespace, "node", "cordoned"),
+		"1 if the node is cordoned, 0 otherwise",
+		[]string{"node", "instance_type"},
+		nil,
+	)
+	d.nodeTaintCount = c.newDesc(
+		prometheus.BuildFQName(namespace, "node", "taint_count"),
+		"number of taints on the node",
+		[]string{"node", "instance_type"},
+		nil,
+	)
+	d.nodeTainted = c.newDesc(
+		prometheus.BuildFQName(namespace, "node", "tainted"),
+		"info labels for each taint on the node",
+		[]string{"node", "key", "effect"},
+		nil,
+	)
+	d.priceArchMismatch = c.newDesc(
+		prometheus.BuildFQName(namespace, "node", "price_arch_mismatch"),
+		"nodes priced at the on-demand price of an instance type which doesn't support the architecture of the "+
+			"node, suggesting the price is of the wrong instance type",
+		[]string{"node", "instance_type", "node_arch", "price_arch"},
+		nil,
+	)
+	d.nodeEmpty = c.newDesc(
+		prometheus.BuildFQName(namespace, "node", "empty"),
+		"1 if the node is only running DaemonSet and kube-system pods, 0 otherwise",
+		[]string{"node", "instance_type"},
+		nil,
+	)
+	d.physicalGPUs = c.newDesc(
+		prometheus.BuildFQName(namespace, "node", "physical_gpus"),
+		"number of physical GPUs of the instance type of the node",
+		[]string{"node", "instance_type"},
+		nil,
+	)
+	d.advertisedGPUs = c.newDesc(
+		prometheus.BuildFQName(namespace, "node", "advertised_gpus"),
+		"number of nvidia.com/gpu allocatable on the node, more than the physical GPUs with time-slicing or MIG",
+		[]string{"node", "instance_type"},
+		nil,
+	)
+	d.nodeCount = c.newDesc(
+		prometheus.BuildFQName(namespace, "node", "count"),
+		"number of nodes by capacity type",
+		[]string{"capacity_type"},
+		nil,
+	)
+	d.priceUnknownCount = c.newDesc(
+		prometheus.BuildFQName(namespace, "node", "price_unknown_count"),
+		"number of nodes whose price could not be determined",
+		nil,
+		nil,
+	)
+}
+
+// newNodePriceDescs builds the descs of the per-node price metrics.
+func (c *Collector) newNodePriceDescs(namespace string) {
+	d := &c.metricDesc
+	d.hourlyPrice = c.newDesc(
+		prometheus.BuildFQName(namespace, "node", "hourly_price"+c.priceUnitSuffix),
+		"hourly price of node",
+		append(nodeLabels, "price_source"),
+		nil,
+	)
+	d.hourlyPricePerVCPU = c.newDesc(
+		prometheus.BuildFQName(namespace, "node", "hourly_price_per_vcpu"+c.priceUnitSuffix),
+		"hourly price of node divided by the number of vCPUs of its instance type",
+		nodeLabels,
+		nil,
+	)
+	d.hourlyPricePerGB = c.newDesc(
+		prometheus.BuildFQName(namespace, "node", "hourly_price_per_gb_memory"+c.priceUnitSuffix),
+		"hourly price of node divided by the memory in GiB of its instance type",
+		nodeLabels,
+		nil,
+	)
+	d.hourlyPricePerGPU = c.newDesc(
+		prometheus.BuildFQName(namespace, "node", "hourly_price_per_gpu"+c.priceUnitSuffix),
+		"hourly price of node divided by the physical GPUs of its instance type",
+		nodeLabels,
+		nil,
+	)
+	d.hourlyPricePerPod = c.newDesc(
+		prometheus.BuildFQName(namespace, "node", "hourly_price_per_ready_pod"+c.priceUnitSuffix),
+		"hourly price of node divided by the number of workload pods on it, which excludes DaemonSet and kube-system "+
+			"pods, not emitted for nodes without workload pods",
+		nodeLabels,
+		nil,
+	)
+	d.priceChangeRatio = c.newDesc(
+		prometheus.BuildFQName(namespace, "node", "price_change_ratio"),
+		"hourly price of node divided by its on-demand or spot price before the last refresh of that pricing, not "+
+			"emitted for nodes without such a previous price",
+		nodeLabels,
+		nil,
+	)
+	d.systemOverhead = c.newDesc(
+		prometheus.BuildFQName(namespace, "node", "system_overhead_hourly_price"+c.priceUnitSuffix),
+		"share of the hourly price of the node attributed to DaemonSet and kube-system pods",
+		[]string{"node"},
+		nil,
+	)
+}
+
+// newClusterDescs builds the descs of the cluster-wide metrics.
+func (c *Collector) newClusterDescs(namespace string) {
+	d := &c.metricDesc
+	d.clusterHourlyPrice = c.newDesc(
+		prometheus.BuildFQName(namespace, "cluster", "hourly_price"+c.priceUnitSuffix),
+		"hourly price of all nodes with a known price",
+		nil,
+		nil,
+	)
+	d.capacityTypePrice = c.newDesc(
+		prometheus.BuildFQName(namespace, "cluster", "hourly_price_by_capacity_type"+c.priceUnitSuffix),
+		"hourly price of all nodes with a known price by capacity type",
+		[]string{"capacity_type"},
+		nil,
+	)
+	d.nodePriceMin = c.newDesc(
+		prometheus.BuildFQName(namespace, "cluster", "node_price_min"+c.priceUnitSuffix),
+		"lowest hourly price of the nodes with a known price",
+		nil,
+		nil,
+	)
+	d.nodePriceMax = c.newDesc(
+		prometheus.BuildFQName(namespace, "cluster", "node_price_max"+c.priceUnitSuffix),
+		"highest hourly price of the nodes with a known price",
+		nil,
+		nil,
+	)
+	d.nodePriceMinByType = c.newDesc(
+		prometheus.BuildFQName(namespace, "cluster", "node_price_min_by_capacity_type"+c.priceUnitSuffix),
+		"lowest hourly price of the nodes with a known price by capacity type",
+		[]string{"capacity_type"},
+		nil,
+	)
+	d.nodePriceMaxByType = c.newDesc(
+		prometheus.BuildFQName(namespace, "cluster", "node_price_max_by_capacity_type"+c.priceUnitSuffix),
+		"highest hourly price of the nodes with a known price by capacity type",
+		[]string{"capacity_type"},
+		nil,
+	)
+	d.emptyHourlyPrice = c.newDesc(
+		prometheus.BuildFQName(namespace, "cluster", "empty_node_hourly_price"+c.priceUnitSuffix),
+		"hourly price of all nodes with a known price which are only running DaemonSet and kube-system pods",
+		nil,
+		nil,
+	)
+	d.controlPlanePrice = c.newDesc(
+		prometheus.BuildFQName(namespace, "cluster", "control_plane_hourly_price"+c.priceUnitSuffix),
+		"hourly fee charged for the EKS cluster control plane",
+		nil,
+		nil,
+	)
+	d.instanceTypeCount = c.newDesc(
+		prometheus.BuildFQName(namespace, "cluster", "distinct_instance_types"),
+		"number of distinct instance types across the nodes of the cluster",
+		nil,
+		nil,
+	)
+	d.pricedNodeRatio = c.newDesc(
+		prometheus.BuildFQName(namespace, "", "priced_node_ratio"),
+		"fraction of the nodes in the cluster with a known price, leaving out the nodes excluded from pricing and "+
+			"in their grace period like the unknown price count, not emitted without such nodes",
+		nil,
+		nil,
+	)
+	d.dataAge = c.newDesc(
+		prometheus.BuildFQName(namespace, "collector", "data_age_seconds"),
+		"age of the node data the metrics were computed from, non-zero if the cluster could not be listed",
+		nil,
+		nil,
+	)
+}
+
+// newGroupDescs builds the descs of the prices of pods and of groups of nodes and pods.
+func (c *Collector) newGroupDescs(namespace string) {
+	podLabels := []string{"namespace", "pod", "node"}
+	if c.workloadLabels {
+		podLabels = append(podLabels, "workload_kind", "workload")
+	}
+	d := &c.metricDesc
+	d.podHourlyPrice = c.newDesc(
+		prometheus.BuildFQName(namespace, "pod", "hourly_price"+c.priceUnitSuffix),
+		"share of the hourly price of the node attributed to the pod by its dominant resource request",
+		podLabels,
+		nil,
+	)
+	d.namespacePrice = c.newDesc(
+		prometheus.BuildFQName(namespace, "namespace", "hourly_price"+c.priceUnitSuffix),
+		"sum of the hourly prices attributed to the pods in the namespace",
+		[]string{"namespace"},
+		nil,
+	)
+	d.billablePrice = c.newDesc(
+		prometheus.BuildFQName(namespace, "cluster", "billable_hourly_price"+c.priceUnitSuffix),
+		"sum of the hourly prices attributed to pods outside of the excluded namespaces",
+		nil,
+		nil,
+	)
+	d.familyPricePerVCPU = c.newDesc(
+		prometheus.BuildFQName(namespace, "instance_family", "hourly_price_per_vcpu"+c.priceUnitSuffix),
+		"average hourly price per vCPU of the nodes of the instance family, excluding Fargate",
+		[]string{"instance_family"},
+		nil,
+	)
+	d.nodePoolSpotPrice = c.newDesc(
+		prometheus.BuildFQName(namespace, "nodepool", "spot_hourly_price"+c.priceUnitSuffix),
+		"average hourly price of the spot nodes of the Karpenter NodePool with a known price, weighted by the "+
+			"number of nodes of each instance type",
+		[]string{"nodepool"},
+		nil,
+	)
+	d.nodePoolPrice = c.newDesc(
+		prometheus.BuildFQName(namespace, "nodepool", "hourly_price"+c.priceUnitSuffix),
+		"hourly price of the nodes of the Karpenter NodePool with a known price",
+		[]string{"nodepool"},
+		nil,
+	)
+}
+
+// newPricingDescs builds the descs of the metrics of the pricing data.
+func (c *Collector) newPricingDescs(namespace string) {
+	d := &c.metricDesc
+	d.updateErrors = c.newDesc(
+		prometheus.BuildFQName(namespace, "pricing", "update_errors_total"),
+		"number of failed pricing updates",
+		nil,
+		nil,
+	)
+	d.spotPriceMin = c.newDesc(
+		prometheus.BuildFQName(namespace, "instance_type", "spot_price_min"+c.priceUnitSuffix),
+		"lowest hourly spot price of the instance type across all zones in the region",
+		[]string{"instance_type"},
+		nil,
+	)
+	d.spotPriceAvg = c.newDesc(
+		prometheus.BuildFQName(namespace, "instance_type", "spot_price_avg"+c.priceUnitSuffix),
+		"average hourly spot price of the instance type across all zones in the region",
+		[]string{"instance_type"},
+		nil,
+	)
+	d.pricingSource = c.newDesc(
+		prometheus.BuildFQName(namespace, "pricing", "source"),
+		"info metric with the source of the pricing data currently in use for each pricing type",
+		[]string{"pricing_type", "source"},
+		nil,
+	)
+	d.pricingStaleness = c.newDesc(
+		prometheus.BuildFQName(namespace, "pricing", "staleness_seconds"),
+		"seconds since each pricing type was last updated at the time of the scrape",
+		[]string{"pricing_type"},
+		nil,
+	)
+	d.effectiveDate = c.newDesc(
+		prometheus.BuildFQName(namespace, "ondemand_price", "effective_date_seconds"),
+		"unix time the on-demand price of the instance type took effect according to the AWS price list",
+		[]string{"instance_type"},
+		nil,
+	)
+	d.spotZonesKnown = c.newDesc(
+		prometheus.BuildFQName(namespace, "spot_price", "zones_known"),
+		"number of zones in the region with a known spot price for the instance type",
+		[]string{"instance_type"},
+		nil,
+	)
+	d.priceDrift = c.newDesc(
+		prometheus.BuildFQName(namespace, "price", "drift_ratio"),
+		"ratio of the on-demand price in use to the live on-demand price of the instance type",
+		[]string{"instance_type"},
+		nil,
+	)
+}
+
+// newOptionalDescs builds the descs of the metrics which are only emitted if enabled by an option.
+func (c *Collector) newOptionalDescs(namespace string) {
+	if c.spotPriceHistogram {
+		histogramOpts := prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "spot",
+			Name:      "price_distribution" + c.priceUnitSuffix,
+			Help: "distribution of the hourly prices of spot nodes, observing the price of every spot node on every " +
+				"collection",
+			Buckets:                         []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+			NativeHistogramBucketFactor:     1.1,
+			NativeHistogramMaxBucketNumber:  160,
+			NativeHistogramMinResetDuration: time.Hour,
+		}
+		histogramOpts.Help = c.metricHelp(
+			prometheus.BuildFQName(histogramOpts.Namespace, histogramOpts.Subsystem, histogramOpts.Name),
+			histogramOpts.Help,
+		)
+		c.spotPriceDistribution = prometheus.NewHistogram(histogramOpts)
+		c.metricDesc.spotPriceDistribution = c.spotPriceDistribution.Desc()
+	}
+	if len(c.debugInstanceTypes) != 0 {
+		c.metricDesc.debugPrice = c.newDesc(
+			prometheus.BuildFQName(namespace, "debug", "price"),
+			"hourly price resolved for the instance type, capacity type and zone, NaN if unknown",
+			[]string{"instance_type", "capacity_type", "zone", "reason", "source"},
+			nil,
+		)
+	}
+	if c.monthlyPrices {
+		c.metricDesc.monthlyPrice = c.newDesc(
+			prometheus.BuildFQName(namespace, "node", "monthly_price"+c.priceUnitSuffix),
+			"monthly price of node, the hourly price multiplied by 730 hours",
+			append(nodeLabels, "price_source"),
+			nil,
+		)
+		c.metricDesc.clusterMonthlyPrice = c.newDesc(
+			prometheus.BuildFQName(namespace, "cluster", "monthly_price"+c.priceUnitSuffix),
+			"monthly price of all nodes with a known price, the hourly price multiplied by 730 hours",
+			nil,
+			nil,
+		)
+	}
+	if c.smoothedSpot {
+		c.metricDesc.smoothedSpotPrice = c.newDesc(
+			prometheus.BuildFQName(namespace, "node", "spot_price_smoothed"+c.priceUnitSuffix),
+			"moving average of the hourly spot price of the instance type in the zone of the spot node across refreshes",
+			nodeLabels,
+			nil,
+		)
+	}
+	if c.fargateAccumulated {
+		c.metricDesc.fargateAccumulatedCost = c.newDesc(
+			prometheus.BuildFQName(namespace, "fargate", "pod_accumulated_cost"+c.priceUnitSuffix+"_total"),
+			"cost of the Fargate pod billed from its status StartTime, its hourly price prorated by its runtime with a "+
+				"one minute minimum",
+			[]string{"namespace", "pod", "node"},
+			nil,
+		)
+	}
 }
 
 func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
-	ch <- c.metricDesc.hourlyPrice
-	ch <- c.metricDesc.nodeInfo
+	c.describePricing(ch)
+	c.describeNodes(ch)
+}
+
+// describeNodes describes the metrics of the nodes, pods and cluster, see NodeMetrics.
+func (c *Collector) describeNodes(ch chan<- *prometheus.Desc) {
+	for _, desc := range c.metricDesc.perNode() {
+		ch <- desc
+	}
+	ch <- c.metricDesc.clusterHourlyPrice
+	ch <- c.metricDesc.capacityTypePrice
+	ch <- c.metricDesc.nodePriceMin
+	ch <- c.metricDesc.nodePriceMax
+	ch <- c.metricDesc.nodePriceMinByType
+	ch <- c.metricDesc.nodePriceMaxByType
+	ch <- c.metricDesc.emptyHourlyPrice
+	ch <- c.metricDesc.priceArchMismatch
+	ch <- c.metricDesc.podHourlyPrice
+	ch <- c.metricDesc.namespacePrice
+	ch <- c.metricDesc.billablePrice
+	ch <- c.metricDesc.systemOverhead
+	ch <- c.metricDesc.nodeCount
+	ch <- c.metricDesc.instanceTypeCount
+	ch <- c.metricDesc.priceUnknownCount
+	ch <- c.metricDesc.pricedNodeRatio
+	ch <- c.metricDesc.familyPricePerVCPU
+	ch <- c.metricDesc.nodePoolSpotPrice
+	ch <- c.metricDesc.nodePoolPrice
+	ch <- c.metricDesc.dataAge
+	if c.spotPriceHistogram {
+		ch <- c.metricDesc.spotPriceDistribution
+	}
+	if c.monthlyPrices {
+		ch <- c.metricDesc.clusterMonthlyPrice
+	}
+}
+
+// describePricing describes the metrics of the pricing data, see PricingMetrics.
+func (c *Collector) describePricing(ch chan<- *prometheus.Desc) {
+	ch <- c.metricDesc.controlPlanePrice
+	ch <- c.metricDesc.updateErrors
+	ch <- c.metricDesc.priceDrift
+	ch <- c.metricDesc.pricingSource
+	ch <- c.metricDesc.pricingStaleness
+	ch <- c.metricDesc.spotPriceMin
+	ch <- c.metricDesc.spotPriceAvg
+	ch <- c.metricDesc.spotZonesKnown
+	ch <- c.metricDesc.effectiveDate
+	if len(c.debugInstanceTypes) != 0 {
+		ch <- c.metricDesc.debugPrice
+	}
 }
 
 func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	pr := c.pricingRepository.Snapshot()
+	c.collectPricing(ch, pr)
+	c.collectNodes(ch, pr)
+}
+
+// collectorView is a prometheus.Collector for part of the metrics of a Collector.
+type collectorView struct {
+	describe func(chan<- *prometheus.Desc)
+	collect  func(chan<- prometheus.Metric)
+}
+
+func (v collectorView) Describe(ch chan<- *prometheus.Desc) {
+	v.describe(ch)
+}
+
+func (v collectorView) Collect(ch chan<- prometheus.Metric) {
+	v.collect(ch)
+}
+
+// NodeMetrics returns a prometheus.Collector for only the node, pod and cluster metrics of the Collector, to register
+// instead of the Collector where the metrics of the pricing data aren't wanted.
+func (c *Collector) NodeMetrics() prometheus.Collector {
+	return collectorView{
+		describe: c.describeNodes,
+		collect: func(ch chan<- prometheus.Metric) {
+			c.collectNodes(ch, c.pricingRepository.Snapshot())
+		},
+	}
+}
+
+// PricingMetrics returns a prometheus.Collector for only the metrics of the pricing data of the Collector, e.g. its
+// staleness and the spot price aggregates, to register instead of the Collector where the node metrics aren't wanted.
+// Collecting them doesn't list the nodes of the cluster.
+func (c *Collector) PricingMetrics() prometheus.Collector {
+	return collectorView{
+		describe: c.describePricing,
+		collect: func(ch chan<- prometheus.Metric) {
+			c.collectPricing(ch, c.pricingRepository.Snapshot())
+		},
+	}
+}
+
+// collectPricing collects the metrics of the pricing data of pr, a snapshot of the pricing repository, which don't
+// depend on the nodes in the cluster.
+func (c *Collector) collectPricing(ch chan<- prometheus.Metric, pr *pricing.Repository) {
+	ch <- prometheus.MustNewConstMetric(
+		c.metricDesc.updateErrors,
+		prometheus.CounterValue,
+		float64(pr.UpdateErrors()),
+	)
+	for pricingType, source := range pr.PricingSources() {
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.pricingSource,
+			prometheus.GaugeValue,
+			1,
+			string(pricingType), // "pricing_type"
+			source,              // "source"
+		)
+	}
+	for _, pricingType := range pricing.PricingTypes {
+		lastUpdated := pr.LastUpdated(pricingType)
+		if lastUpdated.IsZero() {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.pricingStaleness,
+			prometheus.GaugeValue,
+			time.Since(lastUpdated).Seconds(),
+			string(pricingType), // "pricing_type"
+		)
+	}
+	c.collectInstanceTypePricing(ch, pr)
+	c.collectDebugPrices(ch, pr)
+	if price, ok := pr.ControlPlanePrice(); ok {
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.controlPlanePrice,
+			prometheus.GaugeValue,
+			c.roundPrice(price),
+		)
+	}
+}
+
+// collectNodes collects the metrics of the nodes, pods and cluster, pricing every node with pr, a snapshot of the
+// pricing repository.
+func (c *Collector) collectNodes(ch chan<- prometheus.Metric, pr *pricing.Repository) {
 	ctx, cancel := context.WithTimeout(c.parentCtx, 5*time.Minute)
 	defer cancel()
 
-	cluster := model.NewCluster()
-	err := cluster.Populate(ctx, c.cs)
+	nodes, populated, err := c.populatePriced(ctx, pr)
 	if err != nil {
-		log.Fatalf("getting cluster information failed: %s", err)
+		log.Printf("getting cluster information failed: %s", err)
+		if populated.IsZero() {
+			return
+		}
+		log.Printf("emitting metrics for the nodes last seen at %s", populated.Format(time.RFC3339))
+	}
+	ch <- prometheus.MustNewConstMetric(
+		c.metricDesc.dataAge,
+		prometheus.GaugeValue,
+		time.Since(populated).Seconds(),
+	)
+
+	totals := c.sumNodes(nodes)
+	c.collectClusterCounts(ch, totals)
+	c.collectClusterPrices(ch, totals)
+	c.collectNamespacePrices(ch, nodes)
+	c.collectFamilyPricePerVCPU(ch, nodes, pr)
+	c.collectNodePoolSpotPrices(ch, nodes)
+	c.collectNodePoolPrices(ch, nodes)
+	if c.spotPriceHistogram {
+		c.collectSpotPriceDistribution(ch, nodes)
+	}
+
+	if series := len(nodes) * len(c.metricDesc.perNode()); c.maxSeries > 0 && series > c.maxSeries {
+		log.Printf(
+			"per-node metrics would produce %d series which exceeds the limit of %d, only emitting aggregates",
+			series,
+			c.maxSeries,
+		)
+		return
+	}
+
+	c.forEachNode(nodes, func(node *model.Node) {
+		c.collectNode(ch, node, pr)
+	})
+}
+
+// clusterTotals are the node counts and prices of the cluster.
+type clusterTotals struct {
+	totalPrice              float64
+	emptyPrice              float64
+	nodeCounts              map[model.NodeCapacityType]int
+	capacityTypePrices      map[model.NodeCapacityType]float64
+	nodePriceRange          *priceRange
+	capacityTypePriceRanges map[model.NodeCapacityType]*priceRange
+	priceUnknownCount       int
+	// the nodes which are priced and out of their grace period, and how many of them have a price
+	ratioNodes    int
+	pricedNodes   int
+	instanceTypes map[string]bool
+}
+
+// sumNodes sums up the node counts and prices of the cluster.
+func (c *Collector) sumNodes(nodes []*model.Node) clusterTotals {
+	totals := clusterTotals{
+		nodeCounts:              map[model.NodeCapacityType]int{},
+		capacityTypePrices:      map[model.NodeCapacityType]float64{},
+		capacityTypePriceRanges: map[model.NodeCapacityType]*priceRange{},
+		instanceTypes:           map[string]bool{},
+	}
+	for _, node := range nodes {
+		if node.HasPrice() && c.priced(node) {
+			totals.totalPrice += node.Price
+			totals.capacityTypePrices[node.CapacityType()] += node.Price
+			totals.nodePriceRange = totals.nodePriceRange.add(node.Price)
+			totals.capacityTypePriceRanges[node.CapacityType()] =
+				totals.capacityTypePriceRanges[node.CapacityType()].add(node.Price)
+			if node.IsEmpty() {
+				totals.emptyPrice += node.Price
+			}
+		}
+		if c.priced(node) && !c.inGracePeriod(node) {
+			totals.ratioNodes++
+			if node.HasPrice() {
+				totals.pricedNodes++
+			} else {
+				totals.priceUnknownCount++
+			}
+		}
+		totals.nodeCounts[node.CapacityType()]++
+		if node.InstanceType() != "" && (!c.excludeFargateType || !node.IsFargate()) {
+			totals.instanceTypes[node.InstanceType()] = true
+		}
+	}
+	return totals
+}
+
+// collectClusterCounts collects the node counts of the cluster.
+func (c *Collector) collectClusterCounts(ch chan<- prometheus.Metric, totals clusterTotals) {
+	for capacityType, count := range totals.nodeCounts {
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.nodeCount,
+			prometheus.GaugeValue,
+			float64(count),
+			capacityType.String(), // "capacity_type"
+		)
+	}
+	ch <- prometheus.MustNewConstMetric(
+		c.metricDesc.instanceTypeCount,
+		prometheus.GaugeValue,
+		float64(len(totals.instanceTypes)),
+	)
+	ch <- prometheus.MustNewConstMetric(
+		c.metricDesc.priceUnknownCount,
+		prometheus.GaugeValue,
+		float64(totals.priceUnknownCount),
+	)
+	if totals.ratioNodes != 0 {
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.pricedNodeRatio,
+			prometheus.GaugeValue,
+			float64(totals.pricedNodes)/float64(totals.ratioNodes),
+		)
+	}
+}
+
+// collectClusterPrices collects the prices of the cluster.
+func (c *Collector) collectClusterPrices(ch chan<- prometheus.Metric, totals clusterTotals) {
+	ch <- prometheus.MustNewConstMetric(
+		c.metricDesc.clusterHourlyPrice,
+		prometheus.GaugeValue,
+		c.roundPrice(totals.totalPrice),
+	)
+	for capacityType, price := range totals.capacityTypePrices {
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.capacityTypePrice,
+			prometheus.GaugeValue,
+			c.roundPrice(price),
+			capacityType.String(), // "capacity_type"
+		)
+	}
+	if totals.nodePriceRange != nil {
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.nodePriceMin,
+			prometheus.GaugeValue,
+			c.roundPrice(totals.nodePriceRange.min),
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.nodePriceMax,
+			prometheus.GaugeValue,
+			c.roundPrice(totals.nodePriceRange.max),
+		)
+	}
+	for capacityType, prices := range totals.capacityTypePriceRanges {
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.nodePriceMinByType,
+			prometheus.GaugeValue,
+			c.roundPrice(prices.min),
+			capacityType.String(), // "capacity_type"
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.nodePriceMaxByType,
+			prometheus.GaugeValue,
+			c.roundPrice(prices.max),
+			capacityType.String(), // "capacity_type"
+		)
 	}
+	ch <- prometheus.MustNewConstMetric(
+		c.metricDesc.emptyHourlyPrice,
+		prometheus.GaugeValue,
+		c.roundPrice(totals.emptyPrice),
+	)
+	if c.monthlyPrices {
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.clusterMonthlyPrice,
+			prometheus.GaugeValue,
+			c.roundPrice(totals.totalPrice*hoursPerMonth),
+		)
+	}
+}
+
+// priceRange is the lowest and highest price of a group of nodes.
+type priceRange struct {
+	min, max float64
+}
+
+// add returns the range widened to include price, starting a new range if r is nil.
+func (r *priceRange) add(price float64) *priceRange {
+	if r == nil {
+		return &priceRange{min: price, max: price}
+	}
+	r.min = math.Min(r.min, price)
+	r.max = math.Max(r.max, price)
+	return r
+}
+
+// priceChangeRatio returns the ratio of the price of the node to its price before the last refresh of the on-demand or
+// spot pricing it is priced at. It returns false for nodes priced otherwise, with a license included price, or without
+// a non-zero previous price, e.g. as their pricing has been refreshed at most once.
+func priceChangeRatio(node *model.Node, pr *pricing.Repository) (float64, bool) {
+	if node.LicenseModel().IncludesLicense() {
+		return 0, false
+	}
+	var previous float64
+	var ok bool
+	switch node.PriceSource {
+	case model.NodePriceSourceOnDemand:
+		previous, ok = pr.PreviousOnDemandPrice(node.InstanceType())
+	case model.NodePriceSourceSpot:
+		previous, ok = pr.PreviousSpotPrice(node.InstanceType(), node.Zone())
+	}
+	if !ok || previous == 0 {
+		return 0, false
+	}
+	return node.Price / previous, true
+}
+
+// populatePriced populates the cluster like populate, looks up the instance types of nodes missing them if enabled and
+// prices every node against pr.
+func (c *Collector) populatePriced(ctx context.Context, pr *pricing.Repository) ([]*model.Node, time.Time, error) {
+	nodes, populated, err := c.populate(ctx)
+	if c.instanceLookup != nil {
+		c.lookupInstances(ctx, nodes)
+	}
+	c.forEachNode(nodes, func(node *model.Node) {
+		node.UpdatePrice(pr)
+	})
+	return nodes, populated, err
+}
+
+// populate lists the nodes in the cluster along with when they were listed. If listing fails, the nodes from the last
+// successful listing are returned along with the error, or a zero time if there has never been one.
+func (c *Collector) populate(ctx context.Context) ([]*model.Node, time.Time, error) {
+	var clusterOpts []model.ClusterOption
+	if c.podBinding != "" {
+		clusterOpts = append(clusterOpts, model.WithPodBindingStrategy(c.podBinding))
+	}
+	if len(c.nodeOpts) != 0 {
+		clusterOpts = append(clusterOpts, model.WithNodeOptions(c.nodeOpts...))
+	}
+	cluster := model.NewCluster(clusterOpts...)
+	err := cluster.Populate(ctx, c.cs)
 
+	// the snapshot is only ever copied, as every collection prices and looks up the instances of the nodes it gets
+	c.snapshotMu.Lock()
+	defer c.snapshotMu.Unlock()
+	if err != nil {
+		return copyNodes(c.snapshotNodes), c.snapshotPopulated, err
+	}
+	var nodes []*model.Node
 	cluster.ForEachNode(func(node *model.Node) {
-		node.UpdatePrice(c.pricingRepository)
+		nodes = append(nodes, node)
+	})
+	c.snapshotNodes = copyNodes(nodes)
+	c.snapshotPopulated = time.Now()
+	return nodes, c.snapshotPopulated, nil
+}
+
+// copyNodes returns copies of nodes.
+func copyNodes(nodes []*model.Node) []*model.Node {
+	copies := make([]*model.Node, 0, len(nodes))
+	for _, node := range nodes {
+		copies = append(copies, node.Copy())
+	}
+	return copies
+}
+
+// forEachNode calls fn for every node using a bounded pool of workers, returning once all calls have finished.
+func (c *Collector) forEachNode(nodes []*model.Node, fn func(node *model.Node)) {
+	workers := c.workers
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(nodes) {
+		workers = len(nodes)
+	}
+	queue := make(chan *model.Node)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for node := range queue {
+				fn(node)
+			}
+		}()
+	}
+	for _, node := range nodes {
+		queue <- node
+	}
+	close(queue)
+	wg.Wait()
+}
+
+// collectSpotPriceDistribution observes the price of every priced spot node into the spot price histogram, which keeps
+// accumulating across collections like any other histogram so that its rate over time is the distribution of spot
+// prices over that time.
+func (c *Collector) collectSpotPriceDistribution(ch chan<- prometheus.Metric, nodes []*model.Node) {
+	for _, node := range nodes {
+		if node.IsSpot() && node.HasPrice() && c.priced(node) {
+			c.spotPriceDistribution.Observe(node.Price)
+		}
+	}
+	c.spotPriceDistribution.Collect(ch)
+}
+
+// lookupInstances fills in the instance type and zone of nodes missing the labels for them.
+func (c *Collector) lookupInstances(ctx context.Context, nodes []*model.Node) {
+	missing := map[string]*model.Node{}
+	for _, node := range nodes {
+		if node.IsFargate() || node.IsHybrid() || node.InstanceType() != "" || node.InstanceID() == "" {
+			continue
+		}
+		missing[node.InstanceID()] = node
+	}
+	// forget the instances of nodes that are gone or have since been labeled
+	c.instanceLookup.Retain(lo.Keys(missing))
+	if len(missing) == 0 {
+		return
+	}
+	instances, err := c.instanceLookup.Lookup(ctx, lo.Keys(missing))
+	if err != nil {
+		log.Printf("could not look up instances for nodes missing instance type labels: %s", err)
+	}
+	for instanceID, info := range instances {
+		missing[instanceID].SetInstanceInfo(info.InstanceType, info.Zone)
+	}
+}
+
+// priced returns true if the node should be included in the price metrics and totals.
+func (c *Collector) priced(node *model.Node) bool {
+	if c.excludeCordoned && node.Cordoned() {
+		return false
+	}
+	// the instance types of fargate nodes are synthetic and hybrid nodes aren't EC2 instances, so neither are filtered
+	if node.IsFargate() || node.IsHybrid() {
+		return true
+	}
+	instanceType := node.InstanceType()
+	if len(c.instanceTypeAllowlist) != 0 && !matchesAny(c.instanceTypeAllowlist, instanceType) {
+		return false
+	}
+	return !matchesAny(c.instanceTypeDenylist, instanceType)
+}
+
+// matchesAny returns true if name matches any of the path.Match patterns. Malformed patterns never match.
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// inGracePeriod returns true if the node is younger than the configured grace period.
+// emitsPrice returns whether the price metrics of the node are emitted, i.e. it is priced and isn't reported as
+// unpriced while still in its grace period.
+func (c *Collector) emitsPrice(node *model.Node) bool {
+	if !c.priced(node) {
+		return false
+	}
+	// a node in its grace period is likely still missing labels, so don't report it as unpriced yet
+	return node.HasPrice() || !c.inGracePeriod(node)
+}
+
+func (c *Collector) inGracePeriod(node *model.Node) bool {
+	return c.nodeGracePeriod > 0 && time.Since(node.Created()) < c.nodeGracePeriod
+}
+
+func (c *Collector) collectNode(ch chan<- prometheus.Metric, node *model.Node, pr *pricing.Repository) {
+	labelValues := nodeLabelValues(node)
 
+	ch <- prometheus.MustNewConstMetric(
+		c.metricDesc.nodeInfo,
+		prometheus.GaugeValue,
+		1.0,
+		append(labelValues, node.OSImage(), node.OSDistribution().String(), node.InstanceFamily())...,
+	)
+	ch <- prometheus.MustNewConstMetric(
+		c.metricDesc.nodeReady,
+		prometheus.GaugeValue,
+		boolToFloat(node.Ready()),
+		node.Name(),         // "node"
+		node.InstanceType(), // "instance_type"
+	)
+	ch <- prometheus.MustNewConstMetric(
+		c.metricDesc.nodeCordoned,
+		prometheus.GaugeValue,
+		boolToFloat(node.Cordoned()),
+		node.Name(),         // "node"
+		node.InstanceType(), // "instance_type"
+	)
+	ch <- prometheus.MustNewConstMetric(
+		c.metricDesc.nodeEmpty,
+		prometheus.GaugeValue,
+		boolToFloat(node.IsEmpty()),
+		node.Name(),         // "node"
+		node.InstanceType(), // "instance_type"
+	)
+	c.collectHardwareInfo(ch, node, pr)
+	c.collectGPUs(ch, node, pr)
+	c.collectTaints(ch, node)
+
+	if !c.emitsPrice(node) {
+		return
+	}
+	c.collectNodePrices(ch, node, pr)
+	c.collectPodPrices(ch, node)
+}
+
+// collectHardwareInfo collects the hardware info of the instance type of the node.
+func (c *Collector) collectHardwareInfo(ch chan<- prometheus.Metric, node *model.Node, pr *pricing.Repository) {
+	// fargate nodes have synthetic instance types which aren't real EC2 instance types
+	if spec, ok := pr.InstanceSpec(node.InstanceType()); ok && !node.IsFargate() {
+		ebsBandwidth := ""
+		if spec.EBSBandwidthMbps != 0 {
+			ebsBandwidth = strconv.Itoa(int(spec.EBSBandwidthMbps))
+		}
+		instanceStorage := strconv.FormatInt(spec.InstanceStorageGB, 10)
 		ch <- prometheus.MustNewConstMetric(
-			c.metricDesc.nodeInfo,
+			c.metricDesc.nodeHardwareInfo,
 			prometheus.GaugeValue,
 			1.0,
-			node.Name(),                  // "node"
-			node.CapacityType().String(), // "capacity_type"
-			node.InstanceType(),          // "instance_type"
-			node.Zone(),                  // "zone"
-			node.Region(),                // "region"
-			node.Status().String(),       // "status"
+			node.Name(),             // "node"
+			node.InstanceType(),     // "instance_type"
+			spec.NetworkPerformance, // "network_performance"
+			ebsBandwidth,            // "ebs_bandwidth"
+			instanceStorage,         // "instance_storage_gb"
 		)
+	}
+}
+
+// collectTaints collects the taints of the node.
+func (c *Collector) collectTaints(ch chan<- prometheus.Metric, node *model.Node) {
+	taints := node.Taints()
+	ch <- prometheus.MustNewConstMetric(
+		c.metricDesc.nodeTaintCount,
+		prometheus.GaugeValue,
+		float64(len(taints)),
+		node.Name(),         // "node"
+		node.InstanceType(), // "instance_type"
+	)
+	for _, taint := range taints {
 		ch <- prometheus.MustNewConstMetric(
-			c.metricDesc.hourlyPrice,
+			c.metricDesc.nodeTainted,
 			prometheus.GaugeValue,
-			node.Price,
-			node.Name(),                  // "node"
-			node.CapacityType().String(), // "capacity_type"
-			node.InstanceType(),          // "instance_type"
-			node.Zone(),                  // "zone"
-			node.Region(),                // "region"
-			node.Status().String(),       // "status"
+			1.0,
+			node.Name(),          // "node"
+			taint.Key,            // "key"
+			string(taint.Effect), // "effect"
 		)
-	})
+	}
+}
+
+// collectNodePrices collects the prices of the node.
+func (c *Collector) collectNodePrices(ch chan<- prometheus.Metric, node *model.Node, pr *pricing.Repository) {
+	labelValues := nodeLabelValues(node)
+	ch <- prometheus.MustNewConstMetric(
+		c.metricDesc.hourlyPrice,
+		prometheus.GaugeValue,
+		c.roundPrice(node.Price),
+		append(labelValues, node.PriceSource.String())...,
+	)
+	if c.monthlyPrices {
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.monthlyPrice,
+			prometheus.GaugeValue,
+			c.roundPrice(node.Price*hoursPerMonth),
+			append(labelValues, node.PriceSource.String())...,
+		)
+	}
+	if node.PriceSource == model.NodePriceSourceOnDemand {
+		c.collectPriceArchMismatch(ch, node, pr)
+	}
+	if ratio, ok := priceChangeRatio(node, pr); ok {
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.priceChangeRatio,
+			prometheus.GaugeValue,
+			ratio,
+			labelValues...,
+		)
+	}
+	if c.smoothedSpot && node.IsSpot() {
+		if price, ok := pr.SmoothedSpotPrice(node.InstanceType(), node.Zone()); ok {
+			ch <- prometheus.MustNewConstMetric(
+				c.metricDesc.smoothedSpotPrice,
+				prometheus.GaugeValue,
+				c.roundPrice(price),
+				labelValues...,
+			)
+		}
+	}
+	if c.fargateAccumulated {
+		c.collectFargateAccumulatedCost(ch, node)
+	}
+	if pricePerVCPU, ok := node.HourlyPricePerVCPU(pr); ok {
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.hourlyPricePerVCPU,
+			prometheus.GaugeValue,
+			c.roundPrice(pricePerVCPU),
+			labelValues...,
+		)
+	}
+	if pricePerGB, ok := node.HourlyPricePerGBMemory(pr); ok {
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.hourlyPricePerGB,
+			prometheus.GaugeValue,
+			c.roundPrice(pricePerGB),
+			labelValues...,
+		)
+	}
+	if pricePerGPU, ok := node.HourlyPricePerGPU(pr); ok {
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.hourlyPricePerGPU,
+			prometheus.GaugeValue,
+			c.roundPrice(pricePerGPU),
+			labelValues...,
+		)
+	}
+	if pricePerPod, ok := node.HourlyPricePerWorkloadPod(); ok {
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.hourlyPricePerPod,
+			prometheus.GaugeValue,
+			c.roundPrice(pricePerPod),
+			labelValues...,
+		)
+	}
+}
+
+// collectPodPrices collects the prices attributed to the pods of the node and its system overhead.
+func (c *Collector) collectPodPrices(ch chan<- prometheus.Metric, node *model.Node) {
+	podPrices, overhead := c.podPrices(node)
+	for _, podPrice := range podPrices {
+		podLabelValues := []string{
+			podPrice.Pod.Namespace(), // "namespace"
+			podPrice.Pod.Name(),      // "pod"
+			node.Name(),              // "node"
+		}
+		if c.workloadLabels {
+			kind, name := podPrice.Pod.Workload()
+			podLabelValues = append(podLabelValues, kind, name) // "workload_kind", "workload"
+		}
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.podHourlyPrice,
+			prometheus.GaugeValue,
+			c.roundPrice(podPrice.HourlyPrice),
+			podLabelValues...,
+		)
+	}
+	if c.podAttribution.SystemOverhead == model.SystemOverheadSeparate && node.HasPrice() && !node.IsFargate() {
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.systemOverhead,
+			prometheus.GaugeValue,
+			c.roundPrice(overhead),
+			node.Name(), // "node"
+		)
+	}
+}
+
+// podPrices returns the prices attributed to the pods of the node which are not in an excluded namespace, along with
+// the system overhead of the node.
+func (c *Collector) podPrices(node *model.Node) ([]model.PodPrice, float64) {
+	podPrices, overhead := node.AttributePodPrices(c.podAttribution)
+	if len(c.excludeNamespaces) == 0 {
+		return podPrices, overhead
+	}
+	included := podPrices[:0]
+	for _, podPrice := range podPrices {
+		if !c.excludeNamespaces[podPrice.Pod.Namespace()] {
+			included = append(included, podPrice)
+		}
+	}
+	return included, overhead
+}
+
+// collectNamespacePrices emits the sum of the pod prices of each namespace and of all namespaces which aren't
+// excluded.
+func (c *Collector) collectNamespacePrices(ch chan<- prometheus.Metric, nodes []*model.Node) {
+	namespacePrices := map[string]float64{}
+	billablePrice := 0.0
+	for _, node := range nodes {
+		if !c.priced(node) {
+			continue
+		}
+		podPrices, _ := c.podPrices(node)
+		for _, podPrice := range podPrices {
+			namespacePrices[podPrice.Pod.Namespace()] += podPrice.HourlyPrice
+			billablePrice += podPrice.HourlyPrice
+		}
+	}
+	for namespace, price := range namespacePrices {
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.namespacePrice,
+			prometheus.GaugeValue,
+			c.roundPrice(price),
+			namespace, // "namespace"
+		)
+	}
+	ch <- prometheus.MustNewConstMetric(
+		c.metricDesc.billablePrice,
+		prometheus.GaugeValue,
+		c.roundPrice(billablePrice),
+	)
+}
+
+// collectGPUs emits the physical and advertised GPUs of nodes with either, which differ if the GPUs are shared with
+// time-slicing or partitioned with MIG.
+func (c *Collector) collectGPUs(ch chan<- prometheus.Metric, node *model.Node, pr *pricing.Repository) {
+	physical, ok := node.PhysicalGPUs(pr)
+	if ok && physical != 0 {
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.physicalGPUs,
+			prometheus.GaugeValue,
+			float64(physical),
+			node.Name(),         // "node"
+			node.InstanceType(), // "instance_type"
+		)
+	}
+	if advertised := node.AdvertisedGPUs(); advertised != 0 {
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.advertisedGPUs,
+			prometheus.GaugeValue,
+			float64(advertised),
+			node.Name(),         // "node"
+			node.InstanceType(), // "instance_type"
+		)
+	}
+}
+
+// collectFargateAccumulatedCost emits the cost of the pod of a Fargate node since it started.
+func (c *Collector) collectFargateAccumulatedCost(ch chan<- prometheus.Metric, node *model.Node) {
+	cost, pod, ok := node.FargateAccumulatedCost(time.Now())
+	if !ok {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(
+		c.metricDesc.fargateAccumulatedCost,
+		prometheus.CounterValue,
+		c.roundPrice(cost),
+		pod.Namespace(), // "namespace"
+		pod.Name(),      // "pod"
+		node.Name(),     // "node"
+	)
+}
+
+// collectPriceArchMismatch flags the node if it is priced as an instance type which doesn't support its architecture.
+func (c *Collector) collectPriceArchMismatch(ch chan<- prometheus.Metric, node *model.Node, pr *pricing.Repository) {
+	arch := node.Architecture()
+	spec, ok := pr.InstanceSpec(node.InstanceType())
+	if arch == "" || !ok || spec.SupportsArchitecture(arch) {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(
+		c.metricDesc.priceArchMismatch,
+		prometheus.GaugeValue,
+		1.0,
+		node.Name(),                           // "node"
+		node.InstanceType(),                   // "instance_type"
+		arch,                                  // "node_arch"
+		strings.Join(spec.Architectures, ","), // "price_arch"
+	)
+}
+
+// collectFamilyPricePerVCPU emits the average hourly price per vCPU of the priced nodes of each instance family.
+// Fargate nodes are left out by HourlyPricePerVCPU since they aren't EC2 instances.
+func (c *Collector) collectFamilyPricePerVCPU(
+	ch chan<- prometheus.Metric,
+	nodes []*model.Node,
+	pr *pricing.Repository,
+) {
+	sums := map[string]float64{}
+	counts := map[string]int{}
+	for _, node := range nodes {
+		if !c.priced(node) {
+			continue
+		}
+		pricePerVCPU, ok := node.HourlyPricePerVCPU(pr)
+		if !ok {
+			continue
+		}
+		family := node.InstanceFamily()
+		sums[family] += pricePerVCPU
+		counts[family]++
+	}
+	for family, sum := range sums {
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.familyPricePerVCPU,
+			prometheus.GaugeValue,
+			c.roundPrice(sum/float64(counts[family])),
+			family, // "instance_family"
+		)
+	}
+}
+
+// collectNodePoolPrices emits the sum of the prices of the priced nodes of each Karpenter NodePool.
+func (c *Collector) collectNodePoolPrices(ch chan<- prometheus.Metric, nodes []*model.Node) {
+	sums := map[string]float64{}
+	for _, node := range nodes {
+		nodePool := node.NodePool()
+		if nodePool == "" || !node.HasPrice() || !c.priced(node) {
+			continue
+		}
+		sums[nodePool] += node.Price
+	}
+	for nodePool, sum := range sums {
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.nodePoolPrice,
+			prometheus.GaugeValue,
+			c.roundPrice(sum),
+			nodePool, // "nodepool"
+		)
+	}
+}
+
+// collectNodePoolSpotPrices emits the average price of the priced spot nodes of each Karpenter NodePool. Averaging
+// over nodes rather than instance types weights the price of each instance type by how many of its nodes are running.
+func (c *Collector) collectNodePoolSpotPrices(ch chan<- prometheus.Metric, nodes []*model.Node) {
+	sums := map[string]float64{}
+	counts := map[string]int{}
+	for _, node := range nodes {
+		nodePool := node.NodePool()
+		if nodePool == "" || !node.IsSpot() || !node.HasPrice() || !c.priced(node) {
+			continue
+		}
+		sums[nodePool] += node.Price
+		counts[nodePool]++
+	}
+	for nodePool, sum := range sums {
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.nodePoolSpotPrice,
+			prometheus.GaugeValue,
+			c.roundPrice(sum/float64(counts[nodePool])),
+			nodePool, // "nodepool"
+		)
+	}
+}
+
+// collectInstanceTypePricing emits the pricing metrics with one series per instance type, unless there would be more
+// of them than the instance type series limit.
+func (c *Collector) collectInstanceTypePricing(ch chan<- prometheus.Metric, pr *pricing.Repository) {
+	effectiveDates := pr.OnDemandEffectiveDates()
+	drifts := pr.PriceDrift()
+	spotPrices := pr.SpotPrices()
+	series := len(effectiveDates) + len(drifts)
+	for _, zones := range spotPrices {
+		if len(zones) != 0 {
+			// the lowest and average spot price and the number of zones known
+			series += 3
+		}
+	}
+	if c.maxInstanceTypeSeries > 0 && series > c.maxInstanceTypeSeries {
+		log.Printf(
+			"per-instance type pricing metrics would produce %d series which exceeds the limit of %d, not emitting them",
+			series,
+			c.maxInstanceTypeSeries,
+		)
+		return
+	}
+
+	for instanceType, effectiveDate := range effectiveDates {
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.effectiveDate,
+			prometheus.GaugeValue,
+			float64(effectiveDate.Unix()),
+			instanceType, // "instance_type"
+		)
+	}
+	for instanceType, drift := range drifts {
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.priceDrift,
+			prometheus.GaugeValue,
+			drift,
+			instanceType, // "instance_type"
+		)
+	}
+	c.collectSpotPriceAggregates(ch, spotPrices)
+}
+
+// collectSpotPriceAggregates emits the lowest and average spot price of every instance type across the zones of the
+// region.
+func (c *Collector) collectSpotPriceAggregates(ch chan<- prometheus.Metric, spotPrices pricing.SpotPriceList) {
+	for instanceType, zones := range spotPrices {
+		if len(zones) == 0 {
+			continue
+		}
+		lowest := math.Inf(1)
+		sum := 0.0
+		for _, price := range zones {
+			lowest = math.Min(lowest, price)
+			sum += price
+		}
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.spotPriceMin,
+			prometheus.GaugeValue,
+			c.roundPrice(lowest),
+			instanceType, // "instance_type"
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.spotPriceAvg,
+			prometheus.GaugeValue,
+			c.roundPrice(sum/float64(len(zones))),
+			instanceType, // "instance_type"
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.metricDesc.spotZonesKnown,
+			prometheus.GaugeValue,
+			float64(len(zones)),
+			instanceType, // "instance_type"
+		)
+	}
+}
+
+// collectDebugPrices emits the resolved on-demand and capacity block prices of each debug instance type, and its spot
+// price in every zone with a known spot price, or in no zone if there is none.
+func (c *Collector) collectDebugPrices(ch chan<- prometheus.Metric, pr *pricing.Repository) {
+	if len(c.debugInstanceTypes) == 0 {
+		return
+	}
+	spotPrices := pr.SpotPrices()
+	sources := pr.PricingSources()
+	for _, instanceType := range c.debugInstanceTypes {
+		requests := []pricing.LookupRequest{
+			{InstanceType: instanceType, CapacityType: pricing.CapacityTypeOnDemand},
+			{InstanceType: instanceType, CapacityType: pricing.CapacityTypeCapacityBlock},
+		}
+		zones := lo.Keys(spotPrices[instanceType])
+		if len(zones) == 0 {
+			zones = []string{""}
+		}
+		for _, zone := range zones {
+			requests = append(requests, pricing.LookupRequest{
+				InstanceType: instanceType,
+				CapacityType: pricing.CapacityTypeSpot,
+				Zone:         zone,
+			})
+		}
+		for _, req := range requests {
+			result := pr.Lookup(req)
+			price := math.NaN()
+			if result.Price != nil {
+				price = *result.Price
+			}
+			// the capacity types are named like the pricing types they are priced from
+			source, ok := sources[pricing.PricingType(req.CapacityType)]
+			if !ok {
+				source = pricing.PricingSourceUnknown
+			}
+			ch <- prometheus.MustNewConstMetric(
+				c.metricDesc.debugPrice,
+				prometheus.GaugeValue,
+				c.roundPrice(price),
+				req.InstanceType, // "instance_type"
+				req.CapacityType, // "capacity_type"
+				req.Zone,         // "zone"
+				result.Reason,    // "reason"
+				source,           // "source"
+			)
+		}
+	}
+}
+
+// roundPrice rounds price to the configured precision, if any.
+func (c *Collector) roundPrice(price float64) float64 {
+	if c.pricePrecision <= 0 {
+		return price
+	}
+	scale := math.Pow(10, float64(c.pricePrecision))
+	return math.Round(price*scale) / scale
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
 }
@@ -0,0 +1,1555 @@
+package collector_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/smithy-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/sapslaj/eks-pricing-exporter/pkg/collector"
+	"github.com/sapslaj/eks-pricing-exporter/pkg/model"
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing"
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing/pricingtest"
+)
+
+func testRepository(t *testing.T) *pricing.Repository {
+	t.Helper()
+	pr := pricing.NewRepository(&pricingtest.Provider{
+		OnDemand: pricing.OnDemandPriceList{
+			"m5.large":  0.125,
+			"m5.xlarge": 0.25,
+		},
+		Spot: pricing.SpotPriceList{
+			"m5.large": {"us-east-1a": 0.035},
+		},
+		Fargate: pricing.FargatePrice{
+			VCPUPerHour: 0.04048,
+			GBPerHour:   0.004445,
+		},
+		InstanceSpecs: pricing.InstanceSpecList{
+			"m5.large":  {VCPUs: 2, MemoryMiB: 8192},
+			"m5.xlarge": {VCPUs: 4, MemoryMiB: 16384},
+		},
+		ControlPlane: 0.1,
+	})
+	if err := pr.UpdatePricing(context.Background()); err != nil {
+		t.Fatalf("unexpected error updating repository: %s", err)
+	}
+	return pr
+}
+
+func testNode(name string, capacityType string, instanceType string) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				"karpenter.sh/capacity-type": capacityType,
+				v1.LabelInstanceTypeStable:   instanceType,
+				v1.LabelTopologyZone:         "us-east-1a",
+				v1.LabelTopologyRegion:       "us-east-1",
+			},
+		},
+	}
+}
+
+func TestCollectorMaxSeries(t *testing.T) {
+	var objects []runtime.Object
+	for i := 0; i < 10; i++ {
+		objects = append(objects, testNode(fmt.Sprintf("node-%d", i), "on-demand", "m5.large"))
+	}
+	cs := fake.NewSimpleClientset(objects...)
+	pr := testRepository(t)
+
+	c := collector.NewCollector(context.Background(), cs, pr)
+	if exp, got := 10, testutil.CollectAndCount(c, "eks_node_hourly_price"); exp != got {
+		t.Errorf("expected %d eks_node_hourly_price series without a limit, got %d", exp, got)
+	}
+
+	c = collector.NewCollector(context.Background(), cs, pr, collector.WithMaxSeries(10))
+	if exp, got := 0, testutil.CollectAndCount(c, "eks_node_hourly_price"); exp != got {
+		t.Errorf("expected %d eks_node_hourly_price series over the limit, got %d", exp, got)
+	}
+	if exp, got := 0, testutil.CollectAndCount(c, "eks_node_info"); exp != got {
+		t.Errorf("expected %d eks_node_info series over the limit, got %d", exp, got)
+	}
+	if exp, got := 1, testutil.CollectAndCount(c, "eks_cluster_hourly_price"); exp != got {
+		t.Errorf("expected %d eks_cluster_hourly_price series over the limit, got %d", exp, got)
+	}
+}
+
+func TestCollectorExcludeCordoned(t *testing.T) {
+	cordoned := testNode("cordoned", "on-demand", "m5.xlarge")
+	cordoned.Spec.Unschedulable = true
+	cs := fake.NewSimpleClientset(testNode("ready", "on-demand", "m5.large"), cordoned)
+	pr := testRepository(t)
+
+	c := collector.NewCollector(context.Background(), cs, pr)
+	if exp, got := 2, testutil.CollectAndCount(c, "eks_node_hourly_price"); exp != got {
+		t.Errorf("expected %d eks_node_hourly_price series, got %d", exp, got)
+	}
+	expected := `
+# HELP eks_cluster_hourly_price hourly price of all nodes with a known price
+# TYPE eks_cluster_hourly_price gauge
+eks_cluster_hourly_price 0.375
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected), "eks_cluster_hourly_price"); err != nil {
+		t.Error(err)
+	}
+
+	c = collector.NewCollector(context.Background(), cs, pr, collector.WithExcludeCordoned(true))
+	if exp, got := 1, testutil.CollectAndCount(c, "eks_node_hourly_price"); exp != got {
+		t.Errorf("expected %d eks_node_hourly_price series, got %d", exp, got)
+	}
+	if exp, got := 2, testutil.CollectAndCount(c, "eks_node_info"); exp != got {
+		t.Errorf("expected %d eks_node_info series, got %d", exp, got)
+	}
+	expected = `
+# HELP eks_cluster_hourly_price hourly price of all nodes with a known price
+# TYPE eks_cluster_hourly_price gauge
+eks_cluster_hourly_price 0.125
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected), "eks_cluster_hourly_price"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollectorInstanceTypeFilters(t *testing.T) {
+	cs := fake.NewSimpleClientset(
+		testNode("large", "on-demand", "m5.large"),
+		testNode("xlarge", "on-demand", "m5.xlarge"),
+		testNode("unpriced", "on-demand", "m7i.large"),
+	)
+	for name, tc := range map[string]struct {
+		opts     []collector.Option
+		expected string
+	}{
+		"denylist": {
+			opts: []collector.Option{collector.WithInstanceTypeDenylist([]string{"m7i.*"})},
+			expected: `
+# HELP eks_cluster_hourly_price hourly price of all nodes with a known price
+# TYPE eks_cluster_hourly_price gauge
+eks_cluster_hourly_price 0.375
+# HELP eks_node_hourly_price hourly price of node
+# TYPE eks_node_hourly_price gauge
+eks_node_hourly_price{capacity_type="on-demand",instance_type="m5.large",node="large",price_source="on-demand",region="us-east-1",status="Unknown",zone="us-east-1a"} 0.125
+eks_node_hourly_price{capacity_type="on-demand",instance_type="m5.xlarge",node="xlarge",price_source="on-demand",region="us-east-1",status="Unknown",zone="us-east-1a"} 0.25
+# HELP eks_node_price_unknown_count number of nodes whose price could not be determined
+# TYPE eks_node_price_unknown_count gauge
+eks_node_price_unknown_count 0
+`,
+		},
+		"allowlist with denylist": {
+			opts: []collector.Option{
+				collector.WithInstanceTypeAllowlist([]string{"m5.*"}),
+				collector.WithInstanceTypeDenylist([]string{"m5.xlarge"}),
+			},
+			expected: `
+# HELP eks_cluster_hourly_price hourly price of all nodes with a known price
+# TYPE eks_cluster_hourly_price gauge
+eks_cluster_hourly_price 0.125
+# HELP eks_node_hourly_price hourly price of node
+# TYPE eks_node_hourly_price gauge
+eks_node_hourly_price{capacity_type="on-demand",instance_type="m5.large",node="large",price_source="on-demand",region="us-east-1",status="Unknown",zone="us-east-1a"} 0.125
+# HELP eks_node_price_unknown_count number of nodes whose price could not be determined
+# TYPE eks_node_price_unknown_count gauge
+eks_node_price_unknown_count 0
+`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := collector.NewCollector(context.Background(), cs, testRepository(t), tc.opts...)
+			err := testutil.CollectAndCompare(
+				c,
+				strings.NewReader(tc.expected),
+				"eks_cluster_hourly_price",
+				"eks_node_hourly_price",
+				"eks_node_price_unknown_count",
+			)
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestCollectorUnitSuffixesOpenMetrics(t *testing.T) {
+	cs := fake.NewSimpleClientset(testNode("node", "on-demand", "m5.large"))
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector.NewCollector(
+		context.Background(),
+		cs,
+		testRepository(t),
+		collector.WithUnitSuffixes(true),
+	))
+	handler := promhttp.HandlerFor(registry, promhttp.HandlerOpts{EnableOpenMetrics: true})
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Accept", "application/openmetrics-text; version=0.0.1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if contentType := rec.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "application/openmetrics-text") {
+		t.Fatalf("expected OpenMetrics content type, got %s", contentType)
+	}
+	body := rec.Body.String()
+	for _, line := range []string{
+		"# TYPE eks_node_hourly_price_usd gauge",
+		"# TYPE eks_node_hourly_price_per_vcpu_usd gauge",
+		"# TYPE eks_cluster_hourly_price_usd gauge",
+		"# TYPE eks_pricing_update_errors counter",
+		"# EOF",
+	} {
+		if !strings.Contains(body, line) {
+			t.Errorf("expected scrape to contain %q, got:\n%s", line, body)
+		}
+	}
+	if strings.Contains(body, "eks_node_hourly_price{") {
+		t.Errorf("expected unsuffixed eks_node_hourly_price to be absent, got:\n%s", body)
+	}
+}
+
+func TestCollectorUnitSuffixesCurrency(t *testing.T) {
+	cs := fake.NewSimpleClientset(testNode("node", "on-demand", "m5.large"))
+	c := collector.NewCollector(
+		context.Background(),
+		cs,
+		testRepository(t),
+		collector.WithUnitSuffixes(true),
+		collector.WithPriceCurrency(pricing.RegionCurrency("cn-north-1")),
+	)
+	if count := testutil.CollectAndCount(c, "eks_node_hourly_price_cny"); count != 1 {
+		t.Errorf("expected eks_node_hourly_price_cny in the China regions, got %d series", count)
+	}
+	if count := testutil.CollectAndCount(c, "eks_node_hourly_price_usd"); count != 0 {
+		t.Errorf("expected no eks_node_hourly_price_usd in the China regions, got %d series", count)
+	}
+}
+
+func TestCollectorNodeCount(t *testing.T) {
+	fargate := testNode("fargate", "", "")
+	fargate.Labels = map[string]string{"eks.amazonaws.com/compute-type": "fargate"}
+	unknown := testNode("unknown", "", "m5.large")
+	delete(unknown.Labels, "karpenter.sh/capacity-type")
+	cs := fake.NewSimpleClientset(
+		testNode("spot-1", "spot", "m5.large"),
+		testNode("spot-2", "spot", "m5.large"),
+		testNode("spot-3", "spot", "m5.xlarge"),
+		testNode("on-demand-1", "on-demand", "m5.large"),
+		testNode("on-demand-2", "on-demand", "m5.xlarge"),
+		fargate,
+		unknown,
+	)
+	c := collector.NewCollector(context.Background(), cs, testRepository(t))
+
+	expected := `
+# HELP eks_node_count number of nodes by capacity type
+# TYPE eks_node_count gauge
+eks_node_count{capacity_type=""} 1
+eks_node_count{capacity_type="fargate"} 1
+eks_node_count{capacity_type="on-demand"} 2
+eks_node_count{capacity_type="spot"} 3
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected), "eks_node_count"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollectorPricedNodeRatio(t *testing.T) {
+	cs := fake.NewSimpleClientset(
+		testNode("large", "on-demand", "m5.large"),
+		testNode("xlarge", "on-demand", "m5.xlarge"),
+		testNode("spot", "spot", "m5.large"),
+		// there is no price for m7i.large
+		testNode("unpriced", "on-demand", "m7i.large"),
+	)
+	c := collector.NewCollector(context.Background(), cs, testRepository(t))
+
+	if exp, got := 0.75, gatherValue(t, c, "eks_priced_node_ratio"); exp != got {
+		t.Errorf("expected priced node ratio %v, got %v", exp, got)
+	}
+
+	// unpriced nodes left out of pricing or in their grace period don't count
+	young := testNode("young", "on-demand", "m7i.xlarge")
+	young.CreationTimestamp = metav1.NewTime(time.Now())
+	cs = fake.NewSimpleClientset(
+		testNode("large", "on-demand", "m5.large"),
+		testNode("denied", "on-demand", "m7i.large"),
+		young,
+	)
+	c = collector.NewCollector(
+		context.Background(),
+		cs,
+		testRepository(t),
+		collector.WithInstanceTypeDenylist([]string{"m7i.large"}),
+		collector.WithNodeGracePeriod(time.Minute),
+	)
+	if exp, got := 1.0, gatherValue(t, c, "eks_priced_node_ratio"); exp != got {
+		t.Errorf("expected priced node ratio %v, got %v", exp, got)
+	}
+}
+
+func TestCollectorDistinctInstanceTypes(t *testing.T) {
+	fargate := testNode("fargate", "", "")
+	fargate.Labels = map[string]string{"eks.amazonaws.com/compute-type": "fargate"}
+	cs := fake.NewSimpleClientset(
+		testNode("large-1", "on-demand", "m5.large"),
+		testNode("large-2", "spot", "m5.large"),
+		testNode("xlarge", "on-demand", "m5.xlarge"),
+		fargate,
+	)
+
+	for name, tc := range map[string]struct {
+		excludeFargate bool
+		expected       float64
+	}{
+		"fargate included": {expected: 3},
+		"fargate excluded": {excludeFargate: true, expected: 2},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := collector.NewCollector(
+				context.Background(),
+				cs,
+				testRepository(t),
+				collector.WithExcludeFargateInstanceTypes(tc.excludeFargate),
+			)
+			if got := gatherValue(t, c, "eks_cluster_distinct_instance_types"); got != tc.expected {
+				t.Errorf("expected %v distinct instance types, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestCollectorNodePoolSpotPrice(t *testing.T) {
+	pr := pricing.NewRepository(&pricingtest.Provider{
+		OnDemand: pricing.OnDemandPriceList{"m5.large": 1},
+		Spot: pricing.SpotPriceList{
+			"m5.large":  {"us-east-1a": 0.25},
+			"m5.xlarge": {"us-east-1a": 0.5},
+		},
+	})
+	if err := pr.UpdatePricing(context.Background()); err != nil {
+		t.Fatalf("unexpected error updating repository: %s", err)
+	}
+	poolNode := func(name, nodePool, capacityType, instanceType string) *v1.Node {
+		node := testNode(name, capacityType, instanceType)
+		node.Labels["karpenter.sh/nodepool"] = nodePool
+		return node
+	}
+	cs := fake.NewSimpleClientset(
+		poolNode("general-1", "general", "spot", "m5.large"),
+		poolNode("general-2", "general", "spot", "m5.large"),
+		poolNode("general-3", "general", "spot", "m5.large"),
+		poolNode("general-4", "general", "spot", "m5.xlarge"),
+		// on-demand nodes of the pool aren't included
+		poolNode("general-5", "general", "on-demand", "m5.large"),
+		poolNode("batch-1", "batch", "spot", "m5.xlarge"),
+		testNode("unpooled", "spot", "m5.large"),
+	)
+	c := collector.NewCollector(context.Background(), cs, pr)
+
+	// (3 * 0.25 + 0.5) / 4
+	expected := `
+# HELP eks_nodepool_spot_hourly_price average hourly price of the spot nodes of the Karpenter NodePool with a known price, weighted by the number of nodes of each instance type
+# TYPE eks_nodepool_spot_hourly_price gauge
+eks_nodepool_spot_hourly_price{nodepool="batch"} 0.5
+eks_nodepool_spot_hourly_price{nodepool="general"} 0.3125
+`
+	err := testutil.CollectAndCompare(c, strings.NewReader(expected), "eks_nodepool_spot_hourly_price")
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollectorNodePoolPrice(t *testing.T) {
+	pr := pricing.NewRepository(&pricingtest.Provider{
+		OnDemand: pricing.OnDemandPriceList{"m5.large": 1, "m5.xlarge": 2},
+		Spot: pricing.SpotPriceList{
+			"m5.large": {"us-east-1a": 0.25},
+		},
+	})
+	if err := pr.UpdatePricing(context.Background()); err != nil {
+		t.Fatalf("unexpected error updating repository: %s", err)
+	}
+	poolNode := func(name, label, nodePool, capacityType, instanceType string) *v1.Node {
+		node := testNode(name, capacityType, instanceType)
+		node.Labels[label] = nodePool
+		return node
+	}
+	cs := fake.NewSimpleClientset(
+		poolNode("general-1", "karpenter.sh/nodepool", "general", "on-demand", "m5.large"),
+		poolNode("general-2", "karpenter.sh/nodepool", "general", "on-demand", "m5.xlarge"),
+		poolNode("general-3", "karpenter.sh/nodepool", "general", "spot", "m5.large"),
+		// nodes with an unknown price aren't included
+		poolNode("general-4", "karpenter.sh/nodepool", "general", "on-demand", "m5.2xlarge"),
+		// nodes of older Karpenter versions are grouped by their Provisioner
+		poolNode("legacy-1", "karpenter.sh/provisioner-name", "legacy", "on-demand", "m5.large"),
+		poolNode("legacy-2", "karpenter.sh/provisioner-name", "legacy", "spot", "m5.large"),
+		testNode("unpooled", "on-demand", "m5.large"),
+	)
+	c := collector.NewCollector(context.Background(), cs, pr)
+
+	// 1 + 2 + 0.25 and 1 + 0.25
+	expected := `
+# HELP eks_nodepool_hourly_price hourly price of the nodes of the Karpenter NodePool with a known price
+# TYPE eks_nodepool_hourly_price gauge
+eks_nodepool_hourly_price{nodepool="general"} 3.25
+eks_nodepool_hourly_price{nodepool="legacy"} 1.25
+`
+	err := testutil.CollectAndCompare(c, strings.NewReader(expected), "eks_nodepool_hourly_price")
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollectorPriceChangeRatio(t *testing.T) {
+	provider := &pricingtest.Provider{
+		Spot: pricing.SpotPriceList{"m5.large": {"us-east-1a": 0.25}},
+	}
+	pr := pricing.NewRepository(provider)
+	if err := pr.UpdatePricing(context.Background()); err != nil {
+		t.Fatalf("unexpected error updating repository: %s", err)
+	}
+	cs := fake.NewSimpleClientset(testNode("spot", "spot", "m5.large"))
+	c := collector.NewCollector(context.Background(), cs, pr)
+
+	// there is no previous price until pricing has been refreshed twice
+	if count := testutil.CollectAndCount(c, "eks_node_price_change_ratio"); count != 0 {
+		t.Errorf("expected no eks_node_price_change_ratio after the first refresh, got %d", count)
+	}
+
+	provider.Spot = pricing.SpotPriceList{"m5.large": {"us-east-1a": 0.5}}
+	if err := pr.UpdatePricing(context.Background()); err != nil {
+		t.Fatalf("unexpected error updating repository: %s", err)
+	}
+	expected := `
+# HELP eks_node_price_change_ratio hourly price of node divided by its on-demand or spot price before the last refresh of that pricing, not emitted for nodes without such a previous price
+# TYPE eks_node_price_change_ratio gauge
+eks_node_price_change_ratio{capacity_type="spot",instance_type="m5.large",node="spot",region="us-east-1",status="Unknown",zone="us-east-1a"} 2
+`
+	// every scrape sees the change of the last refresh, however many there are in between
+	for scrape := 0; scrape < 2; scrape++ {
+		err := testutil.CollectAndCompare(c, strings.NewReader(expected), "eks_node_price_change_ratio")
+		if err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestCollectorNodePriceRange(t *testing.T) {
+	cs := fake.NewSimpleClientset(
+		testNode("large", "on-demand", "m5.large"),
+		testNode("xlarge", "on-demand", "m5.xlarge"),
+		testNode("spot", "spot", "m5.large"),
+		// nodes with an unknown price are left out
+		testNode("unknown", "on-demand", "m5.24xlarge"),
+	)
+	c := collector.NewCollector(context.Background(), cs, testRepository(t))
+	expected := `
+# HELP eks_cluster_node_price_max highest hourly price of the nodes with a known price
+# TYPE eks_cluster_node_price_max gauge
+eks_cluster_node_price_max 0.25
+# HELP eks_cluster_node_price_max_by_capacity_type highest hourly price of the nodes with a known price by capacity type
+# TYPE eks_cluster_node_price_max_by_capacity_type gauge
+eks_cluster_node_price_max_by_capacity_type{capacity_type="on-demand"} 0.25
+eks_cluster_node_price_max_by_capacity_type{capacity_type="spot"} 0.035
+# HELP eks_cluster_node_price_min lowest hourly price of the nodes with a known price
+# TYPE eks_cluster_node_price_min gauge
+eks_cluster_node_price_min 0.035
+# HELP eks_cluster_node_price_min_by_capacity_type lowest hourly price of the nodes with a known price by capacity type
+# TYPE eks_cluster_node_price_min_by_capacity_type gauge
+eks_cluster_node_price_min_by_capacity_type{capacity_type="on-demand"} 0.125
+eks_cluster_node_price_min_by_capacity_type{capacity_type="spot"} 0.035
+`
+	err := testutil.CollectAndCompare(
+		c,
+		strings.NewReader(expected),
+		"eks_cluster_node_price_min",
+		"eks_cluster_node_price_max",
+		"eks_cluster_node_price_min_by_capacity_type",
+		"eks_cluster_node_price_max_by_capacity_type",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollectorMetricOverrides(t *testing.T) {
+	pr := pricing.NewRepository(&pricingtest.Provider{
+		Spot: pricing.SpotPriceList{"m5.large": {"us-east-1a": 0.25}},
+	})
+	if err := pr.UpdatePricing(context.Background()); err != nil {
+		t.Fatalf("unexpected error updating repository: %s", err)
+	}
+	cs := fake.NewSimpleClientset(testNode("spot", "spot", "m5.large"))
+	c := collector.NewCollector(context.Background(), cs, pr, collector.WithMetricOverrides(collector.MetricOverrides{
+		Help:   map[string]string{"eks_node_hourly_price": "hourly price of the EC2 instance"},
+		Labels: map[string]string{"zone": "availability_zone"},
+	}))
+	expected := `
+# HELP eks_node_hourly_price hourly price of the EC2 instance
+# TYPE eks_node_hourly_price gauge
+eks_node_hourly_price{availability_zone="us-east-1a",capacity_type="spot",instance_type="m5.large",node="spot",price_source="spot",region="us-east-1",status="Unknown"} 0.25
+`
+	err := testutil.CollectAndCompare(c, strings.NewReader(expected), "eks_node_hourly_price")
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMetricOverridesValidate(t *testing.T) {
+	for name, tc := range map[string]struct {
+		help   map[string]string
+		labels map[string]string
+		expErr bool
+	}{
+		"valid": {
+			help:   map[string]string{"eks_node_hourly_price": "price"},
+			labels: map[string]string{"zone": "availability_zone"},
+		},
+		"invalid label name":  {labels: map[string]string{"zone": "availability-zone"}, expErr: true},
+		"reserved label name": {labels: map[string]string{"zone": "__zone"}, expErr: true},
+		"empty label name":    {labels: map[string]string{"zone": ""}, expErr: true},
+		"same label name":     {labels: map[string]string{"zone": "location", "region": "location"}, expErr: true},
+		"invalid metric name": {help: map[string]string{"eks-node": "price"}, expErr: true},
+	} {
+		err := collector.MetricOverrides{Help: tc.help, Labels: tc.labels}.Validate()
+		if (err != nil) != tc.expErr {
+			t.Errorf("%s: expected error %v, got %v", name, tc.expErr, err)
+		}
+	}
+}
+
+func TestCollectorInstanceFamilyExcludesFargate(t *testing.T) {
+	pr := pricing.NewRepository(&pricingtest.Provider{
+		OnDemand: pricing.OnDemandPriceList{"m5.large": 0.125, "m5.xlarge": 0.25},
+		Fargate:  pricing.FargatePrice{VCPUPerHour: 0.5, GBPerHour: 0.25},
+		InstanceSpecs: pricing.InstanceSpecList{
+			"m5.large":  {VCPUs: 2, MemoryMiB: 8192},
+			"m5.xlarge": {VCPUs: 4, MemoryMiB: 16384},
+			// a spec matching the synthetic fargate instance type must not be used
+			"0.25vCPU-0.5GB": {VCPUs: 1, MemoryMiB: 512},
+		},
+	})
+	if err := pr.UpdatePricing(context.Background()); err != nil {
+		t.Fatalf("unexpected error updating repository: %s", err)
+	}
+	fargate := testNode("fargate", "", "")
+	fargate.Labels = map[string]string{"eks.amazonaws.com/compute-type": "fargate"}
+	fargatePod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "app",
+			Annotations: map[string]string{"CapacityProvisioned": "0.25vCPU 0.5GB"},
+		},
+		Spec:   v1.PodSpec{NodeName: "fargate"},
+		Status: v1.PodStatus{Phase: v1.PodRunning},
+	}
+	cs := fake.NewSimpleClientset(
+		testNode("large", "on-demand", "m5.large"),
+		testNode("xlarge", "on-demand", "m5.xlarge"),
+		fargate,
+		fargatePod,
+	)
+	c := collector.NewCollector(context.Background(), cs, pr)
+
+	expected := `
+# HELP eks_instance_family_hourly_price_per_vcpu average hourly price per vCPU of the nodes of the instance family, excluding Fargate
+# TYPE eks_instance_family_hourly_price_per_vcpu gauge
+eks_instance_family_hourly_price_per_vcpu{instance_family="m5"} 0.0625
+`
+	err := testutil.CollectAndCompare(c, strings.NewReader(expected), "eks_instance_family_hourly_price_per_vcpu")
+	if err != nil {
+		t.Error(err)
+	}
+	if exp, got := 2, testutil.CollectAndCount(c, "eks_node_hourly_price_per_vcpu"); exp != got {
+		t.Errorf("expected %d eks_node_hourly_price_per_vcpu series, got %d", exp, got)
+	}
+}
+
+func TestCollectorFargateAccumulatedCost(t *testing.T) {
+	fargate := testNode("fargate", "", "")
+	fargate.Labels = map[string]string{"eks.amazonaws.com/compute-type": "fargate"}
+	fargatePod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "app",
+			Annotations: map[string]string{"CapacityProvisioned": "1vCPU 2GB"},
+		},
+		Spec: v1.PodSpec{NodeName: "fargate"},
+		Status: v1.PodStatus{
+			Phase:     v1.PodRunning,
+			StartTime: &metav1.Time{Time: time.Now().Add(-2 * time.Hour)},
+		},
+	}
+	cs := fake.NewSimpleClientset(fargate, fargatePod)
+	c := collector.NewCollector(
+		context.Background(),
+		cs,
+		testRepository(t),
+		collector.WithFargateAccumulatedCost(true),
+		collector.WithPricePrecision(4),
+	)
+
+	// 2 hours of 1 vCPU and 2 GB
+	expected := `
+# HELP eks_fargate_pod_accumulated_cost_total cost of the Fargate pod billed from its status StartTime, its hourly price prorated by its runtime with a one minute minimum
+# TYPE eks_fargate_pod_accumulated_cost_total counter
+eks_fargate_pod_accumulated_cost_total{namespace="default",node="fargate",pod="app"} 0.0987
+`
+	err := testutil.CollectAndCompare(c, strings.NewReader(expected), "eks_fargate_pod_accumulated_cost_total")
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollectorSpotPriceHistogram(t *testing.T) {
+	cs := fake.NewSimpleClientset(
+		testNode("spot-1", "spot", "m5.large"),
+		testNode("spot-2", "spot", "m5.large"),
+		testNode("on-demand-1", "on-demand", "m5.large"),
+	)
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector.NewCollector(
+		context.Background(),
+		cs,
+		testRepository(t),
+		collector.WithSpotPriceHistogram(true),
+	))
+
+	// the histogram accumulates across scrapes, observing both spot nodes on each
+	for scrape, exp := range []uint64{2, 4} {
+		families, err := registry.Gather()
+		if err != nil {
+			t.Fatalf("unexpected error gathering metrics: %s", err)
+		}
+		var found bool
+		for _, family := range families {
+			if family.GetName() != "eks_spot_price_distribution" {
+				continue
+			}
+			found = true
+			histogram := family.GetMetric()[0].GetHistogram()
+			if got := histogram.GetSampleCount(); exp != got {
+				t.Errorf("expected %d observations after scrape %d, got %d", exp, scrape+1, got)
+			}
+			if exp, got := 0.035*float64(exp), histogram.GetSampleSum(); math.Abs(exp-got) > 1e-9 {
+				t.Errorf("expected sum of observations == %f after scrape %d, got %f", exp, scrape+1, got)
+			}
+			if histogram.Schema == nil {
+				t.Errorf("expected a native histogram schema to be set")
+			}
+		}
+		if !found {
+			t.Errorf("expected eks_spot_price_distribution to be emitted")
+		}
+	}
+
+	c := collector.NewCollector(context.Background(), cs, testRepository(t))
+	if count := testutil.CollectAndCount(c, "eks_spot_price_distribution"); count != 0 {
+		t.Errorf("expected no spot price distribution by default, got %d series", count)
+	}
+}
+
+func TestCollectorNodeGracePeriod(t *testing.T) {
+	young := testNode("young", "on-demand", "")
+	young.CreationTimestamp = metav1.NewTime(time.Now().Add(-10 * time.Second))
+	old := testNode("old", "on-demand", "")
+	old.CreationTimestamp = metav1.NewTime(time.Now().Add(-10 * time.Minute))
+	cs := fake.NewSimpleClientset(young, old)
+	c := collector.NewCollector(
+		context.Background(),
+		cs,
+		testRepository(t),
+		collector.WithNodeGracePeriod(60*time.Second),
+	)
+
+	expected := `
+# HELP eks_node_count number of nodes by capacity type
+# TYPE eks_node_count gauge
+eks_node_count{capacity_type="on-demand"} 2
+# HELP eks_node_price_unknown_count number of nodes whose price could not be determined
+# TYPE eks_node_price_unknown_count gauge
+eks_node_price_unknown_count 1
+`
+	err := testutil.CollectAndCompare(
+		c,
+		strings.NewReader(expected),
+		"eks_node_count",
+		"eks_node_price_unknown_count",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(c)
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error gathering metrics: %s", err)
+	}
+	for _, family := range families {
+		if family.GetName() != "eks_node_hourly_price" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "node" && label.GetValue() == "young" {
+					t.Errorf("expected no hourly price for a node within the grace period")
+				}
+			}
+		}
+	}
+}
+
+func TestCollectorNodeReadyAndCordoned(t *testing.T) {
+	ready := testNode("ready", "on-demand", "m5.large")
+	ready.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}
+	notReady := testNode("not-ready", "on-demand", "m5.large")
+	notReady.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionFalse}}
+	cordoned := testNode("cordoned", "spot", "m5.xlarge")
+	cordoned.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}
+	cordoned.Spec.Unschedulable = true
+	cs := fake.NewSimpleClientset(ready, notReady, cordoned)
+	c := collector.NewCollector(context.Background(), cs, testRepository(t))
+
+	expected := `
+# HELP eks_node_cordoned 1 if the node is cordoned, 0 otherwise
+# TYPE eks_node_cordoned gauge
+eks_node_cordoned{instance_type="m5.large",node="not-ready"} 0
+eks_node_cordoned{instance_type="m5.large",node="ready"} 0
+eks_node_cordoned{instance_type="m5.xlarge",node="cordoned"} 1
+# HELP eks_node_ready 1 if the node is ready, 0 otherwise
+# TYPE eks_node_ready gauge
+eks_node_ready{instance_type="m5.large",node="not-ready"} 0
+eks_node_ready{instance_type="m5.large",node="ready"} 1
+eks_node_ready{instance_type="m5.xlarge",node="cordoned"} 1
+`
+	err := testutil.CollectAndCompare(c, strings.NewReader(expected), "eks_node_ready", "eks_node_cordoned")
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollectorNodeTainted(t *testing.T) {
+	tainted := testNode("tainted", "on-demand", "m5.large")
+	tainted.Spec.Taints = []v1.Taint{
+		{Key: "nvidia.com/gpu", Value: "true", Effect: v1.TaintEffectNoSchedule},
+		{Key: "dedicated", Value: "batch", Effect: v1.TaintEffectNoExecute},
+	}
+	untainted := testNode("untainted", "on-demand", "m5.large")
+	cs := fake.NewSimpleClientset(tainted, untainted)
+	c := collector.NewCollector(context.Background(), cs, testRepository(t))
+
+	expected := `
+# HELP eks_node_taint_count number of taints on the node
+# TYPE eks_node_taint_count gauge
+eks_node_taint_count{instance_type="m5.large",node="tainted"} 2
+eks_node_taint_count{instance_type="m5.large",node="untainted"} 0
+# HELP eks_node_tainted info labels for each taint on the node
+# TYPE eks_node_tainted gauge
+eks_node_tainted{effect="NoExecute",key="dedicated",node="tainted"} 1
+eks_node_tainted{effect="NoSchedule",key="nvidia.com/gpu",node="tainted"} 1
+`
+	err := testutil.CollectAndCompare(c, strings.NewReader(expected), "eks_node_taint_count", "eks_node_tainted")
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollectorEmptyNodes(t *testing.T) {
+	daemonSetPod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "monitoring",
+			Name:            "node-exporter",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet", Name: "node-exporter"}},
+		},
+		Spec:   v1.PodSpec{NodeName: "empty"},
+		Status: v1.PodStatus{Phase: v1.PodRunning},
+	}
+	workloadPod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app"},
+		Spec:       v1.PodSpec{NodeName: "busy"},
+		Status:     v1.PodStatus{Phase: v1.PodRunning},
+	}
+	cs := fake.NewSimpleClientset(
+		testNode("empty", "on-demand", "m5.xlarge"),
+		testNode("busy", "on-demand", "m5.large"),
+		daemonSetPod,
+		workloadPod,
+	)
+	c := collector.NewCollector(context.Background(), cs, testRepository(t))
+
+	expected := `
+# HELP eks_cluster_empty_node_hourly_price hourly price of all nodes with a known price which are only running DaemonSet and kube-system pods
+# TYPE eks_cluster_empty_node_hourly_price gauge
+eks_cluster_empty_node_hourly_price 0.25
+# HELP eks_node_empty 1 if the node is only running DaemonSet and kube-system pods, 0 otherwise
+# TYPE eks_node_empty gauge
+eks_node_empty{instance_type="m5.large",node="busy"} 0
+eks_node_empty{instance_type="m5.xlarge",node="empty"} 1
+`
+	err := testutil.CollectAndCompare(
+		c,
+		strings.NewReader(expected),
+		"eks_node_empty",
+		"eks_cluster_empty_node_hourly_price",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollectorHourlyPriceByCapacityType(t *testing.T) {
+	cs := fake.NewSimpleClientset(
+		testNode("on-demand-small", "on-demand", "m5.large"),
+		testNode("on-demand-large", "on-demand", "m5.xlarge"),
+		testNode("spot-small", "spot", "m5.large"),
+		// there is no spot price for m5.xlarge, so this node is left out
+		testNode("spot-large", "spot", "m5.xlarge"),
+	)
+	c := collector.NewCollector(context.Background(), cs, testRepository(t))
+
+	expected := `
+# HELP eks_cluster_hourly_price_by_capacity_type hourly price of all nodes with a known price by capacity type
+# TYPE eks_cluster_hourly_price_by_capacity_type gauge
+eks_cluster_hourly_price_by_capacity_type{capacity_type="on-demand"} 0.375
+eks_cluster_hourly_price_by_capacity_type{capacity_type="spot"} 0.035
+`
+	err := testutil.CollectAndCompare(c, strings.NewReader(expected), "eks_cluster_hourly_price_by_capacity_type")
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollectorMonthlyPrices(t *testing.T) {
+	cs := fake.NewSimpleClientset(
+		testNode("small", "on-demand", "m5.large"),
+		testNode("large", "on-demand", "m5.xlarge"),
+	)
+	c := collector.NewCollector(
+		context.Background(),
+		cs,
+		testRepository(t),
+		collector.WithMonthlyPrices(true),
+	)
+
+	expected := `
+# HELP eks_cluster_monthly_price monthly price of all nodes with a known price, the hourly price multiplied by 730 hours
+# TYPE eks_cluster_monthly_price gauge
+eks_cluster_monthly_price 273.75
+# HELP eks_node_monthly_price monthly price of node, the hourly price multiplied by 730 hours
+# TYPE eks_node_monthly_price gauge
+eks_node_monthly_price{capacity_type="on-demand",instance_type="m5.large",node="small",price_source="on-demand",region="us-east-1",status="Unknown",zone="us-east-1a"} 91.25
+eks_node_monthly_price{capacity_type="on-demand",instance_type="m5.xlarge",node="large",price_source="on-demand",region="us-east-1",status="Unknown",zone="us-east-1a"} 182.5
+`
+	err := testutil.CollectAndCompare(
+		c,
+		strings.NewReader(expected),
+		"eks_node_monthly_price",
+		"eks_cluster_monthly_price",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+
+	// without the option only hourly prices are emitted
+	c = collector.NewCollector(context.Background(), cs, testRepository(t))
+	if count := testutil.CollectAndCount(c, "eks_node_monthly_price", "eks_cluster_monthly_price"); count != 0 {
+		t.Errorf("expected no monthly price metrics by default, got %d", count)
+	}
+}
+
+func TestCollectorSpotPriceAggregates(t *testing.T) {
+	pr := pricing.NewRepository(&pricingtest.Provider{
+		Spot: pricing.SpotPriceList{
+			"m5.large": {"us-east-1a": 0.0625, "us-east-1b": 0.03125, "us-east-1c": 0.09375},
+		},
+	})
+	if err := pr.UpdateSpotPricing(context.Background()); err != nil {
+		t.Fatalf("unexpected error updating repository: %s", err)
+	}
+	c := collector.NewCollector(context.Background(), fake.NewSimpleClientset(), pr)
+
+	expected := `
+# HELP eks_instance_type_spot_price_avg average hourly spot price of the instance type across all zones in the region
+# TYPE eks_instance_type_spot_price_avg gauge
+eks_instance_type_spot_price_avg{instance_type="m5.large"} 0.0625
+# HELP eks_instance_type_spot_price_min lowest hourly spot price of the instance type across all zones in the region
+# TYPE eks_instance_type_spot_price_min gauge
+eks_instance_type_spot_price_min{instance_type="m5.large"} 0.03125
+`
+	err := testutil.CollectAndCompare(
+		c,
+		strings.NewReader(expected),
+		"eks_instance_type_spot_price_min",
+		"eks_instance_type_spot_price_avg",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollectorSpotPriceAggregatesMaxSeries(t *testing.T) {
+	pr := pricing.NewRepository(&pricingtest.Provider{
+		Spot: pricing.SpotPriceList{
+			"m5.large":  {"us-east-1a": 0.0625},
+			"m5.xlarge": {"us-east-1a": 0.125},
+		},
+	})
+	if err := pr.UpdateSpotPricing(context.Background()); err != nil {
+		t.Fatalf("unexpected error updating repository: %s", err)
+	}
+	metrics := []string{
+		"eks_instance_type_spot_price_min",
+		"eks_instance_type_spot_price_avg",
+		"eks_spot_price_zones_known",
+	}
+
+	c := collector.NewCollector(context.Background(), fake.NewSimpleClientset(), pr, collector.WithMaxInstanceTypeSeries(6))
+	if exp, got := 6, testutil.CollectAndCount(c, metrics...); exp != got {
+		t.Errorf("expected %d spot price aggregate series within the limit, got %d", exp, got)
+	}
+
+	c = collector.NewCollector(context.Background(), fake.NewSimpleClientset(), pr, collector.WithMaxInstanceTypeSeries(5))
+	if exp, got := 0, testutil.CollectAndCount(c, metrics...); exp != got {
+		t.Errorf("expected %d spot price aggregate series over the limit, got %d", exp, got)
+	}
+
+	// the per-node series limit doesn't apply to them
+	c = collector.NewCollector(context.Background(), fake.NewSimpleClientset(), pr, collector.WithMaxSeries(1))
+	if exp, got := 6, testutil.CollectAndCount(c, metrics...); exp != got {
+		t.Errorf("expected %d spot price aggregate series with a per-node series limit, got %d", exp, got)
+	}
+}
+
+func TestCollectorSpotPriceZonesKnown(t *testing.T) {
+	// the cluster runs in us-east-1a, us-east-1b and us-east-1c, but the spot feed for m5.xlarge is missing us-east-1c
+	pr := pricing.NewRepository(&pricingtest.Provider{
+		Spot: pricing.SpotPriceList{
+			"m5.large":  {"us-east-1a": 0.0625, "us-east-1b": 0.03125, "us-east-1c": 0.09375},
+			"m5.xlarge": {"us-east-1a": 0.125, "us-east-1b": 0.0625},
+		},
+	})
+	if err := pr.UpdateSpotPricing(context.Background()); err != nil {
+		t.Fatalf("unexpected error updating repository: %s", err)
+	}
+	c := collector.NewCollector(context.Background(), fake.NewSimpleClientset(), pr)
+
+	expected := `
+# HELP eks_spot_price_zones_known number of zones in the region with a known spot price for the instance type
+# TYPE eks_spot_price_zones_known gauge
+eks_spot_price_zones_known{instance_type="m5.large"} 3
+eks_spot_price_zones_known{instance_type="m5.xlarge"} 2
+`
+	err := testutil.CollectAndCompare(c, strings.NewReader(expected), "eks_spot_price_zones_known")
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollectorDebugPrices(t *testing.T) {
+	c := collector.NewCollector(
+		context.Background(),
+		fake.NewSimpleClientset(),
+		testRepository(t),
+		// m5.xlarge has prices too but isn't listed, m7i.large has no prices at all
+		collector.WithDebugInstanceTypes([]string{"m5.large", "m7i.large"}),
+	)
+
+	expected := `
+# HELP eks_debug_price hourly price resolved for the instance type, capacity type and zone, NaN if unknown
+# TYPE eks_debug_price gauge
+eks_debug_price{capacity_type="capacity-block",instance_type="m5.large",reason="no capacity block price for m5.large",source="unknown",zone=""} NaN
+eks_debug_price{capacity_type="capacity-block",instance_type="m7i.large",reason="no capacity block price for m7i.large",source="unknown",zone=""} NaN
+eks_debug_price{capacity_type="on-demand",instance_type="m5.large",reason="on-demand price for m5.large",source="unknown",zone=""} 0.125
+eks_debug_price{capacity_type="on-demand",instance_type="m7i.large",reason="no on-demand price for m7i.large",source="unknown",zone=""} NaN
+eks_debug_price{capacity_type="spot",instance_type="m5.large",reason="spot price for m5.large in us-east-1a",source="unknown",zone="us-east-1a"} 0.035
+eks_debug_price{capacity_type="spot",instance_type="m7i.large",reason="no spot price for m7i.large in ",source="unknown",zone=""} NaN
+`
+	err := testutil.CollectAndCompare(c, strings.NewReader(expected), "eks_debug_price")
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollectorPricePrecision(t *testing.T) {
+	pr := pricing.NewRepository(&pricingtest.Provider{
+		OnDemand: pricing.OnDemandPriceList{"m5.large": 0.123456},
+	})
+	if err := pr.UpdateOnDemandPricing(context.Background()); err != nil {
+		t.Fatalf("unexpected error updating repository: %s", err)
+	}
+	cs := fake.NewSimpleClientset(testNode("node", "on-demand", "m5.large"))
+
+	for name, tc := range map[string]struct {
+		opts     []collector.Option
+		expected float64
+	}{
+		"unrounded": {expected: 0.123456},
+		"rounded":   {opts: []collector.Option{collector.WithPricePrecision(4)}, expected: 0.1235},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := collector.NewCollector(context.Background(), cs, pr, tc.opts...)
+			if got := gatherValue(t, c, "eks_node_hourly_price"); got != tc.expected {
+				t.Errorf("expected node price %v, got %v", tc.expected, got)
+			}
+			if got := gatherValue(t, c, "eks_cluster_hourly_price"); got != tc.expected {
+				t.Errorf("expected cluster price %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestCollectorPricingStaleness(t *testing.T) {
+	updated := time.Now().Add(-time.Hour)
+	pr := pricing.NewRepository(&pricingtest.Provider{
+		OnDemand: pricing.OnDemandPriceList{"m5.large": 0.125},
+		Spot:     pricing.SpotPriceList{"m5.large": {"us-east-1a": 0.035}},
+	}, pricing.WithClock(func() time.Time { return updated }))
+	ctx := context.Background()
+	// fargate pricing is never updated, so has no staleness
+	for _, update := range []func(context.Context) error{pr.UpdateOnDemandPricing, pr.UpdateSpotPricing} {
+		if err := update(ctx); err != nil {
+			t.Fatalf("unexpected error updating repository: %s", err)
+		}
+	}
+	c := collector.NewCollector(ctx, fake.NewSimpleClientset(), pr)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(c)
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error gathering metrics: %s", err)
+	}
+	staleness := map[string]float64{}
+	for _, family := range families {
+		if family.GetName() != "eks_pricing_staleness_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			staleness[metric.GetLabel()[0].GetValue()] = metric.GetGauge().GetValue()
+		}
+	}
+	if exp, got := 2, len(staleness); exp != got {
+		t.Fatalf("expected staleness of %d pricing types, got %v", exp, staleness)
+	}
+	for _, pricingType := range []string{"on-demand", "spot"} {
+		// allow for the time the test takes to run
+		if got := staleness[pricingType]; got < 3600 || got > 3660 {
+			t.Errorf("expected %s staleness of about an hour, got %fs", pricingType, got)
+		}
+	}
+}
+
+func TestCollectorControlPlanePrice(t *testing.T) {
+	c := collector.NewCollector(context.Background(), fake.NewSimpleClientset(), testRepository(t))
+
+	expected := `
+# HELP eks_cluster_control_plane_hourly_price hourly fee charged for the EKS cluster control plane
+# TYPE eks_cluster_control_plane_hourly_price gauge
+eks_cluster_control_plane_hourly_price 0.1
+`
+	err := testutil.CollectAndCompare(c, strings.NewReader(expected), "eks_cluster_control_plane_hourly_price")
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollectorNodeHardwareInfo(t *testing.T) {
+	pr := pricing.NewRepository(&pricingtest.Provider{
+		InstanceSpecs: pricing.InstanceSpecList{
+			"m5.large":  {VCPUs: 2, MemoryMiB: 8192, NetworkPerformance: "Up to 10 Gigabit", EBSBandwidthMbps: 4750},
+			"m5n.large": {VCPUs: 2, MemoryMiB: 8192, NetworkPerformance: "Up to 25 Gigabit"},
+			"i4i.large": {
+				VCPUs:              2,
+				MemoryMiB:          16384,
+				NetworkPerformance: "Up to 25 Gigabit",
+				EBSBandwidthMbps:   10000,
+				InstanceStorageGB:  468,
+			},
+		},
+	})
+	if err := pr.UpdateInstanceSpecs(context.Background()); err != nil {
+		t.Fatalf("unexpected error updating repository: %s", err)
+	}
+	cs := fake.NewSimpleClientset(
+		testNode("node-1", "on-demand", "m5.large"),
+		testNode("node-2", "spot", "m5n.large"),
+		testNode("node-4", "on-demand", "i4i.large"),
+		// no spec is known for this instance type
+		testNode("node-3", "spot", "m7i.large"),
+	)
+	c := collector.NewCollector(context.Background(), cs, pr)
+
+	expected := `
+# HELP eks_node_hardware_info info labels about the hardware of the instance type of the node, with the EBS bandwidth in Mbps and the size of the instance store in GB
+# TYPE eks_node_hardware_info gauge
+eks_node_hardware_info{ebs_bandwidth="4750",instance_storage_gb="0",instance_type="m5.large",network_performance="Up to 10 Gigabit",node="node-1"} 1
+eks_node_hardware_info{ebs_bandwidth="",instance_storage_gb="0",instance_type="m5n.large",network_performance="Up to 25 Gigabit",node="node-2"} 1
+eks_node_hardware_info{ebs_bandwidth="10000",instance_storage_gb="468",instance_type="i4i.large",network_performance="Up to 25 Gigabit",node="node-4"} 1
+`
+	err := testutil.CollectAndCompare(c, strings.NewReader(expected), "eks_node_hardware_info")
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollectorPriceArchMismatch(t *testing.T) {
+	pr := pricing.NewRepository(&pricingtest.Provider{
+		OnDemand: pricing.OnDemandPriceList{"m6g.large": 0.077, "m5.large": 0.096},
+		InstanceSpecs: pricing.InstanceSpecList{
+			// simulates the cached price of the graviton instance type being of an x86 SKU
+			"m6g.large": {VCPUs: 2, MemoryMiB: 8192, Architectures: []string{"x86_64"}},
+			"m5.large":  {VCPUs: 2, MemoryMiB: 8192, Architectures: []string{"x86_64"}},
+		},
+	})
+	if err := pr.UpdatePricing(context.Background()); err != nil {
+		t.Fatalf("unexpected error updating repository: %s", err)
+	}
+	graviton := testNode("graviton", "on-demand", "m6g.large")
+	graviton.Labels[v1.LabelArchStable] = "arm64"
+	intel := testNode("intel", "on-demand", "m5.large")
+	intel.Labels[v1.LabelArchStable] = "amd64"
+	c := collector.NewCollector(context.Background(), fake.NewSimpleClientset(graviton, intel), pr)
+	expected := `
+# HELP eks_node_price_arch_mismatch nodes priced at the on-demand price of an instance type which doesn't support the architecture of the node, suggesting the price is of the wrong instance type
+# TYPE eks_node_price_arch_mismatch gauge
+eks_node_price_arch_mismatch{instance_type="m6g.large",node="graviton",node_arch="arm64",price_arch="x86_64"} 1
+`
+	err := testutil.CollectAndCompare(c, strings.NewReader(expected), "eks_node_price_arch_mismatch")
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollectorHourlyPricePerReadyPod(t *testing.T) {
+	testPod := func(namespace, name string, nodeName string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec:       v1.PodSpec{NodeName: nodeName},
+			Status:     v1.PodStatus{Phase: v1.PodRunning},
+		}
+	}
+	cs := fake.NewSimpleClientset(
+		testNode("busy", "on-demand", "m5.large"),
+		testNode("empty", "on-demand", "m5.xlarge"),
+		testPod("default", "app-1", "busy"),
+		testPod("default", "app-2", "busy"),
+		// system pods don't count towards the workload pods
+		testPod(metav1.NamespaceSystem, "aws-node-busy", "busy"),
+		testPod(metav1.NamespaceSystem, "aws-node-empty", "empty"),
+	)
+	c := collector.NewCollector(context.Background(), cs, testRepository(t))
+
+	expected := `
+# HELP eks_node_hourly_price_per_ready_pod hourly price of node divided by the number of workload pods on it, which excludes DaemonSet and kube-system pods, not emitted for nodes without workload pods
+# TYPE eks_node_hourly_price_per_ready_pod gauge
+eks_node_hourly_price_per_ready_pod{capacity_type="on-demand",instance_type="m5.large",node="busy",region="us-east-1",status="Unknown",zone="us-east-1a"} 0.0625
+`
+	err := testutil.CollectAndCompare(c, strings.NewReader(expected), "eks_node_hourly_price_per_ready_pod")
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollectorSystemOverheadSeparate(t *testing.T) {
+	node := testNode("node", "on-demand", "m5.large")
+	node.Status.Allocatable = v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}
+	testPod := func(namespace, name string, cpu string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec: v1.PodSpec{
+				NodeName: "node",
+				Containers: []v1.Container{{
+					Name: "container",
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu)},
+					},
+				}},
+			},
+			Status: v1.PodStatus{Phase: v1.PodRunning},
+		}
+	}
+	cs := fake.NewSimpleClientset(
+		node,
+		testPod("default", "app", "500m"),
+		testPod(metav1.NamespaceSystem, "aws-node", "1"),
+	)
+	c := collector.NewCollector(
+		context.Background(),
+		cs,
+		testRepository(t),
+		collector.WithSystemOverhead(model.SystemOverheadSeparate),
+	)
+
+	expected := `
+# HELP eks_node_system_overhead_hourly_price share of the hourly price of the node attributed to DaemonSet and kube-system pods
+# TYPE eks_node_system_overhead_hourly_price gauge
+eks_node_system_overhead_hourly_price{node="node"} 0.0625
+# HELP eks_pod_hourly_price share of the hourly price of the node attributed to the pod by its dominant resource request
+# TYPE eks_pod_hourly_price gauge
+eks_pod_hourly_price{namespace="default",node="node",pod="app"} 0.03125
+`
+	err := testutil.CollectAndCompare(
+		c,
+		strings.NewReader(expected),
+		"eks_node_system_overhead_hourly_price",
+		"eks_pod_hourly_price",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollectorExcludeNamespaces(t *testing.T) {
+	node := testNode("node", "on-demand", "m5.large")
+	node.Status.Allocatable = v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}
+	testPod := func(namespace, name string, cpu string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec: v1.PodSpec{
+				NodeName: "node",
+				Containers: []v1.Container{{
+					Name: "container",
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu)},
+					},
+				}},
+			},
+			Status: v1.PodStatus{Phase: v1.PodRunning},
+		}
+	}
+	cs := fake.NewSimpleClientset(
+		node,
+		testPod("default", "app", "500m"),
+		testPod("default", "worker", "500m"),
+		testPod(metav1.NamespaceSystem, "coredns", "1"),
+	)
+	c := collector.NewCollector(
+		context.Background(),
+		cs,
+		testRepository(t),
+		collector.WithExcludeNamespaces([]string{metav1.NamespaceSystem}),
+	)
+
+	expected := `
+# HELP eks_cluster_billable_hourly_price sum of the hourly prices attributed to pods outside of the excluded namespaces
+# TYPE eks_cluster_billable_hourly_price gauge
+eks_cluster_billable_hourly_price 0.0625
+# HELP eks_namespace_hourly_price sum of the hourly prices attributed to the pods in the namespace
+# TYPE eks_namespace_hourly_price gauge
+eks_namespace_hourly_price{namespace="default"} 0.0625
+# HELP eks_pod_hourly_price share of the hourly price of the node attributed to the pod by its dominant resource request
+# TYPE eks_pod_hourly_price gauge
+eks_pod_hourly_price{namespace="default",node="node",pod="app"} 0.03125
+eks_pod_hourly_price{namespace="default",node="node",pod="worker"} 0.03125
+`
+	err := testutil.CollectAndCompare(
+		c,
+		strings.NewReader(expected),
+		"eks_cluster_billable_hourly_price",
+		"eks_namespace_hourly_price",
+		"eks_pod_hourly_price",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func testManyNodes(count int) []runtime.Object {
+	var objects []runtime.Object
+	for i := 0; i < count; i++ {
+		instanceType := "m5.large"
+		if i%2 == 0 {
+			instanceType = "m5.xlarge"
+		}
+		objects = append(objects, testNode(fmt.Sprintf("node-%d", i), "on-demand", instanceType))
+	}
+	return objects
+}
+
+// TestCollectorManyNodes is most useful when run with -race.
+func TestCollectorManyNodes(t *testing.T) {
+	cs := fake.NewSimpleClientset(testManyNodes(500)...)
+	c := collector.NewCollector(context.Background(), cs, testRepository(t), collector.WithWorkers(16))
+
+	if exp, got := 500, testutil.CollectAndCount(c, "eks_node_hourly_price"); exp != got {
+		t.Errorf("expected %d eks_node_hourly_price series, got %d", exp, got)
+	}
+	expected := `
+# HELP eks_cluster_hourly_price hourly price of all nodes with a known price
+# TYPE eks_cluster_hourly_price gauge
+eks_cluster_hourly_price 93.75
+`
+	err := testutil.CollectAndCompare(c, strings.NewReader(expected), "eks_cluster_hourly_price")
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func BenchmarkCollect(b *testing.B) {
+	cs := fake.NewSimpleClientset(testManyNodes(1000)...)
+	pr := pricing.NewRepository(pricing.NewStaticProvider())
+	if err := pr.UpdatePricing(context.Background()); err != nil {
+		b.Fatalf("unexpected error updating repository: %s", err)
+	}
+	for _, workers := range []int{1, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			c := collector.NewCollector(context.Background(), cs, pr, collector.WithWorkers(workers))
+			for i := 0; i < b.N; i++ {
+				testutil.CollectAndCount(c)
+			}
+		})
+	}
+}
+
+func TestCollectorStaleNodesWhenPopulateFails(t *testing.T) {
+	cs := fake.NewSimpleClientset(
+		testNode("node-1", "on-demand", "m5.large"),
+		testNode("node-2", "on-demand", "m5.xlarge"),
+	)
+	lists := 0
+	cs.PrependReactor("list", "nodes", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+		lists++
+		if lists > 1 {
+			return true, nil, errors.New("apiserver unavailable")
+		}
+		return false, nil, nil
+	})
+	c := collector.NewCollector(context.Background(), cs, testRepository(t))
+
+	if exp, got := 2, testutil.CollectAndCount(c, "eks_node_hourly_price"); exp != got {
+		t.Fatalf("expected %d eks_node_hourly_price series, got %d", exp, got)
+	}
+	if got := gatherValue(t, c, "eks_collector_data_age_seconds"); got > 1 {
+		t.Errorf("expected recent data, got age %f", got)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	if exp, got := 2, testutil.CollectAndCount(c, "eks_node_hourly_price"); exp != got {
+		t.Errorf("expected %d eks_node_hourly_price series from the last good snapshot, got %d", exp, got)
+	}
+	if got := gatherValue(t, c, "eks_collector_data_age_seconds"); got < 0.01 {
+		t.Errorf("expected the data age to grow while populate fails, got %f", got)
+	}
+	expected := `
+# HELP eks_cluster_hourly_price hourly price of all nodes with a known price
+# TYPE eks_cluster_hourly_price gauge
+eks_cluster_hourly_price 0.375
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected), "eks_cluster_hourly_price"); err != nil {
+		t.Error(err)
+	}
+}
+
+// gatherValue returns the value of the single gauge series with the given name collected from c.
+func TestCollectorConcurrentCollectsWhenPopulateFails(t *testing.T) {
+	cs := fake.NewSimpleClientset(
+		testNode("node-1", "on-demand", "m5.large"),
+		testNode("node-2", "on-demand", "m5.xlarge"),
+	)
+	var lists int32
+	cs.PrependReactor("list", "nodes", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+		if atomic.AddInt32(&lists, 1) > 1 {
+			return true, nil, errors.New("apiserver unavailable")
+		}
+		return false, nil, nil
+	})
+	c := collector.NewCollector(context.Background(), cs, testRepository(t), collector.WithWorkers(4))
+	testutil.CollectAndCount(c)
+
+	// every collection prices its own copy of the last good snapshot
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if exp, got := 2, testutil.CollectAndCount(c, "eks_node_hourly_price"); exp != got {
+				t.Errorf("expected %d eks_node_hourly_price series from the last good snapshot, got %d", exp, got)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func gatherValue(t *testing.T, c prometheus.Collector, name string) float64 {
+	t.Helper()
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(c)
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error gathering metrics: %s", err)
+	}
+	for _, family := range families {
+		if family.GetName() == name {
+			return family.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+	t.Fatalf("metric %s not found", name)
+	return 0
+}
+
+type testDescribeInstancesClient struct {
+	instances map[string]ec2types.Instance
+	calls     int
+}
+
+func (c *testDescribeInstancesClient) DescribeInstances(
+	_ context.Context,
+	input *ec2.DescribeInstancesInput,
+	_ ...func(*ec2.Options),
+) (*ec2.DescribeInstancesOutput, error) {
+	c.calls++
+	reservation := ec2types.Reservation{}
+	for _, instanceID := range input.InstanceIds {
+		instance, ok := c.instances[instanceID]
+		if !ok {
+			return nil, &smithy.GenericAPIError{Code: "InvalidInstanceID.NotFound"}
+		}
+		reservation.Instances = append(reservation.Instances, instance)
+	}
+	return &ec2.DescribeInstancesOutput{Reservations: []ec2types.Reservation{reservation}}, nil
+}
+
+func TestCollectorInstanceLookupFromProviderID(t *testing.T) {
+	n := testNode("node", "on-demand", "")
+	n.Labels = map[string]string{"karpenter.sh/capacity-type": "on-demand"}
+	n.Spec.ProviderID = "aws:///us-east-1a/i-0123456789abcdef0"
+	cs := fake.NewSimpleClientset(n)
+	client := &testDescribeInstancesClient{
+		instances: map[string]ec2types.Instance{
+			"i-0123456789abcdef0": {
+				InstanceId:   aws.String("i-0123456789abcdef0"),
+				InstanceType: ec2types.InstanceTypeM5Large,
+				Placement:    &ec2types.Placement{AvailabilityZone: aws.String("us-east-1a")},
+			},
+		},
+	}
+	c := collector.NewCollector(
+		context.Background(),
+		cs,
+		testRepository(t),
+		collector.WithInstanceLookup(pricing.NewInstanceLookup(client)),
+	)
+
+	expected := `
+# HELP eks_node_hourly_price hourly price of node
+# TYPE eks_node_hourly_price gauge
+eks_node_hourly_price{capacity_type="on-demand",instance_type="m5.large",node="node",price_source="on-demand",region="",status="Unknown",zone="us-east-1a"} 0.125
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected), "eks_node_hourly_price"); err != nil {
+		t.Error(err)
+	}
+	// the second collection is served from the cache
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected), "eks_node_hourly_price"); err != nil {
+		t.Error(err)
+	}
+	if exp, got := 1, client.calls; exp != got {
+		t.Errorf("expected %d DescribeInstances calls, got %d", exp, got)
+	}
+}
+
+func TestCollectorInstanceLookupNotFound(t *testing.T) {
+	found := testNode("found", "on-demand", "")
+	found.Labels = map[string]string{"karpenter.sh/capacity-type": "on-demand"}
+	found.Spec.ProviderID = "aws:///us-east-1a/i-0123456789abcdef0"
+	gone := testNode("gone", "on-demand", "")
+	gone.Labels = map[string]string{"karpenter.sh/capacity-type": "on-demand"}
+	gone.Spec.ProviderID = "aws:///us-east-1a/i-0fedcba9876543210"
+	cs := fake.NewSimpleClientset(found, gone)
+	client := &testDescribeInstancesClient{
+		instances: map[string]ec2types.Instance{
+			"i-0123456789abcdef0": {
+				InstanceId:   aws.String("i-0123456789abcdef0"),
+				InstanceType: ec2types.InstanceTypeM5Large,
+				Placement:    &ec2types.Placement{AvailabilityZone: aws.String("us-east-1a")},
+			},
+		},
+	}
+	c := collector.NewCollector(
+		context.Background(),
+		cs,
+		testRepository(t),
+		collector.WithInstanceLookup(pricing.NewInstanceLookup(client)),
+	)
+
+	expected := `
+# HELP eks_node_hourly_price hourly price of node
+# TYPE eks_node_hourly_price gauge
+eks_node_hourly_price{capacity_type="on-demand",instance_type="",node="gone",price_source="none",region="",status="Unknown",zone="us-east-1a"} NaN
+eks_node_hourly_price{capacity_type="on-demand",instance_type="m5.large",node="found",price_source="on-demand",region="",status="Unknown",zone="us-east-1a"} 0.125
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected), "eks_node_hourly_price"); err != nil {
+		t.Error(err)
+	}
+	// the failed batch call is retried one instance at a time
+	if exp, got := 3, client.calls; exp != got {
+		t.Errorf("expected %d DescribeInstances calls, got %d", exp, got)
+	}
+	// the instance that wasn't found is cached too
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected), "eks_node_hourly_price"); err != nil {
+		t.Error(err)
+	}
+	if exp, got := 3, client.calls; exp != got {
+		t.Errorf("expected %d DescribeInstances calls, got %d", exp, got)
+	}
+}
+
+func TestCollectorInstanceLookupPrunesRemovedNodes(t *testing.T) {
+	n := testNode("node", "on-demand", "")
+	n.Labels = map[string]string{"karpenter.sh/capacity-type": "on-demand"}
+	n.Spec.ProviderID = "aws:///us-east-1a/i-0123456789abcdef0"
+	cs := fake.NewSimpleClientset(n)
+	client := &testDescribeInstancesClient{
+		instances: map[string]ec2types.Instance{
+			"i-0123456789abcdef0": {
+				InstanceId:   aws.String("i-0123456789abcdef0"),
+				InstanceType: ec2types.InstanceTypeM5Large,
+				Placement:    &ec2types.Placement{AvailabilityZone: aws.String("us-east-1a")},
+			},
+		},
+	}
+	c := collector.NewCollector(
+		context.Background(),
+		cs,
+		testRepository(t),
+		collector.WithInstanceLookup(pricing.NewInstanceLookup(client)),
+	)
+
+	testutil.CollectAndCount(c, "eks_node_hourly_price")
+	if err := cs.CoreV1().Nodes().Delete(context.Background(), "node", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("unexpected error deleting node: %s", err)
+	}
+	testutil.CollectAndCount(c, "eks_node_hourly_price")
+	if _, err := cs.CoreV1().Nodes().Create(context.Background(), n, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("unexpected error creating node: %s", err)
+	}
+	// the instance was dropped from the cache while its node was gone, so it is looked up again
+	if exp, got := 1, testutil.CollectAndCount(c, "eks_node_hourly_price"); exp != got {
+		t.Errorf("expected %d series, got %d", exp, got)
+	}
+	if exp, got := 2, client.calls; exp != got {
+		t.Errorf("expected %d DescribeInstances calls, got %d", exp, got)
+	}
+}
@@ -0,0 +1,162 @@
+package collector
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing"
+)
+
+// NodeClaimResources are the versions of the Karpenter NodeClaim custom resource in order of preference, v1beta1
+// being served by Karpenter releases before v1.0.
+var NodeClaimResources = []schema.GroupVersionResource{
+	{Group: "karpenter.sh", Version: "v1", Resource: "nodeclaims"},
+	{Group: "karpenter.sh", Version: "v1beta1", Resource: "nodeclaims"},
+}
+
+// NodeClaimCollector prices Karpenter NodeClaims which haven't registered a Node yet, so capacity which is about to
+// be billed shows up before its Node exists.
+type NodeClaimCollector struct {
+	parentCtx         context.Context
+	client            dynamic.Interface
+	pricingRepository *pricing.Repository
+	roundPrice        func(float64) float64
+	// resource is the index of the NodeClaimResources version served by the cluster the last time it was listed
+	resource atomic.Int64
+
+	pendingPrice *prometheus.Desc
+	pendingCount *prometheus.Desc
+	// pendingMonthlyPrice is only set if WithMonthlyPrices is enabled
+	pendingMonthlyPrice *prometheus.Desc
+}
+
+// NewNodeClaimCollector returns a NodeClaimCollector listing NodeClaims with client. Its metrics follow the naming,
+// overrides and monthly prices of c.
+func (c *Collector) NewNodeClaimCollector(client dynamic.Interface) *NodeClaimCollector {
+	nc := &NodeClaimCollector{
+		parentCtx:         c.parentCtx,
+		client:            client,
+		pricingRepository: c.pricingRepository,
+		roundPrice:        c.roundPrice,
+		pendingPrice: c.newDesc(
+			prometheus.BuildFQName(c.namespace, "pending_capacity", "hourly_price"+c.priceUnitSuffix),
+			"hourly price of all Karpenter NodeClaims with a known price which haven't registered a node yet",
+			nil,
+			nil,
+		),
+		pendingCount: c.newDesc(
+			prometheus.BuildFQName(c.namespace, "pending_capacity", "nodeclaims"),
+			"number of Karpenter NodeClaims which haven't registered a node yet by whether their price is known",
+			[]string{"priced"},
+			nil,
+		),
+	}
+	if c.monthlyPrices {
+		nc.pendingMonthlyPrice = c.newDesc(
+			prometheus.BuildFQName(c.namespace, "pending_capacity", "monthly_price"+c.priceUnitSuffix),
+			"monthly price of all Karpenter NodeClaims with a known price which haven't registered a node yet",
+			nil,
+			nil,
+		)
+	}
+	return nc
+}
+
+func (c *NodeClaimCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.pendingPrice
+	ch <- c.pendingCount
+	if c.pendingMonthlyPrice != nil {
+		ch <- c.pendingMonthlyPrice
+	}
+}
+
+func (c *NodeClaimCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(c.parentCtx, time.Minute)
+	defer cancel()
+
+	list, err := c.listNodeClaims(ctx)
+	if err != nil {
+		log.Printf("listing nodeclaims failed: %s", err)
+		return
+	}
+	var total float64
+	var priced, unpriced int
+	for i := range list.Items {
+		nodeClaim := &list.Items[i]
+		if !nodeClaimPending(nodeClaim) {
+			continue
+		}
+		price, ok := c.nodeClaimPrice(nodeClaim)
+		if !ok {
+			unpriced++
+			continue
+		}
+		priced++
+		total += price
+	}
+	ch <- prometheus.MustNewConstMetric(c.pendingPrice, prometheus.GaugeValue, c.roundPrice(total))
+	ch <- prometheus.MustNewConstMetric(c.pendingCount, prometheus.GaugeValue, float64(priced), "true")
+	ch <- prometheus.MustNewConstMetric(c.pendingCount, prometheus.GaugeValue, float64(unpriced), "false")
+	if c.pendingMonthlyPrice != nil {
+		ch <- prometheus.MustNewConstMetric(
+			c.pendingMonthlyPrice,
+			prometheus.GaugeValue,
+			c.roundPrice(total*hoursPerMonth),
+		)
+	}
+}
+
+// listNodeClaims lists the NodeClaims of the first version of NodeClaimResources the cluster serves, starting with
+// the version served the last time.
+func (c *NodeClaimCollector) listNodeClaims(ctx context.Context) (*unstructured.UnstructuredList, error) {
+	last := int(c.resource.Load())
+	list, err := c.client.Resource(NodeClaimResources[last]).List(ctx, metav1.ListOptions{})
+	for i := 0; i < len(NodeClaimResources) && apierrors.IsNotFound(err); i++ {
+		if i == last {
+			continue
+		}
+		list, err = c.client.Resource(NodeClaimResources[i]).List(ctx, metav1.ListOptions{})
+		if err == nil {
+			c.resource.Store(int64(i))
+		}
+	}
+	return list, err
+}
+
+// nodeClaimPending returns true if the NodeClaim isn't being deleted and hasn't registered a Node yet.
+func nodeClaimPending(nodeClaim *unstructured.Unstructured) bool {
+	if nodeClaim.GetDeletionTimestamp() != nil {
+		return false
+	}
+	nodeName, _, _ := unstructured.NestedString(nodeClaim.Object, "status", "nodeName")
+	return nodeName == ""
+}
+
+// nodeClaimPrice returns the hourly price of a NodeClaim from the instance type, capacity type and zone labels
+// Karpenter sets once it has launched the instance, returning false if they aren't set yet or there is no price.
+func (c *NodeClaimCollector) nodeClaimPrice(nodeClaim *unstructured.Unstructured) (float64, bool) {
+	labels := nodeClaim.GetLabels()
+	instanceType := labels[v1.LabelInstanceTypeStable]
+	if instanceType == "" {
+		return 0, false
+	}
+	result := c.pricingRepository.Lookup(pricing.LookupRequest{
+		InstanceType: instanceType,
+		CapacityType: labels["karpenter.sh/capacity-type"],
+		Zone:         labels[v1.LabelTopologyZone],
+	})
+	if result.Price == nil {
+		return 0, false
+	}
+	return *result.Price, true
+}
@@ -0,0 +1,123 @@
+package collector_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/sapslaj/eks-pricing-exporter/pkg/collector"
+)
+
+func testNodeClaim(name string, labels map[string]string, nodeName string) *unstructured.Unstructured {
+	nodeClaim := &unstructured.Unstructured{}
+	nodeClaim.SetAPIVersion("karpenter.sh/v1")
+	nodeClaim.SetKind("NodeClaim")
+	nodeClaim.SetName(name)
+	nodeClaim.SetLabels(labels)
+	if nodeName != "" {
+		_ = unstructured.SetNestedField(nodeClaim.Object, nodeName, "status", "nodeName")
+	}
+	return nodeClaim
+}
+
+func testNodeClaimClient(nodeClaims ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	listKinds := map[schema.GroupVersionResource]string{}
+	for _, resource := range collector.NodeClaimResources {
+		listKinds[resource] = "NodeClaimList"
+	}
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, nodeClaims...)
+}
+
+func TestNodeClaimCollector(t *testing.T) {
+	client := testNodeClaimClient(
+		testNodeClaim("launched-small", map[string]string{
+			"karpenter.sh/capacity-type": "on-demand",
+			v1.LabelInstanceTypeStable:   "m5.large",
+			v1.LabelTopologyZone:         "us-east-1a",
+		}, ""),
+		testNodeClaim("launched-large", map[string]string{
+			"karpenter.sh/capacity-type": "on-demand",
+			v1.LabelInstanceTypeStable:   "m5.xlarge",
+			v1.LabelTopologyZone:         "us-east-1a",
+		}, ""),
+		// not launched yet, so the instance type isn't known
+		testNodeClaim("launching", map[string]string{
+			"karpenter.sh/capacity-type": "spot",
+		}, ""),
+		// already priced through its node
+		testNodeClaim("registered", map[string]string{
+			"karpenter.sh/capacity-type": "spot",
+			v1.LabelInstanceTypeStable:   "m5.large",
+			v1.LabelTopologyZone:         "us-east-1a",
+		}, "ip-10-0-0-1.ec2.internal"),
+	)
+	c := collector.NewCollector(context.Background(), nil, testRepository(t)).NewNodeClaimCollector(client)
+
+	expected := `
+# HELP eks_pending_capacity_hourly_price hourly price of all Karpenter NodeClaims with a known price which haven't registered a node yet
+# TYPE eks_pending_capacity_hourly_price gauge
+eks_pending_capacity_hourly_price 0.375
+# HELP eks_pending_capacity_nodeclaims number of Karpenter NodeClaims which haven't registered a node yet by whether their price is known
+# TYPE eks_pending_capacity_nodeclaims gauge
+eks_pending_capacity_nodeclaims{priced="false"} 1
+eks_pending_capacity_nodeclaims{priced="true"} 2
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNodeClaimCollectorV1beta1(t *testing.T) {
+	nodeClaim := testNodeClaim("launched", map[string]string{
+		"karpenter.sh/capacity-type": "on-demand",
+		v1.LabelInstanceTypeStable:   "m5.large",
+		v1.LabelTopologyZone:         "us-east-1a",
+	}, "")
+	nodeClaim.SetAPIVersion("karpenter.sh/v1beta1")
+	client := testNodeClaimClient(nodeClaim)
+	// Karpenter releases before v1.0 don't serve karpenter.sh/v1
+	client.PrependReactor("list", "nodeclaims", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetResource().Version == "v1" {
+			return true, nil, apierrors.NewNotFound(action.GetResource().GroupResource(), "")
+		}
+		return false, nil, nil
+	})
+	c := collector.NewCollector(
+		context.Background(),
+		nil,
+		testRepository(t),
+		collector.WithUnitSuffixes(true),
+		collector.WithMonthlyPrices(true),
+		collector.WithMetricOverrides(collector.MetricOverrides{
+			Help: map[string]string{"eks_pending_capacity_nodeclaims": "pending nodeclaims"},
+		}),
+	).NewNodeClaimCollector(client)
+
+	expected := `
+# HELP eks_pending_capacity_hourly_price_usd hourly price of all Karpenter NodeClaims with a known price which haven't registered a node yet
+# TYPE eks_pending_capacity_hourly_price_usd gauge
+eks_pending_capacity_hourly_price_usd 0.125
+# HELP eks_pending_capacity_monthly_price_usd monthly price of all Karpenter NodeClaims with a known price which haven't registered a node yet
+# TYPE eks_pending_capacity_monthly_price_usd gauge
+eks_pending_capacity_monthly_price_usd 91.25
+# HELP eks_pending_capacity_nodeclaims pending nodeclaims
+# TYPE eks_pending_capacity_nodeclaims gauge
+eks_pending_capacity_nodeclaims{priced="false"} 0
+eks_pending_capacity_nodeclaims{priced="true"} 1
+`
+	// the second scrape lists the version found by the first
+	for i := 0; i < 2; i++ {
+		if err := testutil.CollectAndCompare(c, strings.NewReader(expected)); err != nil {
+			t.Errorf("scrape %d: %s", i, err)
+		}
+	}
+}
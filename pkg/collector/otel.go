@@ -0,0 +1,125 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/sapslaj/eks-pricing-exporter/pkg/model"
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing"
+)
+
+// OTelCollector mirrors the node price and info metrics of the Collector as OpenTelemetry observable gauges, for
+// exporting them over OTLP instead of or alongside Prometheus.
+type OTelCollector struct {
+	collector *Collector
+
+	nodeInfo           metric.Float64ObservableGauge
+	hourlyPrice        metric.Float64ObservableGauge
+	clusterHourlyPrice metric.Float64ObservableGauge
+	registration       metric.Registration
+}
+
+// NewOTelCollector creates the instruments of the OTelCollector with meter and registers the callback observing them
+// on every collection of the meter. The nodes are populated, priced and filtered by collector, so the observed prices
+// match its metrics.
+func NewOTelCollector(meter metric.Meter, collector *Collector) (*OTelCollector, error) {
+	c := &OTelCollector{
+		collector: collector,
+	}
+	nodeInfo := prometheus.BuildFQName(pricing.MetricsNamespace, "node", "info")
+	hourlyPrice := prometheus.BuildFQName(pricing.MetricsNamespace, "node", "hourly_price")
+	clusterHourlyPrice := prometheus.BuildFQName(pricing.MetricsNamespace, "cluster", "hourly_price")
+	var err error
+	c.nodeInfo, err = meter.Float64ObservableGauge(
+		nodeInfo,
+		metric.WithDescription("info labels about the node"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating %s instrument: %w", nodeInfo, err)
+	}
+	c.hourlyPrice, err = meter.Float64ObservableGauge(
+		hourlyPrice,
+		metric.WithDescription("hourly price of node"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating %s instrument: %w", hourlyPrice, err)
+	}
+	c.clusterHourlyPrice, err = meter.Float64ObservableGauge(
+		clusterHourlyPrice,
+		metric.WithDescription("hourly price of all nodes with a known price"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating %s instrument: %w", clusterHourlyPrice, err)
+	}
+	c.registration, err = meter.RegisterCallback(c.observe, c.nodeInfo, c.hourlyPrice, c.clusterHourlyPrice)
+	if err != nil {
+		return nil, fmt.Errorf("registering callback: %w", err)
+	}
+	return c, nil
+}
+
+// Unregister stops observing the instruments.
+func (c *OTelCollector) Unregister() error {
+	return c.registration.Unregister()
+}
+
+func (c *OTelCollector) observe(ctx context.Context, o metric.Observer) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	pr := c.collector.pricingRepository.Snapshot()
+	nodes, populated, err := c.collector.populatePriced(ctx, pr)
+	if err != nil {
+		if populated.IsZero() {
+			return fmt.Errorf("getting cluster information failed: %w", err)
+		}
+		log.Printf(
+			"getting cluster information failed, observing the nodes last seen at %s: %s",
+			populated.Format(time.RFC3339),
+			err,
+		)
+	}
+	totalPrice := 0.0
+	for _, node := range nodes {
+		attrs := nodeAttributes(node)
+		o.ObserveFloat64(
+			c.nodeInfo,
+			1,
+			metric.WithAttributes(append(
+				attrs,
+				attribute.String("os_image", node.OSImage()),
+				attribute.String("os_distribution", node.OSDistribution().String()),
+				attribute.String("instance_family", node.InstanceFamily()),
+			)...),
+		)
+		if !c.collector.emitsPrice(node) {
+			continue
+		}
+		o.ObserveFloat64(
+			c.hourlyPrice,
+			c.collector.roundPrice(node.Price),
+			metric.WithAttributes(append(attrs, attribute.String("price_source", node.PriceSource.String()))...),
+		)
+		if node.HasPrice() {
+			totalPrice += node.Price
+		}
+	}
+	o.ObserveFloat64(c.clusterHourlyPrice, c.collector.roundPrice(totalPrice))
+	return nil
+}
+
+// nodeAttributes returns the OpenTelemetry equivalent of nodeLabelValues.
+func nodeAttributes(node *model.Node) []attribute.KeyValue {
+	values := nodeLabelValues(node)
+	attrs := make([]attribute.KeyValue, len(nodeLabels))
+	for i, label := range nodeLabels {
+		attrs[i] = attribute.String(label, values[i])
+	}
+	return attrs
+}
@@ -0,0 +1,68 @@
+package collector_test
+
+import (
+	"context"
+	"testing"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/sapslaj/eks-pricing-exporter/pkg/collector"
+)
+
+func TestOTelCollector(t *testing.T) {
+	cs := fake.NewSimpleClientset(
+		testNode("large", "on-demand", "m5.large"),
+		testNode("spot", "spot", "m5.large"),
+		// denied instance types are left out like they are by the collector
+		testNode("denied", "on-demand", "m5.xlarge"),
+	)
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	c := collector.NewCollector(
+		context.Background(),
+		cs,
+		testRepository(t),
+		collector.WithInstanceTypeDenylist([]string{"m5.xlarge"}),
+	)
+	_, err := collector.NewOTelCollector(provider.Meter("test"), c)
+	if err != nil {
+		t.Fatalf("unexpected error creating otel collector: %s", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("unexpected error collecting metrics: %s", err)
+	}
+	gauges := map[string]metricdata.Gauge[float64]{}
+	for _, scopeMetrics := range rm.ScopeMetrics {
+		for _, m := range scopeMetrics.Metrics {
+			if gauge, ok := m.Data.(metricdata.Gauge[float64]); ok {
+				gauges[m.Name] = gauge
+			}
+		}
+	}
+
+	prices := map[string]float64{}
+	for _, point := range gauges["eks_node_hourly_price"].DataPoints {
+		node, _ := point.Attributes.Value("node")
+		source, _ := point.Attributes.Value("price_source")
+		prices[node.AsString()+"/"+source.AsString()] = point.Value
+	}
+	for key, exp := range map[string]float64{"large/on-demand": 0.125, "spot/spot": 0.035} {
+		if got, ok := prices[key]; !ok || exp != got {
+			t.Errorf("expected eks_node_hourly_price of %s to be %v, got %v", key, exp, prices)
+		}
+	}
+	if _, ok := prices["denied/on-demand"]; ok {
+		t.Errorf("expected no eks_node_hourly_price of the denied node, got %v", prices)
+	}
+	if exp, got := 3, len(gauges["eks_node_info"].DataPoints); exp != got {
+		t.Errorf("expected %d eks_node_info data points, got %d", exp, got)
+	}
+	clusterPoints := gauges["eks_cluster_hourly_price"].DataPoints
+	if len(clusterPoints) != 1 || clusterPoints[0].Value != 0.16 {
+		t.Errorf("expected eks_cluster_hourly_price to be 0.16, got %v", clusterPoints)
+	}
+}
@@ -0,0 +1,117 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+)
+
+// MetricOverrides replaces the help text of metrics and renames their labels, for organizations with their own
+// conventions, e.g. availability_zone instead of zone.
+type MetricOverrides struct {
+	// Help is the help text of metrics by their full name, e.g. eks_node_hourly_price.
+	Help map[string]string `json:"help"`
+	// Labels is the name labels are emitted with by their default name, e.g. zone to availability_zone.
+	Labels map[string]string `json:"labels"`
+}
+
+// LoadMetricOverrides reads the metric overrides from a JSON file like
+// {"help": {"eks_node_hourly_price": "..."}, "labels": {"zone": "availability_zone"}}.
+func LoadMetricOverrides(path string) (MetricOverrides, error) {
+	var overrides MetricOverrides
+	f, err := os.Open(path)
+	if err != nil {
+		return overrides, fmt.Errorf("opening metric overrides: %w", err)
+	}
+	defer f.Close()
+	decoder := json.NewDecoder(f)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&overrides); err != nil {
+		return overrides, fmt.Errorf("parsing metric overrides %s: %w", path, err)
+	}
+	return overrides, nil
+}
+
+// ParseLabelRenames parses label renames like zone=availability_zone into the label names by default name.
+func ParseLabelRenames(renames []string) (map[string]string, error) {
+	labels := map[string]string{}
+	for _, rename := range renames {
+		label, name, ok := strings.Cut(rename, "=")
+		if !ok || label == "" {
+			return nil, fmt.Errorf("invalid label rename %q: must be like zone=availability_zone", rename)
+		}
+		labels[label] = name
+	}
+	return labels, nil
+}
+
+// Validate returns an error if a label is renamed to an invalid or reserved label name, two labels are renamed to the
+// same name, or help text is given for an invalid metric name.
+func (o MetricOverrides) Validate() error {
+	labels := make([]string, 0, len(o.Labels))
+	for label := range o.Labels {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	renamedFrom := map[string]string{}
+	for _, label := range labels {
+		name := o.Labels[label]
+		if !model.LabelName(name).IsValid() || strings.HasPrefix(name, model.ReservedLabelPrefix) {
+			return fmt.Errorf("invalid name %q for label %s", name, label)
+		}
+		if other, ok := renamedFrom[name]; ok {
+			return fmt.Errorf("labels %s and %s can't both be renamed to %s", other, label, name)
+		}
+		renamedFrom[name] = label
+	}
+	for metric := range o.Help {
+		if !model.IsValidMetricName(model.LabelValue(metric)) {
+			return fmt.Errorf("invalid metric name %q in help overrides", metric)
+		}
+	}
+	return nil
+}
+
+// WithMetricOverrides replaces the help text and renames the labels of the metrics as given by overrides, which must
+// be valid, see MetricOverrides.Validate. Renaming a label to the name of another label of the same metric makes
+// registering the collector fail.
+func WithMetricOverrides(overrides MetricOverrides) Option {
+	return func(c *Collector) {
+		c.metricOverrides = overrides
+	}
+}
+
+// newDesc is prometheus.NewDesc with the metric overrides of the collector applied.
+func (c *Collector) newDesc(
+	fqName string,
+	help string,
+	variableLabels []string,
+	constLabels prometheus.Labels,
+) *prometheus.Desc {
+	labels := make([]string, len(variableLabels))
+	for i, label := range variableLabels {
+		labels[i] = c.labelName(label)
+	}
+	return prometheus.NewDesc(fqName, c.metricHelp(fqName, help), labels, constLabels)
+}
+
+// metricHelp returns the help text of the metric fqName, help unless overridden.
+func (c *Collector) metricHelp(fqName string, help string) string {
+	if override, ok := c.metricOverrides.Help[fqName]; ok {
+		return override
+	}
+	return help
+}
+
+// labelName returns the name label is emitted with, label itself unless renamed.
+func (c *Collector) labelName(label string) string {
+	if name, ok := c.metricOverrides.Labels[label]; ok {
+		return name
+	}
+	return label
+}
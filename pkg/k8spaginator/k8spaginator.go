@@ -2,24 +2,47 @@ package k8spaginator
 
 import (
 	"context"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
 type ListFunc[V any] func(context.Context, string) ([]V, string, error)
 
-func New[V any](listFunc ListFunc[V]) *Paginator[V] {
-	return &Paginator[V]{
-		listFunc: listFunc,
+type options struct {
+	maxRetries int
+	backoff    time.Duration
+}
+
+// Option configures optional behavior of a Paginator.
+type Option func(*options)
+
+// WithRetry retries a failed page up to maxRetries times when the API server returns a transient error, waiting
+// backoff before the first retry and doubling the wait for each subsequent retry.
+func WithRetry(maxRetries int, backoff time.Duration) Option {
+	return func(o *options) {
+		o.maxRetries = maxRetries
+		o.backoff = backoff
 	}
 }
 
-func NewListFunc[V any](listFunc ListFunc[V]) *Paginator[V] {
-	return &Paginator[V]{
+func New[V any](listFunc ListFunc[V], opts ...Option) *Paginator[V] {
+	p := &Paginator[V]{
 		listFunc: listFunc,
 	}
+	for _, opt := range opts {
+		opt(&p.options)
+	}
+	return p
+}
+
+func NewListFunc[V any](listFunc ListFunc[V], opts ...Option) *Paginator[V] {
+	return New(listFunc, opts...)
 }
 
 type Paginator[V any] struct {
 	listFunc ListFunc[V]
+	options  options
 }
 
 func (p *Paginator[V]) ListFunc(listFunc ListFunc[V]) *Paginator[V] {
@@ -31,7 +54,9 @@ func (p *Paginator[V]) Get(ctx context.Context) ([]V, error) {
 	result := make([]V, 0)
 	var cont string
 	for {
-		items, cont, err := p.listFunc(ctx, cont)
+		var items []V
+		var err error
+		items, cont, err = p.list(ctx, cont)
 		if err != nil {
 			return result, err
 		}
@@ -42,3 +67,28 @@ func (p *Paginator[V]) Get(ctx context.Context) ([]V, error) {
 	}
 	return result, nil
 }
+
+// list fetches a single page, retrying transient errors according to the retry options.
+func (p *Paginator[V]) list(ctx context.Context, cont string) ([]V, string, error) {
+	backoff := p.options.backoff
+	for attempt := 0; ; attempt++ {
+		items, next, err := p.listFunc(ctx, cont)
+		if err == nil || attempt >= p.options.maxRetries || !isRetryable(err) {
+			return items, next, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, "", ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func isRetryable(err error) bool {
+	return apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsServiceUnavailable(err) ||
+		apierrors.IsInternalError(err)
+}
@@ -0,0 +1,114 @@
+package k8spaginator_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/sapslaj/eks-pricing-exporter/pkg/k8spaginator"
+)
+
+func TestPaginatorMultiplePages(t *testing.T) {
+	pages := map[string]struct {
+		items []string
+		next  string
+	}{
+		"":   {items: []string{"a", "b"}, next: "p2"},
+		"p2": {items: []string{"c", "d"}, next: "p3"},
+		"p3": {items: []string{"e"}, next: ""},
+	}
+	var conts []string
+	p := k8spaginator.New(func(_ context.Context, cont string) ([]string, string, error) {
+		conts = append(conts, cont)
+		page, ok := pages[cont]
+		if !ok {
+			t.Fatalf("unexpected continue token %q", cont)
+		}
+		return page.items, page.next, nil
+	})
+
+	result, err := p.Get(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp, got := []string{"a", "b", "c", "d", "e"}, result; !reflect.DeepEqual(exp, got) {
+		t.Errorf("expected items %v, got %v", exp, got)
+	}
+	if exp, got := []string{"", "p2", "p3"}, conts; !reflect.DeepEqual(exp, got) {
+		t.Errorf("expected continue tokens %v, got %v", exp, got)
+	}
+}
+
+func TestPaginatorRetry(t *testing.T) {
+	var conts []string
+	failed := false
+	p := k8spaginator.New(func(_ context.Context, cont string) ([]string, string, error) {
+		conts = append(conts, cont)
+		if cont == "" {
+			return []string{"a", "b"}, "p2", nil
+		}
+		// the second page fails once, the retry must ask for it again rather than start over
+		if !failed {
+			failed = true
+			return nil, "", apierrors.NewTooManyRequests("slow down", 1)
+		}
+		return []string{"c"}, "", nil
+	}, k8spaginator.WithRetry(3, time.Millisecond))
+
+	result, err := p.Get(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp, got := []string{"a", "b", "c"}, result; !reflect.DeepEqual(exp, got) {
+		t.Errorf("expected items %v, got %v", exp, got)
+	}
+	if exp, got := []string{"", "p2", "p2"}, conts; !reflect.DeepEqual(exp, got) {
+		t.Errorf("expected continue tokens %v, got %v", exp, got)
+	}
+}
+
+func TestPaginatorNoRetryByDefault(t *testing.T) {
+	calls := 0
+	p := k8spaginator.New(func(_ context.Context, _ string) ([]string, string, error) {
+		calls++
+		return nil, "", apierrors.NewTooManyRequests("slow down", 1)
+	})
+
+	if _, err := p.Get(context.Background()); err == nil {
+		t.Fatalf("expected an error")
+	}
+	if exp, got := 1, calls; exp != got {
+		t.Errorf("expected %d calls, got %d", exp, got)
+	}
+}
+
+func TestPaginatorRetryNonRetryableError(t *testing.T) {
+	calls := 0
+	p := k8spaginator.New(func(_ context.Context, _ string) ([]string, string, error) {
+		calls++
+		return nil, "", errors.New("forbidden")
+	}, k8spaginator.WithRetry(3, time.Millisecond))
+
+	if _, err := p.Get(context.Background()); err == nil {
+		t.Fatalf("expected an error")
+	}
+	if exp, got := 1, calls; exp != got {
+		t.Errorf("expected %d calls, got %d", exp, got)
+	}
+}
+
+func TestPaginatorRetryContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := k8spaginator.New(func(_ context.Context, _ string) ([]string, string, error) {
+		cancel()
+		return nil, "", apierrors.NewTooManyRequests("slow down", 1)
+	}, k8spaginator.WithRetry(3, time.Hour))
+
+	if _, err := p.Get(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
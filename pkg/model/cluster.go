@@ -16,8 +16,10 @@ package model
 
 import (
 	"context"
+	"fmt"
 	"sort"
 	"sync"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -26,22 +28,90 @@ import (
 	"github.com/sapslaj/eks-pricing-exporter/pkg/k8spaginator"
 )
 
+const (
+	listMaxRetries   = 3
+	listRetryBackoff = time.Second
+)
+
 type Cluster struct {
-	mu        sync.RWMutex
-	nodes     map[string]*Node
-	pods      map[objectKey]*Pod
-	resources []v1.ResourceName
+	mu         sync.RWMutex
+	nodes      map[string]*Node
+	pods       map[objectKey]*Pod
+	resources  []v1.ResourceName
+	podBinding PodBindingStrategy
+	nodeOpts   []NodeOption
+}
+
+// PodBindingStrategy decides which scheduled pods are bound to their node and so count towards the resources used on
+// the node and the attribution of its price.
+type PodBindingStrategy string
+
+const (
+	// PodBindingActive binds only pending and running pods, since completed pods no longer reserve resources.
+	PodBindingActive PodBindingStrategy = "active"
+	// PodBindingAll binds every scheduled pod regardless of its phase.
+	PodBindingAll PodBindingStrategy = "all"
+)
+
+func (s PodBindingStrategy) String() string {
+	return string(s)
+}
+
+// Binds returns true if the pod should be bound to its node under the strategy.
+func (s PodBindingStrategy) Binds(pod *Pod) bool {
+	if s == PodBindingAll {
+		return true
+	}
+	phase := pod.Phase()
+	return phase == v1.PodPending || phase == v1.PodRunning
+}
+
+// ParsePodBindingStrategy returns the PodBindingStrategy with the given name.
+func ParsePodBindingStrategy(name string) (PodBindingStrategy, error) {
+	switch s := PodBindingStrategy(name); s {
+	case PodBindingActive, PodBindingAll:
+		return s, nil
+	default:
+		return "", fmt.Errorf(
+			"unknown pod binding strategy %q, must be one of %s or %s",
+			name,
+			PodBindingActive,
+			PodBindingAll,
+		)
+	}
+}
+
+// ClusterOption configures optional behavior of the Cluster.
+type ClusterOption func(*Cluster)
+
+// WithPodBindingStrategy sets which pods are bound to their node, defaults to PodBindingActive.
+func WithPodBindingStrategy(strategy PodBindingStrategy) ClusterOption {
+	return func(c *Cluster) {
+		c.podBinding = strategy
+	}
+}
+
+// WithNodeOptions configures the nodes added by Populate with opts.
+func WithNodeOptions(opts ...NodeOption) ClusterOption {
+	return func(c *Cluster) {
+		c.nodeOpts = append(c.nodeOpts, opts...)
+	}
 }
 
-func NewCluster() *Cluster {
-	return &Cluster{
-		nodes:     map[string]*Node{},
-		pods:      map[objectKey]*Pod{},
-		resources: []v1.ResourceName{v1.ResourceCPU},
+func NewCluster(opts ...ClusterOption) *Cluster {
+	c := &Cluster{
+		nodes:      map[string]*Node{},
+		pods:       map[objectKey]*Pod{},
+		resources:  []v1.ResourceName{v1.ResourceCPU},
+		podBinding: PodBindingActive,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
-func (c *Cluster) Populate(ctx context.Context, cs *kubernetes.Clientset) error {
+func (c *Cluster) Populate(ctx context.Context, cs kubernetes.Interface) error {
 	pods, err := k8spaginator.NewListFunc(func(ctx context.Context, cont string) ([]v1.Pod, string, error) {
 		r, err := cs.CoreV1().Pods("").List(ctx, metav1.ListOptions{
 			Continue: cont,
@@ -50,7 +120,7 @@ func (c *Cluster) Populate(ctx context.Context, cs *kubernetes.Clientset) error
 			return nil, "", err
 		}
 		return r.Items, r.Continue, nil
-	}).Get(ctx)
+	}, k8spaginator.WithRetry(listMaxRetries, listRetryBackoff)).Get(ctx)
 	if err != nil {
 		return err
 	}
@@ -59,7 +129,20 @@ func (c *Cluster) Populate(ctx context.Context, cs *kubernetes.Clientset) error
 		c.AddPod(NewPod(&pod))
 	}
 
-	nodes, err := k8spaginator.NewListFunc(func(ctx context.Context, cont string) ([]v1.Node, string, error) {
+	nodes, err := listNodes(ctx, cs)
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		node := node
+		c.AddNode(NewNode(&node, c.nodeOpts...))
+	}
+
+	return nil
+}
+
+func listNodes(ctx context.Context, cs kubernetes.Interface) ([]v1.Node, error) {
+	return k8spaginator.NewListFunc(func(ctx context.Context, cont string) ([]v1.Node, string, error) {
 		r, err := cs.CoreV1().Nodes().List(ctx, metav1.ListOptions{
 			Continue: cont,
 		})
@@ -67,16 +150,26 @@ func (c *Cluster) Populate(ctx context.Context, cs *kubernetes.Clientset) error
 			return nil, "", err
 		}
 		return r.Items, r.Continue, nil
-	}).Get(ctx)
+	}, k8spaginator.WithRetry(listMaxRetries, listRetryBackoff)).Get(ctx)
+}
+
+// NodeZones returns the sorted zones of the nodes in the cluster.
+func NodeZones(ctx context.Context, cs kubernetes.Interface) ([]string, error) {
+	nodes, err := listNodes(ctx, cs)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	seen := map[string]bool{}
+	var zones []string
 	for _, node := range nodes {
 		node := node
-		c.AddNode(NewNode(&node))
+		if zone := NewNode(&node).Zone(); zone != "" && !seen[zone] {
+			seen[zone] = true
+			zones = append(zones, zone)
+		}
 	}
-
-	return nil
+	sort.Strings(zones)
+	return zones, nil
 }
 
 func (c *Cluster) AddNode(node *Node) *Node {
@@ -130,6 +223,13 @@ func (c *Cluster) AddPod(pod *Pod) (totalPods int) {
 	if !pod.IsScheduled() {
 		return
 	}
+	if !c.podBinding.Binds(pod) {
+		// the pod may have been bound before it completed
+		if n, ok := c.GetNode(pod.NodeName()); ok {
+			n.DeletePod(pod.Namespace(), pod.Name())
+		}
+		return
+	}
 	n, ok := c.GetNode(pod.NodeName())
 	if !ok {
 		// node doesn't exist so we need to create it first to have somewhere to record the pod, it will be updated
@@ -0,0 +1,93 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/sapslaj/eks-pricing-exporter/pkg/model"
+)
+
+func testClusterPod(name string, phase v1.PodPhase) *model.Pod {
+	pod := testPod("default", name)
+	pod.Spec.NodeName = "mynode"
+	pod.Status.Phase = phase
+	return model.NewPod(pod)
+}
+
+func TestClusterCompletedPodNotBound(t *testing.T) {
+	c := model.NewCluster()
+	c.AddNode(model.NewNode(testNode("mynode")))
+	c.AddPod(testClusterPod("pending", v1.PodPending))
+	c.AddPod(testClusterPod("running", v1.PodRunning))
+	c.AddPod(testClusterPod("succeeded", v1.PodSucceeded))
+	c.AddPod(testClusterPod("failed", v1.PodFailed))
+
+	n, ok := c.GetNode("mynode")
+	if !ok {
+		t.Fatal("expected node to exist")
+	}
+	if exp, got := resource.MustParse("2"), n.Used()[v1.ResourceCPU]; exp.Cmp(got) != 0 {
+		t.Errorf("expected used CPU = %s, got %s", exp.String(), got.String())
+	}
+	if exp, got := 2, len(n.Pods()); exp != got {
+		t.Errorf("expected %d bound pods, got %d", exp, got)
+	}
+}
+
+func TestClusterPodCompletesAfterBinding(t *testing.T) {
+	c := model.NewCluster()
+	c.AddNode(model.NewNode(testNode("mynode")))
+	c.AddPod(testClusterPod("job", v1.PodRunning))
+	c.AddPod(testClusterPod("job", v1.PodSucceeded))
+
+	n, _ := c.GetNode("mynode")
+	if got := n.Used()[v1.ResourceCPU]; !got.IsZero() {
+		t.Errorf("expected used CPU = 0, got %s", got.String())
+	}
+	if exp, got := 0, len(n.Pods()); exp != got {
+		t.Errorf("expected %d bound pods, got %d", exp, got)
+	}
+}
+
+func TestClusterPodBindingAll(t *testing.T) {
+	c := model.NewCluster(model.WithPodBindingStrategy(model.PodBindingAll))
+	c.AddNode(model.NewNode(testNode("mynode")))
+	c.AddPod(testClusterPod("running", v1.PodRunning))
+	c.AddPod(testClusterPod("succeeded", v1.PodSucceeded))
+
+	n, _ := c.GetNode("mynode")
+	if exp, got := resource.MustParse("2"), n.Used()[v1.ResourceCPU]; exp.Cmp(got) != 0 {
+		t.Errorf("expected used CPU = %s, got %s", exp.String(), got.String())
+	}
+}
+
+func TestParsePodBindingStrategy(t *testing.T) {
+	for _, name := range []string{"active", "all"} {
+		s, err := model.ParsePodBindingStrategy(name)
+		if err != nil {
+			t.Errorf("unexpected error parsing %q: %s", name, err)
+		}
+		if s.String() != name {
+			t.Errorf("expected %s, got %s", name, s)
+		}
+	}
+	if _, err := model.ParsePodBindingStrategy("bogus"); err == nil {
+		t.Error("expected error parsing unknown strategy")
+	}
+}
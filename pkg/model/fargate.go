@@ -0,0 +1,60 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+// fargateMemoryOverheadGB is the memory Fargate adds to the memory requested by a pod for the Kubernetes components
+// running alongside it, before rounding up to a configuration.
+const fargateMemoryOverheadGB = 0.25
+
+// fargateConfiguration is a vCPU count along with the memory sizes in GB available with it on Fargate.
+type fargateConfiguration struct {
+	vCPU     float64
+	memoryGB []float64
+}
+
+// fargateConfigurations are the vCPU and memory combinations Fargate provisions pods with, from smallest to largest.
+var fargateConfigurations = []fargateConfiguration{
+	{vCPU: 0.25, memoryGB: []float64{0.5, 1, 2}},
+	{vCPU: 0.5, memoryGB: memoryRange(1, 4, 1)},
+	{vCPU: 1, memoryGB: memoryRange(2, 8, 1)},
+	{vCPU: 2, memoryGB: memoryRange(4, 16, 1)},
+	{vCPU: 4, memoryGB: memoryRange(8, 30, 1)},
+	{vCPU: 8, memoryGB: memoryRange(16, 60, 4)},
+	{vCPU: 16, memoryGB: memoryRange(32, 120, 8)},
+}
+
+func memoryRange(from, to, step float64) []float64 {
+	var sizes []float64
+	for size := from; size <= to; size += step {
+		sizes = append(sizes, size)
+	}
+	return sizes
+}
+
+// FargateConfiguration returns the vCPU and GB of memory of the smallest Fargate configuration with at least vCPU
+// vCPUs and memoryGB GB of memory, the way Fargate sizes pods. It returns false if no configuration is large enough.
+func FargateConfiguration(vCPU, memoryGB float64) (float64, float64, bool) {
+	for _, configuration := range fargateConfigurations {
+		if configuration.vCPU < vCPU {
+			continue
+		}
+		for _, size := range configuration.memoryGB {
+			if size >= memoryGB {
+				return configuration.vCPU, size, true
+			}
+		}
+	}
+	return 0, 0, false
+}
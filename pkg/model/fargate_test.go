@@ -0,0 +1,169 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/sapslaj/eks-pricing-exporter/pkg/model"
+)
+
+func TestFargateConfigurationPublishedCombinations(t *testing.T) {
+	// https://docs.aws.amazon.com/eks/latest/userguide/fargate-pod-configuration.html
+	published := map[float64][]float64{
+		0.25: {0.5, 1, 2},
+		0.5:  {1, 2, 3, 4},
+		1:    {2, 3, 4, 5, 6, 7, 8},
+		2:    {4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+		4: {
+			8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30,
+		},
+		8:  {16, 20, 24, 28, 32, 36, 40, 44, 48, 52, 56, 60},
+		16: {32, 40, 48, 56, 64, 72, 80, 88, 96, 104, 112, 120},
+	}
+	for vCPU, sizes := range published {
+		for _, memoryGB := range sizes {
+			gotVCPU, gotMemoryGB, ok := model.FargateConfiguration(vCPU, memoryGB)
+			if !ok || gotVCPU != vCPU || gotMemoryGB != memoryGB {
+				t.Errorf(
+					"expected %gvCPU %gGB to be a configuration, got %gvCPU %gGB (%v)",
+					vCPU,
+					memoryGB,
+					gotVCPU,
+					gotMemoryGB,
+					ok,
+				)
+			}
+		}
+	}
+}
+
+func TestFargateConfigurationRoundsUp(t *testing.T) {
+	for name, tc := range map[string]struct {
+		vCPU, memoryGB       float64
+		expVCPU, expMemoryGB float64
+		expOK                bool
+	}{
+		"nothing":                  {0, 0, 0.25, 0.5, true},
+		"memory between sizes":     {0.25, 1.5, 0.25, 2, true},
+		"memory over vCPU maximum": {0.25, 3, 0.5, 3, true},
+		"vCPU between sizes":       {3, 1, 4, 8, true},
+		"memory in 4GB steps":      {8, 17, 8, 20, true},
+		"memory in 8GB steps":      {16, 33, 16, 40, true},
+		"too much memory":          {16, 121, 0, 0, false},
+		"too many vCPUs":           {32, 64, 0, 0, false},
+	} {
+		vCPU, memoryGB, ok := model.FargateConfiguration(tc.vCPU, tc.memoryGB)
+		if vCPU != tc.expVCPU || memoryGB != tc.expMemoryGB || ok != tc.expOK {
+			t.Errorf(
+				"%s: expected %gvCPU %gGB (%v), got %gvCPU %gGB (%v)",
+				name,
+				tc.expVCPU,
+				tc.expMemoryGB,
+				tc.expOK,
+				vCPU,
+				memoryGB,
+				ok,
+			)
+		}
+	}
+}
+
+func TestFargateCapacityRequested(t *testing.T) {
+	requests := func(cpu, memory string) v1.ResourceRequirements {
+		return v1.ResourceRequirements{Requests: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse(cpu),
+			v1.ResourceMemory: resource.MustParse(memory),
+		}}
+	}
+	for name, tc := range map[string]struct {
+		containers     []v1.ResourceRequirements
+		initContainers []v1.ResourceRequirements
+		expVCPU        float64
+		expMemoryGB    float64
+		expOK          bool
+	}{
+		"no requests": {
+			containers:  []v1.ResourceRequirements{{}},
+			expVCPU:     0.25,
+			expMemoryGB: 0.5,
+			expOK:       true,
+		},
+		// the memory reserved for the kubernetes components pushes 1Gi over 1GB
+		"memory overhead": {
+			containers:  []v1.ResourceRequirements{requests("1", "1Gi")},
+			expVCPU:     1,
+			expMemoryGB: 2,
+			expOK:       true,
+		},
+		"containers are summed": {
+			containers:  []v1.ResourceRequirements{requests("500m", "1Gi"), requests("250m", "2Gi")},
+			expVCPU:     1,
+			expMemoryGB: 4,
+			expOK:       true,
+		},
+		"largest init container": {
+			containers:     []v1.ResourceRequirements{requests("250m", "256Mi")},
+			initContainers: []v1.ResourceRequirements{requests("2", "1Gi"), requests("100m", "3Gi")},
+			expVCPU:        2,
+			expMemoryGB:    4,
+			expOK:          true,
+		},
+		"too large": {
+			containers: []v1.ResourceRequirements{requests("32", "64Gi")},
+			expOK:      false,
+		},
+	} {
+		pod := testPod("default", "mypod")
+		pod.Spec.Containers = nil
+		for _, resources := range tc.containers {
+			pod.Spec.Containers = append(pod.Spec.Containers, v1.Container{Name: "container", Resources: resources})
+		}
+		for _, resources := range tc.initContainers {
+			pod.Spec.InitContainers = append(pod.Spec.InitContainers, v1.Container{Name: "init", Resources: resources})
+		}
+		vCPU, memoryGB, ok := model.NewPod(pod).FargateCapacityRequested()
+		if vCPU != tc.expVCPU || memoryGB != tc.expMemoryGB || ok != tc.expOK {
+			t.Errorf(
+				"%s: expected %gvCPU %gGB (%v), got %gvCPU %gGB (%v)",
+				name,
+				tc.expVCPU,
+				tc.expMemoryGB,
+				tc.expOK,
+				vCPU,
+				memoryGB,
+				ok,
+			)
+		}
+	}
+}
+
+func TestFargateCapacityPrefersProvisioned(t *testing.T) {
+	pod := testPod("default", "mypod")
+	pod.Annotations = map[string]string{"CapacityProvisioned": "2vCPU 4GB"}
+	vCPU, memoryGB, ok := model.NewPod(pod).FargateCapacity()
+	if !ok || vCPU != 2 || memoryGB != 4 {
+		t.Errorf("expected the provisioned 2vCPU 4GB, got %gvCPU %gGB (%v)", vCPU, memoryGB, ok)
+	}
+
+	// without the annotation the 1 vCPU and 1Gi requested by testPod are rounded up
+	vCPU, memoryGB, ok = model.NewPod(testPod("default", "mypod")).FargateCapacity()
+	if !ok || vCPU != 1 || memoryGB != 2 {
+		t.Errorf("expected the requested 1vCPU 2GB, got %gvCPU %gGB (%v)", vCPU, memoryGB, ok)
+	}
+}
@@ -17,6 +17,8 @@ package model
 import (
 	"fmt"
 	"math"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -36,6 +38,42 @@ type Node struct {
 	pods    map[objectKey]*Pod
 	used    v1.ResourceList
 	Price   float64
+	// PriceReason describes which lookup the price came from, or why no price was found.
+	PriceReason string
+	// PriceSource is where the price came from, NodePriceSourceNone if no price was found.
+	PriceSource NodePriceSource
+	// capacityTypeLabels and defaultCapacityType detect the capacity type of nodes without the EKS or Karpenter
+	// capacity type labels, e.g. self-managed nodes.
+	capacityTypeLabels  []string
+	defaultCapacityType NodeCapacityType
+	// hybridHourlyPrice is the flat hourly price of EKS Hybrid Nodes, which run outside of EC2.
+	hybridHourlyPrice float64
+	// priceResolvers replaces the DefaultPriceResolvers if set.
+	priceResolvers []PriceResolver
+}
+
+// PriceOverrideAnnotation is the node annotation which, if set to a valid hourly price, is used as the price of the
+// node instead of looking it up, e.g. for nodes on a custom contract.
+const PriceOverrideAnnotation = "pricing.sapslaj.com/hourly-price"
+
+// LicenseModelKey is the node annotation or label which sets the license model of the operating system of the node,
+// e.g. "byol" or "rhel", to price on-demand nodes with or without an operating system license fee.
+const LicenseModelKey = "pricing.sapslaj.com/license-model"
+
+type NodePriceSource string
+
+const (
+	NodePriceSourceNone          NodePriceSource = "none"
+	NodePriceSourceAnnotation    NodePriceSource = "annotation"
+	NodePriceSourceCapacityBlock NodePriceSource = "capacity-block"
+	NodePriceSourceOnDemand      NodePriceSource = "on-demand"
+	NodePriceSourceSpot          NodePriceSource = "spot"
+	NodePriceSourceFargate       NodePriceSource = "fargate"
+	NodePriceSourceHybrid        NodePriceSource = "hybrid"
+)
+
+func (nps NodePriceSource) String() string {
+	return string(nps)
 }
 
 type NodeCapacityType string
@@ -45,12 +83,37 @@ const (
 	NodeOnDemand            NodeCapacityType = "on-demand"
 	NodeSpot                NodeCapacityType = "spot"
 	NodeFargate             NodeCapacityType = "fargate"
+	NodeCapacityBlock       NodeCapacityType = "capacity-block"
+	NodeHybrid              NodeCapacityType = "hybrid"
 )
 
 func (nct NodeCapacityType) String() string {
 	return string(nct)
 }
 
+// ParseCapacityType returns the on-demand or spot NodeCapacityType with the given name.
+func ParseCapacityType(name string) (NodeCapacityType, error) {
+	switch ct := NodeCapacityType(name); ct {
+	case NodeOnDemand, NodeSpot:
+		return ct, nil
+	default:
+		return "", fmt.Errorf("unknown capacity type %q, must be one of %s or %s", name, NodeOnDemand, NodeSpot)
+	}
+}
+
+// capacityTypeLabelValue returns the capacity type of the value of a custom capacity type label, accepting the values
+// used by common node provisioners, e.g. "spot" or "Ec2Spot" and "on-demand" or "normal".
+func capacityTypeLabelValue(value string) (NodeCapacityType, bool) {
+	switch strings.ToLower(value) {
+	case "on-demand", "ondemand", "on_demand", "normal":
+		return NodeOnDemand, true
+	case "spot", "ec2spot":
+		return NodeSpot, true
+	default:
+		return NodeUnknownCapacityType, false
+	}
+}
+
 type NodeStatus string
 
 const (
@@ -65,37 +128,141 @@ func (ns NodeStatus) String() string {
 	return string(ns)
 }
 
-func NewNode(n *v1.Node) *Node {
+type NodeOSDistribution string
+
+const (
+	NodeOSDistributionUnknown      NodeOSDistribution = "unknown"
+	NodeOSDistributionBottlerocket NodeOSDistribution = "bottlerocket"
+	NodeOSDistributionAmazonLinux  NodeOSDistribution = "amazon-linux"
+	NodeOSDistributionUbuntu       NodeOSDistribution = "ubuntu"
+	NodeOSDistributionWindows      NodeOSDistribution = "windows"
+)
+
+func (nod NodeOSDistribution) String() string {
+	return string(nod)
+}
+
+// NodeOption configures optional behavior of the Node.
+type NodeOption func(*Node)
+
+// WithCapacityTypeLabels reads the capacity type of nodes without the EKS or Karpenter capacity type labels from the
+// first of labels which is set to a known capacity type, e.g. node.kubernetes.io/lifecycle on self-managed nodes.
+func WithCapacityTypeLabels(labels ...string) NodeOption {
+	return func(n *Node) {
+		n.capacityTypeLabels = labels
+	}
+}
+
+// WithDefaultCapacityType sets the capacity type of nodes without any capacity type label, e.g. NodeOnDemand for
+// self-managed nodes which are all on-demand.
+func WithDefaultCapacityType(capacityType NodeCapacityType) NodeOption {
+	return func(n *Node) {
+		n.defaultCapacityType = capacityType
+	}
+}
+
+// WithHybridHourlyPrice sets the flat hourly price of EKS Hybrid Nodes, e.g. the amortized cost of the on-premises
+// hardware. They have no EC2 price, so are priced at 0 by default.
+func WithHybridHourlyPrice(price float64) NodeOption {
+	return func(n *Node) {
+		n.hybridHourlyPrice = price
+	}
+}
+
+func NewNode(n *v1.Node, opts ...NodeOption) *Node {
 	node := &Node{
 		node: *n,
 		pods: map[objectKey]*Pod{},
 		used: v1.ResourceList{},
 	}
+	for _, opt := range opts {
+		opt(node)
+	}
 
 	return node
 }
 
+// Copy returns a copy of the node, sharing its pods, which can be priced without affecting the node.
+func (n *Node) Copy() *Node {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	pods := make(map[objectKey]*Pod, len(n.pods))
+	for key, pod := range n.pods {
+		pods[key] = pod
+	}
+	return &Node{
+		visible:             n.visible,
+		node:                *n.node.DeepCopy(),
+		pods:                pods,
+		used:                n.used.DeepCopy(),
+		Price:               n.Price,
+		PriceReason:         n.PriceReason,
+		PriceSource:         n.PriceSource,
+		capacityTypeLabels:  n.capacityTypeLabels,
+		defaultCapacityType: n.defaultCapacityType,
+		hybridHourlyPrice:   n.hybridHourlyPrice,
+		priceResolvers:      n.priceResolvers,
+	}
+}
+
+// customCapacityType returns the capacity type of a node without the EKS or Karpenter capacity type labels from the
+// custom capacity type labels, or else the default capacity type.
+func (n *Node) customCapacityType() NodeCapacityType {
+	for _, label := range []string{
+		"karpenter.sh/capacity-type",
+		"eks.amazonaws.com/capacityType",
+		"eks.amazonaws.com/compute-type",
+	} {
+		if _, ok := n.node.Labels[label]; ok {
+			return NodeUnknownCapacityType
+		}
+	}
+	for _, label := range n.capacityTypeLabels {
+		if capacityType, ok := capacityTypeLabelValue(n.node.Labels[label]); ok {
+			return capacityType
+		}
+	}
+	return n.defaultCapacityType
+}
+
+// IsCapacityBlock returns true if the node is backed by an EC2 Capacity Block for ML reservation.
+func (n *Node) IsCapacityBlock() bool {
+	return n.node.Labels["karpenter.k8s.aws/capacity-reservation-type"] == "capacity-block" ||
+		n.node.Labels["eks.amazonaws.com/capacityType"] == "CAPACITY_BLOCK"
+}
+
 func (n *Node) IsOnDemand() bool {
 	return n.node.Labels["karpenter.sh/capacity-type"] == "on-demand" ||
-		n.node.Labels["eks.amazonaws.com/capacityType"] == "ON_DEMAND"
+		n.node.Labels["eks.amazonaws.com/capacityType"] == "ON_DEMAND" ||
+		n.customCapacityType() == NodeOnDemand
 }
 
 func (n *Node) IsSpot() bool {
 	return n.node.Labels["karpenter.sh/capacity-type"] == "spot" ||
-		n.node.Labels["eks.amazonaws.com/capacityType"] == "SPOT"
+		n.node.Labels["eks.amazonaws.com/capacityType"] == "SPOT" ||
+		n.customCapacityType() == NodeSpot
 }
 
 func (n *Node) IsFargate() bool {
 	return n.node.Labels["eks.amazonaws.com/compute-type"] == "fargate"
 }
 
+// IsHybrid returns true if the node is an EKS Hybrid Node running on on-premises or edge infrastructure.
+func (n *Node) IsHybrid() bool {
+	return n.node.Labels["eks.amazonaws.com/compute-type"] == "hybrid"
+}
+
 func (n *Node) CapacityType() NodeCapacityType {
-	if n.IsOnDemand() {
+	if n.IsCapacityBlock() {
+		return NodeCapacityBlock
+	} else if n.IsOnDemand() {
 		return NodeOnDemand
 	} else if n.IsSpot() {
 		return NodeSpot
 	} else if n.IsFargate() {
 		return NodeFargate
+	} else if n.IsHybrid() {
+		return NodeHybrid
 	} else {
 		return NodeUnknownCapacityType
 	}
@@ -184,6 +351,14 @@ func (n *Node) Cordoned() bool {
 	return n.node.Spec.Unschedulable
 }
 
+// Taints returns the taints of the node.
+func (n *Node) Taints() []v1.Taint {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	// shouldn't be modified so it's safe to return
+	return n.node.Spec.Taints
+}
+
 func (n *Node) Ready() bool {
 	n.mu.RLock()
 	defer n.mu.RUnlock()
@@ -206,7 +381,7 @@ func (n *Node) InstanceType() string {
 	defer n.mu.RUnlock()
 	if n.IsFargate() {
 		if len(n.Pods()) == 1 {
-			cpu, mem, ok := n.Pods()[0].FargateCapacityProvisioned()
+			cpu, mem, ok := n.Pods()[0].FargateCapacity()
 			if ok {
 				return fmt.Sprintf("%gvCPU-%gGB", cpu, mem)
 			}
@@ -216,16 +391,127 @@ func (n *Node) InstanceType() string {
 	return n.node.Labels[v1.LabelInstanceTypeStable]
 }
 
+// InstanceFamily returns the family of the instance type of the node, e.g. "m5" for "m5.large". Fargate nodes have
+// the family "fargate" since their instance type is synthetic. Returns an empty string if the instance type is unknown.
+func (n *Node) InstanceFamily() string {
+	if n.IsFargate() {
+		return "fargate"
+	}
+	family, _, _ := strings.Cut(n.InstanceType(), ".")
+	return family
+}
+
+// Zone returns the zone of the node from the topology label, falling back to the deprecated failure-domain label
+// still set on older clusters and then to the provider ID.
 func (n *Node) Zone() string {
 	n.mu.RLock()
 	defer n.mu.RUnlock()
-	return n.node.Labels[v1.LabelTopologyZone]
+	if zone, ok := n.node.Labels[v1.LabelTopologyZone]; ok {
+		return zone
+	}
+	if zone, ok := n.node.Labels[v1.LabelFailureDomainBetaZone]; ok {
+		return zone
+	}
+	zone, _ := parseProviderID(n.node.Spec.ProviderID)
+	return zone
+}
+
+// InstanceID returns the EC2 instance ID of the node from its provider ID, or an empty string.
+func (n *Node) InstanceID() string {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	_, instanceID := parseProviderID(n.node.Spec.ProviderID)
+	return instanceID
+}
+
+// SetInstanceInfo fills in the instance type and zone of a node which is missing the labels for them, e.g. from a
+// DescribeInstances lookup. Existing labels are left as is.
+func (n *Node) SetInstanceInfo(instanceType string, zone string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.node.Labels == nil {
+		n.node.Labels = map[string]string{}
+	}
+	if _, ok := n.node.Labels[v1.LabelInstanceTypeStable]; !ok && instanceType != "" {
+		n.node.Labels[v1.LabelInstanceTypeStable] = instanceType
+	}
+	if _, ok := n.node.Labels[v1.LabelTopologyZone]; !ok && zone != "" {
+		n.node.Labels[v1.LabelTopologyZone] = zone
+	}
 }
 
+// parseProviderID returns the zone and instance ID from an AWS provider ID of the form aws:///us-east-1a/i-xxxx.
+func parseProviderID(providerID string) (string, string) {
+	if !strings.HasPrefix(providerID, "aws://") {
+		return "", ""
+	}
+	parts := strings.Split(strings.TrimPrefix(providerID, "aws://"), "/")
+	if len(parts) < 2 || !strings.HasPrefix(parts[len(parts)-1], "i-") {
+		return "", ""
+	}
+	return parts[len(parts)-2], parts[len(parts)-1]
+}
+
+// Region returns the region of the node from the topology label, falling back to the deprecated failure-domain label
+// still set on older clusters.
 func (n *Node) Region() string {
 	n.mu.RLock()
 	defer n.mu.RUnlock()
-	return n.node.Labels[v1.LabelTopologyRegion]
+	if region, ok := n.node.Labels[v1.LabelTopologyRegion]; ok {
+		return region
+	}
+	return n.node.Labels[v1.LabelFailureDomainBetaRegion]
+}
+
+// NodePool returns the Karpenter NodePool which launched the node, or the Provisioner for nodes launched by Karpenter
+// versions before NodePools, or an empty string if it wasn't launched by Karpenter.
+func (n *Node) NodePool() string {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if nodePool, ok := n.node.Labels["karpenter.sh/nodepool"]; ok {
+		return nodePool
+	}
+	return n.node.Labels["karpenter.sh/provisioner-name"]
+}
+
+// OSImage returns the OS image reported by the kubelet, e.g. "Bottlerocket OS 1.19.2 (aws-k8s-1.28)".
+func (n *Node) OSImage() string {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.node.Status.NodeInfo.OSImage
+}
+
+// Architecture returns the CPU architecture of the node from its architecture label, e.g. "arm64" or "amd64", or an
+// empty string if it is unknown.
+func (n *Node) Architecture() string {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.node.Labels[v1.LabelArchStable]
+}
+
+// IsWindows returns true if the node runs Windows according to its OS label or image.
+func (n *Node) IsWindows() bool {
+	n.mu.RLock()
+	osLabel := n.node.Labels[v1.LabelOSStable]
+	n.mu.RUnlock()
+	return osLabel == "windows" || n.OSDistribution() == NodeOSDistributionWindows
+}
+
+// OSDistribution returns the OS distribution of the node derived from its OS image.
+func (n *Node) OSDistribution() NodeOSDistribution {
+	osImage := strings.ToLower(n.OSImage())
+	switch {
+	case strings.Contains(osImage, "bottlerocket"):
+		return NodeOSDistributionBottlerocket
+	case strings.Contains(osImage, "amazon linux"):
+		return NodeOSDistributionAmazonLinux
+	case strings.Contains(osImage, "ubuntu"):
+		return NodeOSDistributionUbuntu
+	case strings.Contains(osImage, "windows"):
+		return NodeOSDistributionWindows
+	default:
+		return NodeOSDistributionUnknown
+	}
 }
 
 func (n *Node) NumPods() int {
@@ -266,28 +552,374 @@ func (n *Node) Pods() []*Pod {
 	return pods
 }
 
+// IsEmpty returns true if the node is only running system pods (see Pod.IsSystem) and so is a candidate for scaling
+// down. Fargate nodes are never empty as they only exist to run a single pod.
+func (n *Node) IsEmpty() bool {
+	if n.IsFargate() {
+		return false
+	}
+	for _, p := range n.Pods() {
+		if !p.IsSystem() {
+			return false
+		}
+	}
+	return true
+}
+
 func (n *Node) HasPrice() bool {
 	// we use NaN for an unknown price, so if this is true the price is known
 	return n.Price == n.Price
 }
 
+// PriceOverride returns the hourly price set by the PriceOverrideAnnotation, returning false if the annotation is
+// missing or not a valid price.
+func (n *Node) PriceOverride() (float64, bool) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	value, ok := n.node.Annotations[PriceOverrideAnnotation]
+	if !ok {
+		return 0, false
+	}
+	price, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || math.IsNaN(price) || math.IsInf(price, 0) || price < 0 {
+		return 0, false
+	}
+	return price, true
+}
+
+// LicenseModel returns the license model set by the LicenseModelKey annotation or else label, defaulting to
+// pricing.LicenseModelNone if neither is set to a valid license model.
+func (n *Node) LicenseModel() pricing.LicenseModel {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	value, ok := n.node.Annotations[LicenseModelKey]
+	if !ok {
+		value = n.node.Labels[LicenseModelKey]
+	}
+	licenseModel, err := pricing.ParseLicenseModel(value)
+	if err != nil {
+		return pricing.LicenseModelNone
+	}
+	return licenseModel
+}
+
+// UpdatePrice sets the price of the node from the first of its price resolvers which returns a price, see
+// DefaultPriceResolvers. If none does, the reason is that of the last resolver which applied to the node.
 func (n *Node) UpdatePrice(pricingRepository *pricing.Repository) {
-	// lookup our n price
 	n.Price = math.NaN()
-	if n.IsOnDemand() {
-		if price, ok := pricingRepository.OnDemandPrice(n.InstanceType()); ok {
-			n.Price = price
-		}
-	} else if n.IsSpot() {
-		if price, ok := pricingRepository.SpotPrice(n.InstanceType(), n.Zone()); ok {
-			n.Price = price
+	n.PriceSource = NodePriceSourceNone
+	n.PriceReason = "unknown capacity type"
+	resolvers := n.priceResolvers
+	if resolvers == nil {
+		resolvers = DefaultPriceResolvers()
+	}
+	for _, resolve := range resolvers {
+		resolution, ok := resolve(n, pricingRepository)
+		if resolution.Reason != "" {
+			n.PriceReason = resolution.Reason
 		}
-	} else if n.IsFargate() && len(n.Pods()) == 1 {
-		cpu, mem, ok := n.Pods()[0].FargateCapacityProvisioned()
 		if ok {
-			if price, ok := pricingRepository.FargatePrice(cpu, mem); ok {
-				n.Price = price
-			}
+			n.Price = resolution.Price
+			n.PriceSource = resolution.Source
+			return
+		}
+	}
+}
+
+// HourlyPricePerVCPU returns the hourly price of the node divided by the number of vCPUs of its instance type,
+// returning false if either the price or the vCPU count is unknown.
+func (n *Node) HourlyPricePerVCPU(pricingRepository *pricing.Repository) (float64, bool) {
+	// fargate nodes have synthetic instance types which aren't real EC2 instance types
+	if !n.HasPrice() || n.IsFargate() {
+		return 0, false
+	}
+	spec, ok := pricingRepository.InstanceSpec(n.InstanceType())
+	if !ok || spec.VCPUs == 0 {
+		return 0, false
+	}
+	return n.Price / float64(spec.VCPUs), true
+}
+
+// HourlyPricePerWorkloadPod returns the hourly price of the node divided by the number of pods bound to it which
+// aren't system pods (see Pod.IsSystem), returning false if the price is unknown or there are no workload pods.
+func (n *Node) HourlyPricePerWorkloadPod() (float64, bool) {
+	if !n.HasPrice() {
+		return 0, false
+	}
+	workloadPods := 0
+	for _, p := range n.Pods() {
+		if !p.IsSystem() {
+			workloadPods++
+		}
+	}
+	if workloadPods == 0 {
+		return 0, false
+	}
+	return n.Price / float64(workloadPods), true
+}
+
+// fargateMinimumBillingDuration is the minimum duration Fargate bills a pod for.
+const fargateMinimumBillingDuration = time.Minute
+
+// FargateAccumulatedCost returns the cost of the pod of a Fargate node from when it started until now, its hourly
+// price prorated by its runtime with Fargate's one minute minimum, along with the pod. It returns false if the node
+// isn't a Fargate node, its price is unknown or its pod hasn't started.
+func (n *Node) FargateAccumulatedCost(now time.Time) (float64, *Pod, bool) {
+	if !n.IsFargate() || !n.HasPrice() {
+		return 0, nil, false
+	}
+	pods := n.Pods()
+	if len(pods) != 1 {
+		return 0, nil, false
+	}
+	pod := pods[0]
+	started, ok := pod.StartTime()
+	if !ok {
+		return 0, nil, false
+	}
+	runtime := now.Sub(started)
+	if runtime < fargateMinimumBillingDuration {
+		runtime = fargateMinimumBillingDuration
+	}
+	return n.Price * runtime.Hours(), pod, true
+}
+
+// PodPrice is the share of a node's price attributed to a pod.
+type PodPrice struct {
+	Pod         *Pod
+	HourlyPrice float64
+}
+
+// SystemOverhead is how the share of a node's price attributed to system pods (see Pod.IsSystem) is handled.
+type SystemOverhead string
+
+const (
+	// SystemOverheadNone attributes system pods their share of the node price like any other pod.
+	SystemOverheadNone SystemOverhead = "none"
+	// SystemOverheadProportional redistributes the share of system pods among the workload pods on the node in
+	// proportion to their own shares.
+	SystemOverheadProportional SystemOverhead = "proportional"
+	// SystemOverheadSeparate leaves system pods out of the pod prices and reports their share as the overhead of the
+	// node instead.
+	SystemOverheadSeparate SystemOverhead = "separate"
+)
+
+func (o SystemOverhead) String() string {
+	return string(o)
+}
+
+// ParseSystemOverhead returns the SystemOverhead with the given name.
+func ParseSystemOverhead(name string) (SystemOverhead, error) {
+	switch o := SystemOverhead(name); o {
+	case SystemOverheadNone, SystemOverheadProportional, SystemOverheadSeparate:
+		return o, nil
+	default:
+		return "", fmt.Errorf(
+			"unknown system overhead mode %q, must be one of %s, %s or %s",
+			name,
+			SystemOverheadNone,
+			SystemOverheadProportional,
+			SystemOverheadSeparate,
+		)
+	}
+}
+
+// AttributionBasis is which resources of a pod its share of the node price is based on.
+type AttributionBasis string
+
+const (
+	// AttributionBasisRequests attributes node prices by the resources requested by pods.
+	AttributionBasisRequests AttributionBasis = "requests"
+	// AttributionBasisLimits attributes node prices by the resource limits of pods, falling back to the request for
+	// resources without a limit.
+	AttributionBasisLimits AttributionBasis = "limits"
+)
+
+func (b AttributionBasis) String() string {
+	return string(b)
+}
+
+// ParseAttributionBasis returns the AttributionBasis with the given name.
+func ParseAttributionBasis(name string) (AttributionBasis, error) {
+	switch b := AttributionBasis(name); b {
+	case AttributionBasisRequests, AttributionBasisLimits:
+		return b, nil
+	default:
+		return "", fmt.Errorf(
+			"unknown attribution basis %q, must be one of %s or %s",
+			name,
+			AttributionBasisRequests,
+			AttributionBasisLimits,
+		)
+	}
+}
+
+// resources returns the resources of the pod to attribute by.
+func (b AttributionBasis) resources(p *Pod) v1.ResourceList {
+	if b != AttributionBasisLimits {
+		return p.Requested()
+	}
+	resources := p.Requested()
+	for rn, q := range p.Limits() {
+		resources[rn] = q
+	}
+	return resources
+}
+
+// PodAttribution configures how the price of a node is attributed to its pods. The zero value attributes by requests
+// with SystemOverheadNone.
+type PodAttribution struct {
+	Basis          AttributionBasis
+	SystemOverhead SystemOverhead
+}
+
+// PodHourlyPrices attributes the hourly price of the node to the pods bound to it. Each pod is weighted by its
+// dominant resource share, the largest fraction of any allocatable resource (including extended resources such as
+// nvidia.com/gpu) that it requests, so a pod requesting a node's only GPU bears most of a GPU node's price regardless
+// of its CPU request. Weights are scaled down if they sum to more than one so the node price is never
+// over-attributed; any remainder is idle capacity. Returns nil if the node price is unknown.
+func (n *Node) PodHourlyPrices() []PodPrice {
+	prices, _ := n.AttributePodPrices(PodAttribution{})
+	return prices
+}
+
+// AttributePodPrices attributes the hourly price of the node to the pods bound to it like PodHourlyPrices, weighting
+// pods by the resources of attribution.Basis and handling the share of system pods according to
+// attribution.SystemOverhead. The returned overhead is the price of the system pods left out of the pod prices, which
+// is only non-zero for SystemOverheadSeparate. If there are no workload pods to redistribute to,
+// SystemOverheadProportional attributes system pods their own share.
+func (n *Node) AttributePodPrices(attribution PodAttribution) ([]PodPrice, float64) {
+	if !n.HasPrice() {
+		return nil, 0
+	}
+	pods := n.Pods()
+	if n.IsFargate() {
+		// a fargate node only ever runs a single pod which is billed for the whole node
+		if len(pods) != 1 {
+			return nil, 0
 		}
+		return []PodPrice{{Pod: pods[0], HourlyPrice: n.Price}}, 0
+	}
+
+	weights, scale := podWeights(pods, n.Allocatable(), attribution.Basis)
+
+	overhead := attribution.SystemOverhead
+	if overhead != SystemOverheadProportional && overhead != SystemOverheadSeparate {
+		prices := make([]PodPrice, len(pods))
+		for i, p := range pods {
+			prices[i] = PodPrice{Pod: p, HourlyPrice: n.Price * weights[i] * scale}
+		}
+		return prices, 0
+	}
+
+	systemTotal, workloadTotal := 0.0, 0.0
+	for i, p := range pods {
+		if p.IsSystem() {
+			systemTotal += weights[i]
+		} else {
+			workloadTotal += weights[i]
+		}
+	}
+	if overhead == SystemOverheadProportional && workloadTotal == 0 {
+		attribution.SystemOverhead = SystemOverheadNone
+		return n.AttributePodPrices(attribution)
+	}
+
+	prices := make([]PodPrice, 0, len(pods))
+	for i, p := range pods {
+		if p.IsSystem() {
+			continue
+		}
+		weight := weights[i]
+		if overhead == SystemOverheadProportional {
+			weight += systemTotal * weights[i] / workloadTotal
+		}
+		prices = append(prices, PodPrice{Pod: p, HourlyPrice: n.Price * weight * scale})
+	}
+	if overhead == SystemOverheadSeparate {
+		return prices, n.Price * systemTotal * scale
+	}
+	return prices, 0
+}
+
+// podWeights returns the dominant share of the allocatable resources of each pod by the resources of basis, along with
+// the factor to scale them by so they sum to at most one.
+func podWeights(pods []*Pod, allocatable v1.ResourceList, basis AttributionBasis) ([]float64, float64) {
+	weights := make([]float64, len(pods))
+	total := 0.0
+	for i, p := range pods {
+		weights[i] = dominantShare(basis.resources(p), allocatable)
+		total += weights[i]
+	}
+	if total > 1 {
+		return weights, 1 / total
+	}
+	return weights, 1
+}
+
+// dominantShare returns the largest fraction of any allocatable resource that is requested, capped at 1.
+func dominantShare(requested v1.ResourceList, allocatable v1.ResourceList) float64 {
+	share := 0.0
+	for rn, q := range requested {
+		if rn == v1.ResourcePods {
+			continue
+		}
+		available, ok := allocatable[rn]
+		if !ok || available.IsZero() {
+			continue
+		}
+		share = math.Max(share, q.AsApproximateFloat64()/available.AsApproximateFloat64())
+	}
+	return math.Min(share, 1)
+}
+
+// ResourceNvidiaGPU is the extended resource NVIDIA GPUs are advertised as by the NVIDIA device plugin.
+const ResourceNvidiaGPU v1.ResourceName = "nvidia.com/gpu"
+
+// AdvertisedGPUs returns the number of NVIDIA GPUs the node advertises as allocatable. With time-slicing or MIG this
+// is the number of GPU replicas or partitions, which can exceed the number of physical GPUs.
+func (n *Node) AdvertisedGPUs() int64 {
+	gpus := n.Allocatable()[ResourceNvidiaGPU]
+	return gpus.Value()
+}
+
+// PhysicalGPUs returns the number of physical GPUs of the instance type of the node, returning false if its instance
+// spec is unknown.
+func (n *Node) PhysicalGPUs(pricingRepository *pricing.Repository) (int32, bool) {
+	// fargate nodes have synthetic instance types which aren't real EC2 instance types
+	if n.IsFargate() {
+		return 0, false
+	}
+	spec, ok := pricingRepository.InstanceSpec(n.InstanceType())
+	if !ok {
+		return 0, false
+	}
+	return spec.GPUs, true
+}
+
+// HourlyPricePerGPU returns the hourly price of the node divided by the number of physical GPUs of its instance type,
+// returning false if either the price or the GPU count is unknown or the instance type has no GPUs. The advertised
+// GPUs aren't used since time-slicing and MIG advertise more GPUs than the instance has.
+func (n *Node) HourlyPricePerGPU(pricingRepository *pricing.Repository) (float64, bool) {
+	if !n.HasPrice() {
+		return 0, false
+	}
+	gpus, ok := n.PhysicalGPUs(pricingRepository)
+	if !ok || gpus == 0 {
+		return 0, false
+	}
+	return n.Price / float64(gpus), true
+}
+
+// HourlyPricePerGBMemory returns the hourly price of the node divided by the memory in GiB of its instance type,
+// returning false if either the price or the memory size is unknown.
+func (n *Node) HourlyPricePerGBMemory(pricingRepository *pricing.Repository) (float64, bool) {
+	if !n.HasPrice() || n.IsFargate() {
+		return 0, false
+	}
+	spec, ok := pricingRepository.InstanceSpec(n.InstanceType())
+	if !ok || spec.MemoryMiB == 0 {
+		return 0, false
 	}
+	return n.Price / (float64(spec.MemoryMiB) / 1024), true
 }
@@ -14,14 +14,53 @@ limitations under the License.
 package model_test
 
 import (
+	"context"
+	"math"
 	"testing"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/sapslaj/eks-pricing-exporter/pkg/model"
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing"
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing/pricingtest"
 )
 
+func testRepository(t *testing.T, provider *pricingtest.Provider) *pricing.Repository {
+	t.Helper()
+	var licenseModels []pricing.LicenseModel
+	for licenseModel := range provider.Licensed {
+		licenseModels = append(licenseModels, licenseModel)
+	}
+	for licenseModel := range provider.LicensedSpot {
+		licenseModels = append(licenseModels, licenseModel)
+	}
+	var fargateRegions []string
+	for region := range provider.RegionalFargate {
+		fargateRegions = append(fargateRegions, region)
+	}
+	pr := pricing.NewRepository(
+		provider,
+		pricing.WithLicenseModels(licenseModels...),
+		pricing.WithFargateRegions(fargateRegions...),
+	)
+	ctx := context.Background()
+	for _, update := range []func(context.Context) error{
+		pr.UpdateOnDemandPricing,
+		pr.UpdateSpotPricing,
+		pr.UpdateFargatePricing,
+		pr.UpdateCapacityBlockPricing,
+		pr.UpdateInstanceSpecs,
+	} {
+		if err := update(ctx); err != nil {
+			t.Fatalf("unexpected error updating repository: %s", err)
+		}
+	}
+	return pr
+}
+
 func testNode(name string) *v1.Node {
 	n := &v1.Node{
 		ObjectMeta: metav1.ObjectMeta{
@@ -117,3 +156,821 @@ func TestNodeTypeFargate(t *testing.T) {
 		}
 	}
 }
+
+func TestNodeHourlyPricePerVCPU(t *testing.T) {
+	pr := testRepository(t, &pricingtest.Provider{
+		OnDemand: pricing.OnDemandPriceList{"m5.xlarge": 0.192},
+		InstanceSpecs: pricing.InstanceSpecList{
+			"m5.xlarge": {VCPUs: 4, MemoryMiB: 16384},
+		},
+	})
+
+	n := testNode("mynode")
+	n.Labels = map[string]string{
+		"karpenter.sh/capacity-type": "on-demand",
+		v1.LabelInstanceTypeStable:   "m5.xlarge",
+	}
+	node := model.NewNode(n)
+	node.UpdatePrice(pr)
+	pricePerVCPU, ok := node.HourlyPricePerVCPU(pr)
+	if !ok {
+		t.Fatalf("expected price per vCPU to be known")
+	}
+	if exp, got := 0.048, pricePerVCPU; exp != got {
+		t.Errorf("expected HourlyPricePerVCPU == %f, got %f", exp, got)
+	}
+}
+
+func TestNodeHourlyPricePerGPUTimeSlicing(t *testing.T) {
+	pr := testRepository(t, &pricingtest.Provider{
+		OnDemand: pricing.OnDemandPriceList{"g4dn.12xlarge": 3.912},
+		InstanceSpecs: pricing.InstanceSpecList{
+			"g4dn.12xlarge": {VCPUs: 48, MemoryMiB: 196608, GPUs: 4},
+		},
+	})
+
+	n := testNode("mynode")
+	n.Labels = map[string]string{
+		"karpenter.sh/capacity-type": "on-demand",
+		v1.LabelInstanceTypeStable:   "g4dn.12xlarge",
+	}
+	// each GPU is time-sliced into four replicas
+	n.Status.Allocatable = v1.ResourceList{
+		model.ResourceNvidiaGPU: resource.MustParse("16"),
+	}
+	node := model.NewNode(n)
+	node.UpdatePrice(pr)
+	if exp, got := int64(16), node.AdvertisedGPUs(); exp != got {
+		t.Errorf("expected AdvertisedGPUs == %d, got %d", exp, got)
+	}
+	if physical, ok := node.PhysicalGPUs(pr); !ok || physical != 4 {
+		t.Errorf("expected PhysicalGPUs == 4, got %d (%v)", physical, ok)
+	}
+	pricePerGPU, ok := node.HourlyPricePerGPU(pr)
+	if !ok {
+		t.Fatalf("expected price per GPU to be known")
+	}
+	if exp, got := 0.978, pricePerGPU; exp != got {
+		t.Errorf("expected HourlyPricePerGPU == %f, got %f", exp, got)
+	}
+}
+
+func TestNodeHourlyPricePerVCPUUnknownSpec(t *testing.T) {
+	pr := testRepository(t, &pricingtest.Provider{
+		OnDemand: pricing.OnDemandPriceList{"m5.xlarge": 0.192},
+	})
+
+	n := testNode("mynode")
+	n.Labels = map[string]string{
+		"karpenter.sh/capacity-type": "on-demand",
+		v1.LabelInstanceTypeStable:   "m5.xlarge",
+	}
+	node := model.NewNode(n)
+	node.UpdatePrice(pr)
+	if _, ok := node.HourlyPricePerVCPU(pr); ok {
+		t.Errorf("expected price per vCPU to be unknown")
+	}
+}
+
+func TestNodeOSDistribution(t *testing.T) {
+	for osImage, exp := range map[string]model.NodeOSDistribution{
+		"Bottlerocket OS 1.19.2 (aws-k8s-1.28)": model.NodeOSDistributionBottlerocket,
+		"Amazon Linux 2":                        model.NodeOSDistributionAmazonLinux,
+		"Ubuntu 22.04.3 LTS":                    model.NodeOSDistributionUbuntu,
+		"Windows Server 2019 Datacenter":        model.NodeOSDistributionWindows,
+		"":                                      model.NodeOSDistributionUnknown,
+	} {
+		n := testNode("mynode")
+		n.Status.NodeInfo.OSImage = osImage
+		node := model.NewNode(n)
+		if exp, got := osImage, node.OSImage(); exp != got {
+			t.Errorf("expected OSImage == %s, got %s", exp, got)
+		}
+		if got := node.OSDistribution(); exp != got {
+			t.Errorf("expected OSDistribution == %s for %q, got %s", exp, osImage, got)
+		}
+	}
+}
+
+func TestNodeTypeCapacityBlock(t *testing.T) {
+	pr := testRepository(t, &pricingtest.Provider{
+		OnDemand:      pricing.OnDemandPriceList{"p5.48xlarge": 98.32},
+		CapacityBlock: pricing.CapacityBlockPriceList{"p5.48xlarge": 31.464},
+	})
+
+	for _, labels := range []map[string]string{
+		{
+			"karpenter.sh/capacity-type":                  "reserved",
+			"karpenter.k8s.aws/capacity-reservation-type": "capacity-block",
+		},
+		{
+			"eks.amazonaws.com/capacityType": "CAPACITY_BLOCK",
+		},
+	} {
+		n := testNode("mynode")
+		n.Labels = labels
+		n.Labels[v1.LabelInstanceTypeStable] = "p5.48xlarge"
+		node := model.NewNode(n)
+		if !node.IsCapacityBlock() {
+			t.Errorf("expected to be capacity block")
+		}
+		if exp, got := model.NodeCapacityBlock, node.CapacityType(); exp != got {
+			t.Errorf("expected CapacityType == %s, got %s", exp, got)
+		}
+		node.UpdatePrice(pr)
+		if exp, got := 31.464, node.Price; exp != got {
+			t.Errorf("expected Price == %f, got %f", exp, got)
+		}
+	}
+}
+
+func TestNodePodHourlyPricesGPU(t *testing.T) {
+	pr := testRepository(t, &pricingtest.Provider{
+		OnDemand: pricing.OnDemandPriceList{"g5.xlarge": 1.006},
+	})
+
+	n := testNode("gpunode")
+	n.Labels = map[string]string{
+		"karpenter.sh/capacity-type": "on-demand",
+		v1.LabelInstanceTypeStable:   "g5.xlarge",
+	}
+	n.Status.Allocatable = v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("4"),
+		v1.ResourceMemory: resource.MustParse("16Gi"),
+		"nvidia.com/gpu":  resource.MustParse("1"),
+	}
+	node := model.NewNode(n)
+
+	gpuPod := testPod("default", "gpu")
+	gpuPod.Spec.Containers[0].Resources.Requests["nvidia.com/gpu"] = resource.MustParse("1")
+	node.BindPod(model.NewPod(gpuPod))
+	smallPod := testPod("default", "small")
+	smallPod.Spec.Containers[0].Resources.Requests = v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("500m"),
+		v1.ResourceMemory: resource.MustParse("512Mi"),
+	}
+	node.BindPod(model.NewPod(smallPod))
+	node.UpdatePrice(pr)
+
+	prices := map[string]float64{}
+	total := 0.0
+	for _, podPrice := range node.PodHourlyPrices() {
+		prices[podPrice.Pod.Name()] = podPrice.HourlyPrice
+		total += podPrice.HourlyPrice
+	}
+	// the gpu pod has a dominant share of 1 (gpu) and the small pod 0.125 (cpu), scaled down to fit the node price
+	if exp, got := 1.006/1.125, prices["gpu"]; math.Abs(exp-got) > 1e-9 {
+		t.Errorf("expected gpu pod price == %f, got %f", exp, got)
+	}
+	if exp, got := 1.006*0.125/1.125, prices["small"]; math.Abs(exp-got) > 1e-9 {
+		t.Errorf("expected small pod price == %f, got %f", exp, got)
+	}
+	if math.Abs(total-node.Price) > 1e-9 {
+		t.Errorf("expected pod prices to sum to node price %f, got %f", node.Price, total)
+	}
+}
+
+func TestNodePodHourlyPricesIdle(t *testing.T) {
+	pr := testRepository(t, &pricingtest.Provider{
+		OnDemand: pricing.OnDemandPriceList{"m5.xlarge": 0.192},
+	})
+
+	n := testNode("mynode")
+	n.Labels = map[string]string{
+		"karpenter.sh/capacity-type": "on-demand",
+		v1.LabelInstanceTypeStable:   "m5.xlarge",
+	}
+	n.Status.Allocatable = v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("4"),
+		v1.ResourceMemory: resource.MustParse("16Gi"),
+	}
+	node := model.NewNode(n)
+	// requests 1 cpu and 1Gi, so cpu is the dominant resource with a share of 0.25
+	node.BindPod(model.NewPod(testPod("default", "mypod")))
+	node.UpdatePrice(pr)
+
+	prices := node.PodHourlyPrices()
+	if exp, got := 1, len(prices); exp != got {
+		t.Fatalf("expected %d pod prices, got %d", exp, got)
+	}
+	if exp, got := 0.048, prices[0].HourlyPrice; math.Abs(exp-got) > 1e-9 {
+		t.Errorf("expected pod price == %f, got %f", exp, got)
+	}
+}
+
+func TestNodeProviderID(t *testing.T) {
+	n := testNode("mynode")
+	n.Spec.ProviderID = "aws:///us-east-1a/i-0123456789abcdef0"
+	node := model.NewNode(n)
+	if exp, got := "i-0123456789abcdef0", node.InstanceID(); exp != got {
+		t.Errorf("expected InstanceID == %s, got %s", exp, got)
+	}
+	if exp, got := "us-east-1a", node.Zone(); exp != got {
+		t.Errorf("expected Zone == %s, got %s", exp, got)
+	}
+	if exp, got := "", node.InstanceType(); exp != got {
+		t.Errorf("expected InstanceType == %s, got %s", exp, got)
+	}
+
+	node.SetInstanceInfo("m5.large", "us-east-1a")
+	if exp, got := "m5.large", node.InstanceType(); exp != got {
+		t.Errorf("expected InstanceType == %s, got %s", exp, got)
+	}
+}
+
+func TestNodeSelfManagedCapacityType(t *testing.T) {
+	pr := testRepository(t, &pricingtest.Provider{
+		OnDemand: pricing.OnDemandPriceList{"m5.large": 0.096},
+		Spot:     pricing.SpotPriceList{"m5.large": {"us-east-1a": 0.035}},
+	})
+	opts := []model.NodeOption{
+		model.WithCapacityTypeLabels("node.kubernetes.io/lifecycle"),
+		model.WithDefaultCapacityType(model.NodeOnDemand),
+	}
+	for name, tc := range map[string]struct {
+		labels       map[string]string
+		capacityType model.NodeCapacityType
+		price        float64
+	}{
+		"custom spot label": {
+			labels:       map[string]string{"node.kubernetes.io/lifecycle": "Ec2Spot"},
+			capacityType: model.NodeSpot,
+			price:        0.035,
+		},
+		"custom on-demand label": {
+			labels:       map[string]string{"node.kubernetes.io/lifecycle": "normal"},
+			capacityType: model.NodeOnDemand,
+			price:        0.096,
+		},
+		"no capacity type label": {
+			capacityType: model.NodeOnDemand,
+			price:        0.096,
+		},
+		"eks label takes precedence": {
+			labels: map[string]string{
+				"eks.amazonaws.com/capacityType": "SPOT",
+				"node.kubernetes.io/lifecycle":   "normal",
+			},
+			capacityType: model.NodeSpot,
+			price:        0.035,
+		},
+	} {
+		n := testNode("mynode")
+		n.Labels = map[string]string{
+			v1.LabelInstanceTypeStable: "m5.large",
+			v1.LabelTopologyZone:       "us-east-1a",
+		}
+		for k, v := range tc.labels {
+			n.Labels[k] = v
+		}
+		node := model.NewNode(n, opts...)
+		if exp, got := tc.capacityType, node.CapacityType(); exp != got {
+			t.Errorf("%s: expected capacity type == %s, got %s", name, exp, got)
+		}
+		node.UpdatePrice(pr)
+		if exp, got := tc.price, node.Price; exp != got {
+			t.Errorf("%s: expected price == %f, got %f", name, exp, got)
+		}
+	}
+
+	// without the options self-managed nodes have an unknown capacity type
+	n := testNode("mynode")
+	n.Labels = map[string]string{
+		v1.LabelInstanceTypeStable:     "m5.large",
+		"node.kubernetes.io/lifecycle": "spot",
+	}
+	if exp, got := model.NodeUnknownCapacityType, model.NewNode(n).CapacityType(); exp != got {
+		t.Errorf("expected capacity type == %q without options, got %q", exp, got)
+	}
+}
+
+func TestNodeHybrid(t *testing.T) {
+	pr := testRepository(t, &pricingtest.Provider{
+		OnDemand: pricing.OnDemandPriceList{"m5.large": 0.096},
+	})
+	n := testNode("mi-0123456789abcdef0")
+	n.Labels = map[string]string{"eks.amazonaws.com/compute-type": "hybrid"}
+
+	// the default capacity type of self-managed nodes doesn't apply to hybrid nodes
+	node := model.NewNode(n, model.WithDefaultCapacityType(model.NodeOnDemand))
+	if exp, got := model.NodeHybrid, node.CapacityType(); exp != got {
+		t.Errorf("expected capacity type == %s, got %s", exp, got)
+	}
+	node.UpdatePrice(pr)
+	if !node.HasPrice() || node.Price != 0 {
+		t.Errorf("expected hybrid node to be priced at 0 by default, got %f", node.Price)
+	}
+	if exp, got := model.NodePriceSourceHybrid, node.PriceSource; exp != got {
+		t.Errorf("expected price source == %s, got %s", exp, got)
+	}
+
+	node = model.NewNode(n, model.WithHybridHourlyPrice(0.375))
+	node.UpdatePrice(pr)
+	if exp, got := 0.375, node.Price; exp != got {
+		t.Errorf("expected price == %f, got %f", exp, got)
+	}
+}
+
+func TestNodeFailureDomainLabels(t *testing.T) {
+	pr := testRepository(t, &pricingtest.Provider{
+		Spot: pricing.SpotPriceList{"m5.large": {"us-east-1a": 0.035}},
+	})
+	n := testNode("mynode")
+	n.Labels = map[string]string{
+		"karpenter.sh/capacity-type":    "spot",
+		v1.LabelInstanceTypeStable:      "m5.large",
+		v1.LabelFailureDomainBetaZone:   "us-east-1a",
+		v1.LabelFailureDomainBetaRegion: "us-east-1",
+	}
+	node := model.NewNode(n)
+	if exp, got := "us-east-1a", node.Zone(); exp != got {
+		t.Errorf("expected Zone == %s, got %s", exp, got)
+	}
+	if exp, got := "us-east-1", node.Region(); exp != got {
+		t.Errorf("expected Region == %s, got %s", exp, got)
+	}
+	node.UpdatePrice(pr)
+	if exp, got := 0.035, node.Price; exp != got {
+		t.Errorf("expected spot price == %f, got %f", exp, got)
+	}
+
+	// the stable labels take precedence
+	n.Labels[v1.LabelTopologyZone] = "us-east-1b"
+	n.Labels[v1.LabelTopologyRegion] = "us-east-2"
+	node = model.NewNode(n)
+	if exp, got := "us-east-1b", node.Zone(); exp != got {
+		t.Errorf("expected Zone == %s, got %s", exp, got)
+	}
+	if exp, got := "us-east-2", node.Region(); exp != got {
+		t.Errorf("expected Region == %s, got %s", exp, got)
+	}
+}
+
+func TestNodeHourlyPricePerGBMemory(t *testing.T) {
+	pr := testRepository(t, &pricingtest.Provider{
+		OnDemand: pricing.OnDemandPriceList{"r5.large": 0.126},
+		InstanceSpecs: pricing.InstanceSpecList{
+			"r5.large": {VCPUs: 2, MemoryMiB: 16384},
+		},
+	})
+
+	n := testNode("mynode")
+	n.Labels = map[string]string{
+		"karpenter.sh/capacity-type": "on-demand",
+		v1.LabelInstanceTypeStable:   "r5.large",
+	}
+	node := model.NewNode(n)
+	node.UpdatePrice(pr)
+	pricePerGB, ok := node.HourlyPricePerGBMemory(pr)
+	if !ok {
+		t.Fatalf("expected price per GB to be known")
+	}
+	if exp, got := 0.007875, pricePerGB; math.Abs(exp-got) > 1e-9 {
+		t.Errorf("expected HourlyPricePerGBMemory == %f, got %f", exp, got)
+	}
+
+	n.Labels[v1.LabelInstanceTypeStable] = "r5.xlarge"
+	node = model.NewNode(n)
+	node.UpdatePrice(pr)
+	if _, ok := node.HourlyPricePerGBMemory(pr); ok {
+		t.Errorf("expected price per GB to be unknown without a spec")
+	}
+}
+
+func TestNodePriceOverrideAnnotation(t *testing.T) {
+	pr := testRepository(t, &pricingtest.Provider{
+		OnDemand: pricing.OnDemandPriceList{"m5.large": 0.096},
+	})
+	n := testNode("mynode")
+	n.Labels = map[string]string{
+		"karpenter.sh/capacity-type": "on-demand",
+		v1.LabelInstanceTypeStable:   "m5.large",
+	}
+	n.Annotations = map[string]string{
+		model.PriceOverrideAnnotation: "1.234",
+	}
+	node := model.NewNode(n)
+	node.UpdatePrice(pr)
+	if exp, got := 1.234, node.Price; exp != got {
+		t.Errorf("expected price == %f, got %f", exp, got)
+	}
+	if exp, got := model.NodePriceSourceAnnotation, node.PriceSource; exp != got {
+		t.Errorf("expected price source == %s, got %s", exp, got)
+	}
+}
+
+func TestNodePriceOverrideAnnotationInvalid(t *testing.T) {
+	pr := testRepository(t, &pricingtest.Provider{
+		OnDemand: pricing.OnDemandPriceList{"m5.large": 0.096},
+	})
+	for _, value := range []string{"", "cheap", "-1", "NaN", "+Inf"} {
+		n := testNode("mynode")
+		n.Labels = map[string]string{
+			"karpenter.sh/capacity-type": "on-demand",
+			v1.LabelInstanceTypeStable:   "m5.large",
+		}
+		n.Annotations = map[string]string{
+			model.PriceOverrideAnnotation: value,
+		}
+		node := model.NewNode(n)
+		node.UpdatePrice(pr)
+		if exp, got := 0.096, node.Price; exp != got {
+			t.Errorf("annotation %q: expected price == %f, got %f", value, exp, got)
+		}
+		if exp, got := model.NodePriceSourceOnDemand, node.PriceSource; exp != got {
+			t.Errorf("annotation %q: expected price source == %s, got %s", value, exp, got)
+		}
+	}
+}
+
+func TestNodeLicenseModel(t *testing.T) {
+	pr := testRepository(t, &pricingtest.Provider{
+		OnDemand: pricing.OnDemandPriceList{"m5.large": 0.096},
+		Licensed: map[pricing.LicenseModel]pricing.OnDemandPriceList{
+			pricing.LicenseModelWindows: {"m5.large": 0.188},
+			pricing.LicenseModelRHEL:    {"m5.large": 0.1248},
+		},
+	})
+	for name, tc := range map[string]struct {
+		annotations  map[string]string
+		labels       map[string]string
+		licenseModel pricing.LicenseModel
+		price        float64
+	}{
+		"unset": {
+			licenseModel: pricing.LicenseModelNone,
+			price:        0.096,
+		},
+		"byol annotation": {
+			annotations:  map[string]string{model.LicenseModelKey: "byol"},
+			licenseModel: pricing.LicenseModelBYOL,
+			price:        0.096,
+		},
+		"rhel annotation": {
+			annotations:  map[string]string{model.LicenseModelKey: "rhel"},
+			licenseModel: pricing.LicenseModelRHEL,
+			price:        0.1248,
+		},
+		"windows label": {
+			labels:       map[string]string{model.LicenseModelKey: "windows"},
+			licenseModel: pricing.LicenseModelWindows,
+			price:        0.188,
+		},
+		"annotation over label": {
+			annotations:  map[string]string{model.LicenseModelKey: "byol"},
+			labels:       map[string]string{model.LicenseModelKey: "windows"},
+			licenseModel: pricing.LicenseModelBYOL,
+			price:        0.096,
+		},
+		"invalid": {
+			annotations:  map[string]string{model.LicenseModelKey: "solaris"},
+			licenseModel: pricing.LicenseModelNone,
+			price:        0.096,
+		},
+	} {
+		n := testNode("mynode")
+		n.Labels = map[string]string{
+			"karpenter.sh/capacity-type": "on-demand",
+			v1.LabelInstanceTypeStable:   "m5.large",
+		}
+		for k, v := range tc.labels {
+			n.Labels[k] = v
+		}
+		n.Annotations = tc.annotations
+		node := model.NewNode(n)
+		if exp, got := tc.licenseModel, node.LicenseModel(); exp != got {
+			t.Errorf("%s: expected license model == %s, got %s", name, exp, got)
+		}
+		node.UpdatePrice(pr)
+		if exp, got := tc.price, node.Price; exp != got {
+			t.Errorf("%s: expected price == %f, got %f", name, exp, got)
+		}
+	}
+}
+
+func TestNodeLicenseModelSpot(t *testing.T) {
+	pr := testRepository(t, &pricingtest.Provider{
+		Spot: pricing.SpotPriceList{"m5.large": {"us-east-1a": 0.0375}},
+		Licensed: map[pricing.LicenseModel]pricing.OnDemandPriceList{
+			pricing.LicenseModelWindows: {"m5.large": 0.188},
+		},
+		LicensedSpot: map[pricing.LicenseModel]pricing.SpotPriceList{
+			pricing.LicenseModelWindows: {"m5.large": {"us-east-1a": 0.125}},
+		},
+	})
+	for name, tc := range map[string]struct {
+		licenseModel string
+		price        float64
+	}{
+		"linux":   {licenseModel: "", price: 0.0375},
+		"byol":    {licenseModel: "byol", price: 0.0375},
+		"windows": {licenseModel: "windows", price: 0.125},
+		// there are no RHEL spot prices, which mustn't fall back to the Linux price
+		"rhel": {licenseModel: "rhel", price: math.NaN()},
+	} {
+		n := testNode("mynode")
+		n.Labels = map[string]string{
+			"karpenter.sh/capacity-type": "spot",
+			v1.LabelInstanceTypeStable:   "m5.large",
+			v1.LabelTopologyZone:         "us-east-1a",
+		}
+		if tc.licenseModel != "" {
+			n.Annotations = map[string]string{model.LicenseModelKey: tc.licenseModel}
+		}
+		node := model.NewNode(n)
+		node.UpdatePrice(pr)
+		if exp, got := tc.price, node.Price; exp != got && !(math.IsNaN(exp) && math.IsNaN(got)) {
+			t.Errorf("%s: expected price == %f, got %f", name, exp, got)
+		}
+	}
+}
+
+func TestNodeFargateWindows(t *testing.T) {
+	pr := testRepository(t, &pricingtest.Provider{
+		Fargate: pricing.FargatePrice{
+			VCPUPerHour:          0.5,
+			GBPerHour:            0.25,
+			WindowsVCPUPerHour:   1,
+			WindowsGBPerHour:     0.5,
+			WindowsOSPerVCPUHour: 0.25,
+		},
+	})
+	for name, windows := range map[string]bool{
+		"linux":            false,
+		"windows-node":     true,
+		"windows-selector": true,
+	} {
+		n := testNode("fargate-" + name)
+		n.Labels = map[string]string{
+			"eks.amazonaws.com/compute-type": "fargate",
+		}
+		if name == "windows-node" {
+			n.Labels[v1.LabelOSStable] = "windows"
+		}
+		p := testPod("default", name)
+		p.Annotations = map[string]string{
+			"CapacityProvisioned": "2vCPU 4GB",
+		}
+		if name == "windows-selector" {
+			p.Spec.NodeSelector = map[string]string{v1.LabelOSStable: "windows"}
+		}
+		node := model.NewNode(n)
+		node.BindPod(model.NewPod(p))
+		node.UpdatePrice(pr)
+
+		// 2 * 0.5 + 4 * 0.25 for linux, 2 * (1 + 0.25) + 4 * 0.5 for windows
+		exp := 2.0
+		if windows {
+			exp = 4.5
+		}
+		if got := node.Price; exp != got {
+			t.Errorf("%s: expected price == %f, got %f (%s)", name, exp, got, node.PriceReason)
+		}
+	}
+}
+
+func TestNodeFargateRegions(t *testing.T) {
+	pr := testRepository(t, &pricingtest.Provider{
+		Fargate: pricing.FargatePrice{VCPUPerHour: 0.25, GBPerHour: 0.125},
+		RegionalFargate: map[string]pricing.FargatePrice{
+			"us-west-2": {VCPUPerHour: 0.5, GBPerHour: 0.25},
+			"eu-west-1": {VCPUPerHour: 0.625, GBPerHour: 0.125},
+		},
+	})
+	// 2 * 0.5 + 4 * 0.25 and 2 * 0.625 + 4 * 0.125, other regions use the pricing of the region of the provider
+	for region, exp := range map[string]float64{"us-west-2": 2, "eu-west-1": 1.75, "ap-south-1": 1} {
+		n := testNode("fargate-" + region)
+		n.Labels = map[string]string{
+			"eks.amazonaws.com/compute-type": "fargate",
+			v1.LabelTopologyRegion:           region,
+		}
+		p := testPod("default", region)
+		p.Annotations = map[string]string{
+			"CapacityProvisioned": "2vCPU 4GB",
+		}
+		node := model.NewNode(n)
+		node.BindPod(model.NewPod(p))
+		node.UpdatePrice(pr)
+		if got := node.Price; exp != got {
+			t.Errorf("%s: expected price == %f, got %f (%s)", region, exp, got, node.PriceReason)
+		}
+	}
+}
+
+func testOverheadNode(t *testing.T) *model.Node {
+	pr := testRepository(t, &pricingtest.Provider{
+		OnDemand: pricing.OnDemandPriceList{"m5.xlarge": 0.5},
+	})
+
+	n := testNode("mynode")
+	n.Labels = map[string]string{
+		"karpenter.sh/capacity-type": "on-demand",
+		v1.LabelInstanceTypeStable:   "m5.xlarge",
+	}
+	n.Status.Allocatable = v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("4"),
+		v1.ResourceMemory: resource.MustParse("16Gi"),
+	}
+	node := model.NewNode(n)
+
+	// shares of 0.25 and 0.5 for the workload pods and 0.25 for the DaemonSet pod
+	node.BindPod(model.NewPod(testPod("default", "small")))
+	large := testPod("default", "large")
+	large.Spec.Containers[0].Resources.Requests[v1.ResourceCPU] = resource.MustParse("2")
+	node.BindPod(model.NewPod(large))
+	ds := testPod("monitoring", "node-exporter")
+	ds.OwnerReferences = []metav1.OwnerReference{{Kind: "DaemonSet", Name: "node-exporter"}}
+	node.BindPod(model.NewPod(ds))
+	node.UpdatePrice(pr)
+	return node
+}
+
+func TestNodePodHourlyPricesSystemOverheadProportional(t *testing.T) {
+	node := testOverheadNode(t)
+
+	prices, overhead := node.AttributePodPrices(model.PodAttribution{SystemOverhead: model.SystemOverheadProportional})
+	got := map[string]float64{}
+	for _, podPrice := range prices {
+		got[podPrice.Pod.Name()] = podPrice.HourlyPrice
+	}
+	// the DaemonSet share of 0.25 is split 1:2 between the workload pods
+	expected := map[string]float64{
+		"small": 0.5 * (0.25 + 0.25/3),
+		"large": 0.5 * (0.5 + 0.25*2/3),
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("expected pod prices %v, got %v", expected, got)
+	}
+	for name, exp := range expected {
+		if math.Abs(exp-got[name]) > 1e-9 {
+			t.Errorf("expected %s pod price == %f, got %f", name, exp, got[name])
+		}
+	}
+	if overhead != 0 {
+		t.Errorf("expected overhead == 0, got %f", overhead)
+	}
+	if total := got["small"] + got["large"]; math.Abs(total-node.Price) > 1e-9 {
+		t.Errorf("expected pod prices to sum to node price %f, got %f", node.Price, total)
+	}
+}
+
+func TestNodePodHourlyPricesSystemOverheadSeparate(t *testing.T) {
+	node := testOverheadNode(t)
+
+	prices, overhead := node.AttributePodPrices(model.PodAttribution{SystemOverhead: model.SystemOverheadSeparate})
+	got := map[string]float64{}
+	for _, podPrice := range prices {
+		got[podPrice.Pod.Name()] = podPrice.HourlyPrice
+	}
+	if exp := map[string]float64{"small": 0.125, "large": 0.25}; len(got) != len(exp) ||
+		got["small"] != exp["small"] || got["large"] != exp["large"] {
+		t.Errorf("expected pod prices %v, got %v", exp, got)
+	}
+	if exp := 0.125; overhead != exp {
+		t.Errorf("expected overhead == %f, got %f", exp, overhead)
+	}
+}
+
+func TestNodePodHourlyPricesSystemOverheadOnlySystemPods(t *testing.T) {
+	pr := testRepository(t, &pricingtest.Provider{
+		OnDemand: pricing.OnDemandPriceList{"m5.xlarge": 0.5},
+	})
+	n := testNode("mynode")
+	n.Labels = map[string]string{
+		"karpenter.sh/capacity-type": "on-demand",
+		v1.LabelInstanceTypeStable:   "m5.xlarge",
+	}
+	n.Status.Allocatable = v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")}
+	node := model.NewNode(n)
+	node.BindPod(model.NewPod(testPod(metav1.NamespaceSystem, "coredns")))
+	node.UpdatePrice(pr)
+
+	// with no workload pods to redistribute to, system pods keep their own share
+	prices, _ := node.AttributePodPrices(model.PodAttribution{SystemOverhead: model.SystemOverheadProportional})
+	if len(prices) != 1 || prices[0].HourlyPrice != 0.125 {
+		t.Errorf("expected coredns pod price == 0.125, got %v", prices)
+	}
+}
+
+func TestNodePodHourlyPricesByLimits(t *testing.T) {
+	pr := testRepository(t, &pricingtest.Provider{
+		OnDemand: pricing.OnDemandPriceList{"m5.xlarge": 0.5},
+	})
+
+	n := testNode("mynode")
+	n.Labels = map[string]string{
+		"karpenter.sh/capacity-type": "on-demand",
+		v1.LabelInstanceTypeStable:   "m5.xlarge",
+	}
+	n.Status.Allocatable = v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("4"),
+		v1.ResourceMemory: resource.MustParse("16Gi"),
+	}
+	node := model.NewNode(n)
+
+	// requests 1 cpu and 1Gi but is limited to 2 cpus and 8Gi, so memory is the dominant limit with a share of 0.5
+	burstable := testPod("default", "burstable")
+	burstable.Spec.Containers[0].Resources.Limits = v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("2"),
+		v1.ResourceMemory: resource.MustParse("8Gi"),
+	}
+	node.BindPod(model.NewPod(burstable))
+	// has no limits so is attributed by its requests of 1 cpu and 1Gi either way
+	node.BindPod(model.NewPod(testPod("default", "unlimited")))
+	node.UpdatePrice(pr)
+
+	for _, tc := range []struct {
+		basis    model.AttributionBasis
+		expected map[string]float64
+	}{
+		{model.AttributionBasisRequests, map[string]float64{"burstable": 0.125, "unlimited": 0.125}},
+		{model.AttributionBasisLimits, map[string]float64{"burstable": 0.25, "unlimited": 0.125}},
+	} {
+		prices, _ := node.AttributePodPrices(model.PodAttribution{Basis: tc.basis})
+		got := map[string]float64{}
+		for _, podPrice := range prices {
+			got[podPrice.Pod.Name()] = podPrice.HourlyPrice
+		}
+		for name, exp := range tc.expected {
+			if got[name] != exp {
+				t.Errorf("expected %s pod price by %s == %f, got %f", name, tc.basis, exp, got[name])
+			}
+		}
+	}
+}
+
+func TestNodeIsEmpty(t *testing.T) {
+	node := model.NewNode(testNode("mynode"))
+	if !node.IsEmpty() {
+		t.Error("expected node without pods to be empty")
+	}
+
+	ds := testPod("monitoring", "node-exporter")
+	ds.OwnerReferences = []metav1.OwnerReference{{Kind: "DaemonSet", Name: "node-exporter"}}
+	node.BindPod(model.NewPod(ds))
+	if !node.IsEmpty() {
+		t.Error("expected node with only a DaemonSet pod to be empty")
+	}
+
+	node.BindPod(model.NewPod(testPod("default", "mypod")))
+	if node.IsEmpty() {
+		t.Error("expected node with a workload pod not to be empty")
+	}
+}
+
+func TestNodeInstanceFamily(t *testing.T) {
+	n := testNode("mynode")
+	n.Labels = map[string]string{v1.LabelInstanceTypeStable: "m5.large"}
+	if exp, got := "m5", model.NewNode(n).InstanceFamily(); exp != got {
+		t.Errorf("expected InstanceFamily == %s, got %s", exp, got)
+	}
+
+	fargate := testNode("fargate")
+	fargate.Labels = map[string]string{"eks.amazonaws.com/compute-type": "fargate"}
+	if exp, got := "fargate", model.NewNode(fargate).InstanceFamily(); exp != got {
+		t.Errorf("expected InstanceFamily == %s, got %s", exp, got)
+	}
+}
+
+func TestNodeFargateAccumulatedCost(t *testing.T) {
+	pr := testRepository(t, &pricingtest.Provider{
+		Fargate: pricing.FargatePrice{VCPUPerHour: 0.5, GBPerHour: 0.25},
+	})
+	n := testNode("fargate-accumulated")
+	n.Labels = map[string]string{
+		"eks.amazonaws.com/compute-type": "fargate",
+	}
+	p := testPod("default", "accumulated")
+	p.Annotations = map[string]string{
+		"CapacityProvisioned": "2vCPU 4GB",
+	}
+	node := model.NewNode(n)
+	pod := model.NewPod(p)
+	node.BindPod(pod)
+	node.UpdatePrice(pr)
+
+	started := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	if _, _, ok := node.FargateAccumulatedCost(started.Add(30 * time.Minute)); ok {
+		t.Errorf("expected no accumulated cost before the pod started")
+	}
+
+	p.Status.StartTime = &metav1.Time{Time: started}
+	pod.Update(p)
+	// 2 * 0.5 + 4 * 0.25 per hour for 30 minutes
+	got, gotPod, ok := node.FargateAccumulatedCost(started.Add(30 * time.Minute))
+	if !ok {
+		t.Fatalf("expected an accumulated cost")
+	}
+	if gotPod != pod {
+		t.Errorf("expected the accumulated cost of pod %s, got %v", pod.Name(), gotPod)
+	}
+	if exp := 1.0; got != exp {
+		t.Errorf("expected accumulated cost == %f, got %f", exp, got)
+	}
+
+	// fargate bills at least a minute
+	got, _, _ = node.FargateAccumulatedCost(started.Add(10 * time.Second))
+	if exp := 2.0 / 60; got != exp {
+		t.Errorf("expected accumulated cost == %f, got %f", exp, got)
+	}
+}
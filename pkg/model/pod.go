@@ -18,10 +18,14 @@ import (
 	"log"
 	"regexp"
 	"strconv"
+	"strings"
 	"sync"
+	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // Pod is our pod model used for internal storage and display.
@@ -79,6 +83,51 @@ func (p *Pod) Phase() v1.PodPhase {
 	return p.pod.Status.Phase
 }
 
+// StartTime returns when the kubelet started the pod, returning false if it hasn't been started yet.
+func (p *Pod) StartTime() (time.Time, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.pod.Status.StartTime == nil {
+		return time.Time{}, false
+	}
+	return p.pod.Status.StartTime.Time, true
+}
+
+// IsSystem returns true if the pod is part of the node overhead rather than a workload, i.e. it is run by a
+// DaemonSet or in the kube-system namespace.
+func (p *Pod) IsSystem() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.pod.Namespace == metav1.NamespaceSystem {
+		return true
+	}
+	for _, owner := range p.pod.OwnerReferences {
+		if owner.Kind == "DaemonSet" {
+			return true
+		}
+	}
+	return false
+}
+
+// Workload returns the kind and name of the controller managing the pod, resolving pods owned by a ReplicaSet of a
+// Deployment to the Deployment. Pods without a controller are their own workload with the kind "Pod".
+func (p *Pod) Workload() (kind string, name string) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	owner := metav1.GetControllerOfNoCopy(&p.pod)
+	if owner == nil {
+		return "Pod", p.pod.Name
+	}
+	if owner.Kind == "ReplicaSet" {
+		// ReplicaSets created by a Deployment are named after it with the pod template hash as a suffix
+		hash := p.pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]
+		if deployment := strings.TrimSuffix(owner.Name, "-"+hash); hash != "" && deployment != owner.Name {
+			return "Deployment", deployment
+		}
+	}
+	return owner.Kind, owner.Name
+}
+
 // Requested returns the sum of the resources requested by the pod. This doesn't include any init containers as we
 // are interested in the steady state usage of the pod.
 func (p *Pod) Requested() v1.ResourceList {
@@ -96,8 +145,40 @@ func (p *Pod) Requested() v1.ResourceList {
 	return requested
 }
 
+// Limits returns the sum of the resource limits of the pod. Resources that any container requests without a limit are
+// left out since the pod is unbounded for them. Like Requested, init containers are not included.
+func (p *Pod) Limits() v1.ResourceList {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	limits := v1.ResourceList{}
+	unlimited := map[v1.ResourceName]bool{}
+	for _, c := range p.pod.Spec.Containers {
+		for rn := range c.Resources.Requests {
+			if _, ok := c.Resources.Limits[rn]; !ok {
+				unlimited[rn] = true
+			}
+		}
+		for rn, q := range c.Resources.Limits {
+			existing := limits[rn]
+			existing.Add(q)
+			limits[rn] = existing
+		}
+	}
+	for rn := range unlimited {
+		delete(limits, rn)
+	}
+	return limits
+}
+
 var fargateCapacityRe = regexp.MustCompile("(.*?)vCPU (.*?)GB")
 
+// IsWindows returns true if the pod selects Windows nodes.
+func (p *Pod) IsWindows() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.pod.Spec.NodeSelector[v1.LabelOSStable] == "windows"
+}
+
 func (p *Pod) FargateCapacityProvisioned() (float64, float64, bool) {
 	provisioned, ok := p.pod.Annotations["CapacityProvisioned"]
 	if !ok {
@@ -107,6 +188,7 @@ func (p *Pod) FargateCapacityProvisioned() (float64, float64, bool) {
 	match := fargateCapacityRe.FindStringSubmatch(provisioned)
 	if len(match) != 3 {
 		log.Printf("unable to parse %q for fargate provisioner capacity", provisioned)
+		return 0, 0, false
 	}
 	cpu, err := strconv.ParseFloat(match[1], 64)
 	if err != nil {
@@ -120,3 +202,38 @@ func (p *Pod) FargateCapacityProvisioned() (float64, float64, bool) {
 	}
 	return cpu, mem, true
 }
+
+// FargateCapacityRequested returns the vCPU and GB of memory Fargate provisions for the pod based on its resource
+// requests. Like Fargate, it takes the larger of the requests of all containers and of the largest init container,
+// adds the memory reserved for the Kubernetes components and rounds up to the smallest Fargate configuration fitting
+// them. It returns false if the pod requests more than the largest configuration.
+func (p *Pod) FargateCapacityRequested() (float64, float64, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	var cpu, mem resource.Quantity
+	for _, c := range p.pod.Spec.Containers {
+		cpu.Add(c.Resources.Requests[v1.ResourceCPU])
+		mem.Add(c.Resources.Requests[v1.ResourceMemory])
+	}
+	// init containers run one at a time before the other containers start
+	for _, c := range p.pod.Spec.InitContainers {
+		if q := c.Resources.Requests[v1.ResourceCPU]; q.Cmp(cpu) > 0 {
+			cpu = q
+		}
+		if q := c.Resources.Requests[v1.ResourceMemory]; q.Cmp(mem) > 0 {
+			mem = q
+		}
+	}
+	vCPU := float64(cpu.MilliValue()) / 1000
+	memoryGB := mem.AsApproximateFloat64()/(1<<30) + fargateMemoryOverheadGB
+	return FargateConfiguration(vCPU, memoryGB)
+}
+
+// FargateCapacity returns the vCPU and GB of memory provisioned for the pod on Fargate, from the CapacityProvisioned
+// annotation Fargate sets or from its resource requests if it isn't set.
+func (p *Pod) FargateCapacity() (float64, float64, bool) {
+	if cpu, mem, ok := p.FargateCapacityProvisioned(); ok {
+		return cpu, mem, true
+	}
+	return p.FargateCapacityRequested()
+}
@@ -109,3 +109,70 @@ func TestFargateCapacity(t *testing.T) {
 		t.Errorf("expected to have a mem capacity of 0.5, got %f", mem)
 	}
 }
+
+func TestPodLimits(t *testing.T) {
+	pod := testPod("default", "mypod")
+	pod.Spec.Containers[0].Resources.Limits = v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("2"),
+		v1.ResourceMemory: resource.MustParse("2Gi"),
+	}
+	pod.Spec.Containers = append(pod.Spec.Containers, v1.Container{
+		Name: "sidecar",
+		Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")},
+			Limits:   v1.ResourceList{v1.ResourceMemory: resource.MustParse("256Mi")},
+		},
+	})
+	limits := model.NewPod(pod).Limits()
+
+	// the sidecar requests cpu without a limit, so the pod has no cpu limit
+	if _, ok := limits[v1.ResourceCPU]; ok {
+		t.Errorf("expected no cpu limit, got %s", limits.Cpu())
+	}
+	if exp, got := resource.MustParse("2304Mi"), limits[v1.ResourceMemory]; exp.Cmp(got) != 0 {
+		t.Errorf("expected memory limit = %s, got %s", exp.String(), got.String())
+	}
+}
+
+func TestPodWorkload(t *testing.T) {
+	controller := true
+	for name, tc := range map[string]struct {
+		owner        *metav1.OwnerReference
+		labels       map[string]string
+		expectedKind string
+		expectedName string
+	}{
+		"deployment": {
+			owner:        &metav1.OwnerReference{Kind: "ReplicaSet", Name: "web-5d8f7c9b4", Controller: &controller},
+			labels:       map[string]string{"pod-template-hash": "5d8f7c9b4"},
+			expectedKind: "Deployment",
+			expectedName: "web",
+		},
+		"bare replicaset": {
+			owner:        &metav1.OwnerReference{Kind: "ReplicaSet", Name: "web", Controller: &controller},
+			expectedKind: "ReplicaSet",
+			expectedName: "web",
+		},
+		"statefulset": {
+			owner:        &metav1.OwnerReference{Kind: "StatefulSet", Name: "db", Controller: &controller},
+			expectedKind: "StatefulSet",
+			expectedName: "db",
+		},
+		"no controller": {
+			expectedKind: "Pod",
+			expectedName: "mypod",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			pod := testPod("default", "mypod")
+			pod.Labels = tc.labels
+			if tc.owner != nil {
+				pod.OwnerReferences = []metav1.OwnerReference{*tc.owner}
+			}
+			kind, name := model.NewPod(pod).Workload()
+			if kind != tc.expectedKind || name != tc.expectedName {
+				t.Errorf("expected workload %s/%s, got %s/%s", tc.expectedKind, tc.expectedName, kind, name)
+			}
+		})
+	}
+}
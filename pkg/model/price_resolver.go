@@ -0,0 +1,133 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing"
+)
+
+// PriceResolution is the outcome of a PriceResolver. The reason describes where the price came from, or, if the
+// resolver applies to the node but found no price, why not.
+type PriceResolution struct {
+	Price  float64
+	Source NodePriceSource
+	Reason string
+}
+
+// PriceResolver resolves the hourly price of a node, returning false if it has no price for the node. Resolvers which
+// don't apply to the node at all return an empty reason.
+type PriceResolver func(n *Node, pricingRepository *pricing.Repository) (PriceResolution, bool)
+
+// DefaultPriceResolvers returns the resolvers nodes are priced with by default, in order of precedence: the
+// PriceOverrideAnnotation, then the EC2, Fargate and EKS Hybrid Nodes prices.
+func DefaultPriceResolvers() []PriceResolver {
+	return []PriceResolver{
+		ResolveAnnotationPrice,
+		ResolveEC2Price,
+		ResolveFargatePrice,
+		ResolveHybridPrice,
+	}
+}
+
+// WithPriceResolvers replaces the DefaultPriceResolvers the node is priced with. The first resolver returning a price
+// wins.
+func WithPriceResolvers(resolvers ...PriceResolver) NodeOption {
+	return func(n *Node) {
+		n.priceResolvers = resolvers
+	}
+}
+
+// ResolveAnnotationPrice resolves the price set by a valid PriceOverrideAnnotation.
+func ResolveAnnotationPrice(n *Node, _ *pricing.Repository) (PriceResolution, bool) {
+	price, ok := n.PriceOverride()
+	if !ok {
+		return PriceResolution{}, false
+	}
+	return PriceResolution{
+		Price:  price,
+		Source: NodePriceSourceAnnotation,
+		Reason: fmt.Sprintf("%s annotation", PriceOverrideAnnotation),
+	}, true
+}
+
+// ResolveEC2Price looks up the price of capacity block, on-demand and spot nodes by their capacity type.
+func ResolveEC2Price(n *Node, pricingRepository *pricing.Repository) (PriceResolution, bool) {
+	if !n.IsCapacityBlock() && !n.IsOnDemand() && !n.IsSpot() {
+		return PriceResolution{}, false
+	}
+	result := pricingRepository.Lookup(pricing.LookupRequest{
+		InstanceType: n.InstanceType(),
+		CapacityType: n.CapacityType().String(),
+		Zone:         n.Zone(),
+		LicenseModel: n.LicenseModel(),
+	})
+	if result.Price == nil {
+		return PriceResolution{Reason: result.Reason}, false
+	}
+	return PriceResolution{
+		Price:  *result.Price,
+		Source: NodePriceSource(n.CapacityType()),
+		Reason: result.Reason,
+	}, true
+}
+
+// ResolveFargatePrice prices Fargate nodes by the vCPU and memory of their pod, at the Fargate pricing of their region.
+func ResolveFargatePrice(n *Node, pricingRepository *pricing.Repository) (PriceResolution, bool) {
+	if !n.IsFargate() {
+		return PriceResolution{}, false
+	}
+	if len(n.Pods()) != 1 {
+		return PriceResolution{Reason: "fargate node without exactly one pod"}, false
+	}
+	pod := n.Pods()[0]
+	cpu, mem, ok := pod.FargateCapacity()
+	if !ok {
+		return PriceResolution{Reason: "fargate pod requests more than the largest fargate configuration"}, false
+	}
+	if n.IsWindows() || pod.IsWindows() {
+		price, ok := pricingRepository.FargateWindowsPrice(n.Region(), cpu, mem)
+		if !ok {
+			return PriceResolution{Reason: "no fargate windows price"}, false
+		}
+		return PriceResolution{
+			Price:  price,
+			Source: NodePriceSourceFargate,
+			Reason: fmt.Sprintf("fargate windows price for %gvCPU and %gGB", cpu, mem),
+		}, true
+	}
+	price, ok := pricingRepository.FargatePrice(n.Region(), cpu, mem)
+	if !ok {
+		return PriceResolution{Reason: "no fargate price"}, false
+	}
+	return PriceResolution{
+		Price:  price,
+		Source: NodePriceSourceFargate,
+		Reason: fmt.Sprintf("fargate price for %gvCPU and %gGB", cpu, mem),
+	}, true
+}
+
+// ResolveHybridPrice prices EKS Hybrid Nodes at their flat rate, see WithHybridHourlyPrice.
+func ResolveHybridPrice(n *Node, _ *pricing.Repository) (PriceResolution, bool) {
+	if !n.IsHybrid() {
+		return PriceResolution{}, false
+	}
+	return PriceResolution{
+		Price:  n.hybridHourlyPrice,
+		Source: NodePriceSourceHybrid,
+		Reason: "hybrid node flat rate",
+	}, true
+}
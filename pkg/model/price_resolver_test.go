@@ -0,0 +1,117 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/sapslaj/eks-pricing-exporter/pkg/model"
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing"
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing/pricingtest"
+)
+
+// staticResolver always resolves to price with the "contract" price source.
+func staticResolver(price float64) model.PriceResolver {
+	return func(_ *model.Node, _ *pricing.Repository) (model.PriceResolution, bool) {
+		return model.PriceResolution{Price: price, Source: "contract", Reason: "contract rate"}, true
+	}
+}
+
+func testOnDemandNode(annotations map[string]string) *v1.Node {
+	n := testNode("mynode")
+	n.Labels = map[string]string{
+		"karpenter.sh/capacity-type": "on-demand",
+		v1.LabelInstanceTypeStable:   "m5.large",
+	}
+	n.Annotations = annotations
+	return n
+}
+
+func TestNodePriceResolverOrder(t *testing.T) {
+	pr := testRepository(t, &pricingtest.Provider{
+		OnDemand: pricing.OnDemandPriceList{"m5.large": 0.096},
+	})
+	annotated := map[string]string{model.PriceOverrideAnnotation: "1.5"}
+	for name, tc := range map[string]struct {
+		annotations map[string]string
+		resolvers   []model.PriceResolver
+		price       float64
+		source      model.NodePriceSource
+	}{
+		"default annotation over on-demand": {
+			annotations: annotated,
+			price:       1.5,
+			source:      model.NodePriceSourceAnnotation,
+		},
+		"default on-demand": {
+			price:  0.096,
+			source: model.NodePriceSourceOnDemand,
+		},
+		"first resolver wins": {
+			annotations: annotated,
+			resolvers:   []model.PriceResolver{staticResolver(0.5), model.ResolveAnnotationPrice},
+			price:       0.5,
+			source:      "contract",
+		},
+		"later resolver is skipped": {
+			resolvers: append(model.DefaultPriceResolvers(), staticResolver(0.5)),
+			price:     0.096,
+			source:    model.NodePriceSourceOnDemand,
+		},
+		"inapplicable resolvers fall through": {
+			resolvers: []model.PriceResolver{
+				model.ResolveAnnotationPrice,
+				model.ResolveFargatePrice,
+				staticResolver(0.5),
+			},
+			price:  0.5,
+			source: "contract",
+		},
+	} {
+		var opts []model.NodeOption
+		if tc.resolvers != nil {
+			opts = append(opts, model.WithPriceResolvers(tc.resolvers...))
+		}
+		node := model.NewNode(testOnDemandNode(tc.annotations), opts...)
+		node.UpdatePrice(pr)
+		if exp, got := tc.price, node.Price; exp != got {
+			t.Errorf("%s: expected price == %f, got %f", name, exp, got)
+		}
+		if exp, got := tc.source, node.PriceSource; exp != got {
+			t.Errorf("%s: expected price source == %s, got %s", name, exp, got)
+		}
+	}
+}
+
+func TestNodePriceResolverNoPrice(t *testing.T) {
+	pr := testRepository(t, &pricingtest.Provider{})
+	node := model.NewNode(testOnDemandNode(nil), model.WithPriceResolvers(
+		model.ResolveEC2Price,
+		model.ResolveHybridPrice,
+	))
+	node.UpdatePrice(pr)
+	if node.HasPrice() {
+		t.Errorf("expected no price, got %f", node.Price)
+	}
+	if exp, got := model.NodePriceSourceNone, node.PriceSource; exp != got {
+		t.Errorf("expected price source == %s, got %s", exp, got)
+	}
+	// the reason of the on-demand lookup is kept since the hybrid resolver doesn't apply
+	if node.PriceReason == "" || node.PriceReason == "unknown capacity type" {
+		t.Errorf("expected the reason of the on-demand lookup, got %q", node.PriceReason)
+	}
+}
@@ -0,0 +1,86 @@
+package pricing
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MetricsNamespace is the namespace of all metrics of the exporter.
+const MetricsNamespace = "eks"
+
+// APIMetrics records the number and latency of every AWS API call made by clients created from a config it
+// instruments. It is a prometheus.Collector which needs to be registered to be exported.
+type APIMetrics struct {
+	requestDuration *prometheus.HistogramVec
+	// calls counts every API call, so every page of a paginated call like GetProducts, to size refresh intervals
+	// against API costs and rate limits.
+	calls *prometheus.CounterVec
+}
+
+func NewAPIMetrics() *APIMetrics {
+	return &APIMetrics{
+		requestDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: MetricsNamespace,
+				Subsystem: "aws_api",
+				Name:      "request_duration_seconds",
+				Help:      "duration of AWS API calls including retries",
+				Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+			},
+			[]string{"api", "status"},
+		),
+		calls: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: MetricsNamespace,
+				Subsystem: "aws_api",
+				Name:      "calls_total",
+				Help:      "number of AWS API calls, counting every page of paginated calls",
+			},
+			[]string{"api"},
+		),
+	}
+}
+
+// Instrument adds a middleware to cfg which counts and observes the duration and outcome of every API call of the clients
+// created from it. The APIOptions of cfg are copied so configs sharing them are not affected.
+func (m *APIMetrics) Instrument(cfg *aws.Config) {
+	apiOptions := make([]func(*middleware.Stack) error, len(cfg.APIOptions), len(cfg.APIOptions)+1)
+	copy(apiOptions, cfg.APIOptions)
+	cfg.APIOptions = append(apiOptions, func(stack *middleware.Stack) error {
+		// added after the operation metadata is registered so the operation name is available
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc(
+			"APIRequestDuration",
+			func(
+				ctx context.Context,
+				in middleware.InitializeInput,
+				next middleware.InitializeHandler,
+			) (middleware.InitializeOutput, middleware.Metadata, error) {
+				start := time.Now()
+				out, metadata, err := next.HandleInitialize(ctx, in)
+				status := "success"
+				if err != nil {
+					status = "error"
+				}
+				operation := awsmiddleware.GetOperationName(ctx)
+				m.calls.WithLabelValues(operation).Inc()
+				m.requestDuration.WithLabelValues(operation, status).Observe(time.Since(start).Seconds())
+				return out, metadata, err
+			},
+		), middleware.After)
+	})
+}
+
+func (m *APIMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.requestDuration.Describe(ch)
+	m.calls.Describe(ch)
+}
+
+func (m *APIMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.requestDuration.Collect(ch)
+	m.calls.Collect(ch)
+}
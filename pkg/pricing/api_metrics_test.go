@@ -0,0 +1,105 @@
+package pricing_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing"
+)
+
+type failingHTTPClient struct{}
+
+func (c *failingHTTPClient) Do(_ *http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestAPIMetrics(t *testing.T) {
+	metrics := pricing.NewAPIMetrics()
+
+	for _, client := range []aws.HTTPClient{&testHTTPClient{}, &failingHTTPClient{}} {
+		cfg := aws.Config{
+			Region:           "us-east-1",
+			Credentials:      aws.AnonymousCredentials{},
+			HTTPClient:       client,
+			RetryMaxAttempts: 1,
+		}
+		metrics.Instrument(&cfg)
+		_, _ = pricing.NewAWSProvider(cfg).GetFargatePricing(context.Background())
+	}
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(metrics)
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	counts := map[string]uint64{}
+	for _, family := range families {
+		if family.GetName() != "eks_aws_api_request_duration_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			counts[labels["api"]+"/"+labels["status"]] = metric.GetHistogram().GetSampleCount()
+		}
+	}
+	for _, key := range []string{"GetProducts/success", "GetProducts/error"} {
+		if exp, got := uint64(1), counts[key]; exp != got {
+			t.Errorf("expected %d %s observations, got %d", exp, key, got)
+		}
+	}
+}
+
+// pagingHTTPClient answers GetProducts calls with empty pages, with a next token on all but the last of pages.
+type pagingHTTPClient struct {
+	pages int
+	calls int
+}
+
+func (c *pagingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	c.calls++
+	body := `{"FormatVersion":"aws_v1","PriceList":[]}`
+	if c.calls < c.pages {
+		body = `{"FormatVersion":"aws_v1","PriceList":[],"NextToken":"` + strconv.Itoa(c.calls) + `"}`
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/x-amz-json-1.1"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestAPIMetricsCallsPerPage(t *testing.T) {
+	metrics := pricing.NewAPIMetrics()
+	cfg := aws.Config{
+		Region:      "us-east-1",
+		Credentials: aws.AnonymousCredentials{},
+		HTTPClient:  &pagingHTTPClient{pages: 3},
+	}
+	metrics.Instrument(&cfg)
+	if _, err := pricing.NewAWSProvider(cfg).GetFargatePricing(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := `
+# HELP eks_aws_api_calls_total number of AWS API calls, counting every page of paginated calls
+# TYPE eks_aws_api_calls_total counter
+eks_aws_api_calls_total{api="GetProducts"} 3
+`
+	if err := testutil.CollectAndCompare(metrics, strings.NewReader(expected), "eks_aws_api_calls_total"); err != nil {
+		t.Error(err)
+	}
+}
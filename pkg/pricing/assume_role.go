@@ -0,0 +1,42 @@
+package pricing
+
+import (
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+)
+
+// RoleCredentialsCache assumes IAM roles, e.g. one per account to fetch pricing from, and caches the credentials of
+// each role until they expire so that refreshes don't call STS every time.
+type RoleCredentialsCache struct {
+	client stscreds.AssumeRoleAPIClient
+
+	mu    sync.Mutex
+	roles map[string]*aws.CredentialsCache
+}
+
+// NewRoleCredentialsCache returns a RoleCredentialsCache which assumes roles with client.
+func NewRoleCredentialsCache(client stscreds.AssumeRoleAPIClient) *RoleCredentialsCache {
+	return &RoleCredentialsCache{client: client, roles: map[string]*aws.CredentialsCache{}}
+}
+
+// Credentials returns the credentials provider for roleARN, which assumes the role the first time credentials are
+// retrieved and again only once they have expired.
+func (c *RoleCredentialsCache) Credentials(roleARN string) aws.CredentialsProvider {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	credentials, ok := c.roles[roleARN]
+	if !ok {
+		credentials = aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(c.client, roleARN))
+		c.roles[roleARN] = credentials
+	}
+	return credentials
+}
+
+// Config returns a copy of cfg using the credentials of roleARN.
+func (c *RoleCredentialsCache) Config(cfg aws.Config, roleARN string) aws.Config {
+	roleCfg := cfg.Copy()
+	roleCfg.Credentials = c.Credentials(roleARN)
+	return roleCfg
+}
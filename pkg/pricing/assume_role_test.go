@@ -0,0 +1,76 @@
+package pricing_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	ststypes "github.com/aws/aws-sdk-go-v2/service/sts/types"
+
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing"
+)
+
+type testAssumeRoleClient struct {
+	// expiration is when the credentials returned by AssumeRole expire
+	expiration time.Time
+	calls      map[string]int
+}
+
+func (c *testAssumeRoleClient) AssumeRole(
+	_ context.Context,
+	input *sts.AssumeRoleInput,
+	_ ...func(*sts.Options),
+) (*sts.AssumeRoleOutput, error) {
+	roleARN := aws.ToString(input.RoleArn)
+	c.calls[roleARN]++
+	return &sts.AssumeRoleOutput{
+		Credentials: &ststypes.Credentials{
+			AccessKeyId:     aws.String("AKID-" + roleARN),
+			SecretAccessKey: aws.String("secret"),
+			SessionToken:    aws.String("token"),
+			Expiration:      aws.Time(c.expiration),
+		},
+	}, nil
+}
+
+func TestRoleCredentialsCache(t *testing.T) {
+	const (
+		roleA = "arn:aws:iam::111111111111:role/pricing"
+		roleB = "arn:aws:iam::222222222222:role/pricing"
+	)
+	ctx := context.Background()
+	client := &testAssumeRoleClient{expiration: time.Now().Add(time.Hour), calls: map[string]int{}}
+	cache := pricing.NewRoleCredentialsCache(client)
+	cfg := aws.Config{Region: "us-east-1"}
+
+	for i := 0; i < 3; i++ {
+		for _, roleARN := range []string{roleA, roleB} {
+			credentials, err := cache.Config(cfg, roleARN).Credentials.Retrieve(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error retrieving credentials: %s", err)
+			}
+			if exp, got := "AKID-"+roleARN, credentials.AccessKeyID; exp != got {
+				t.Errorf("expected access key %s, got %s", exp, got)
+			}
+		}
+	}
+	for _, roleARN := range []string{roleA, roleB} {
+		if exp, got := 1, client.calls[roleARN]; exp != got {
+			t.Errorf("expected %s to be assumed %d times before its credentials expire, got %d", roleARN, exp, got)
+		}
+	}
+
+	// credentials which have already expired are never reused
+	client.expiration = time.Now().Add(-time.Minute)
+	expiring := pricing.NewRoleCredentialsCache(client)
+	for i := 0; i < 2; i++ {
+		if _, err := expiring.Credentials(roleA).Retrieve(ctx); err != nil {
+			t.Fatalf("unexpected error retrieving credentials: %s", err)
+		}
+	}
+	if exp, got := 3, client.calls[roleA]; exp != got {
+		t.Errorf("expected expired credentials of %s to be assumed %d times in total, got %d", roleA, exp, got)
+	}
+}
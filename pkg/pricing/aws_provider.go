@@ -8,128 +8,483 @@ import (
 	"log"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/aws/aws-sdk-go-v2/service/pricing"
 	pricingtypes "github.com/aws/aws-sdk-go-v2/service/pricing/types"
 	"github.com/samber/lo"
 )
 
+// EC2API is the subset of the EC2 API used by the AWS provider.
+type EC2API interface {
+	ec2.DescribeSpotPriceHistoryAPIClient
+	ec2.DescribeInstanceTypesAPIClient
+}
+
 type AWSProvider struct {
 	Region        string
-	EC2Client     ec2.DescribeSpotPriceHistoryAPIClient
+	EC2Client     EC2API
 	PricingClient pricing.GetProductsAPIClient
+	// MaxSpotPricePages bounds the number of DescribeSpotPriceHistory pages fetched per refresh, 0 for no limit.
+	MaxSpotPricePages int
+	// Filters are the attribute values used to select EC2 products from the pricing API. Empty fields fall back to
+	// DefaultPricingFilters.
+	Filters PricingFilters
+	// Zones restricts spot pricing to these zones, all zones of the region if empty.
+	Zones []string
+
+	// zonesMu guards Zones once the provider is in use, see ScopeToZones
+	zonesMu sync.RWMutex
 }
 
-// NewAWSPricingClient returns a pricing API client configured based on a particular region.
-func NewAWSPricingClient(cfg aws.Config, region string) *pricing.Client {
-	// pricing API doesn't have an endpoint in all regions
-	pricingAPIRegion := "us-east-1"
-	if strings.HasPrefix(region, "ap-") {
-		pricingAPIRegion = "ap-south-1"
+// ScopeToZones restricts spot pricing to zones from the next time spot pricing is fetched on.
+func (p *AWSProvider) ScopeToZones(zones []string) {
+	p.zonesMu.Lock()
+	defer p.zonesMu.Unlock()
+	p.Zones = zones
+}
+
+// PricingFilters are the attribute values used to select EC2 products from the pricing API. AWS has renamed these
+// before, so they can be overridden without a code change.
+type PricingFilters struct {
+	ServiceCode          string
+	OperatingSystem      string
+	PreInstalledSoftware string
+	CapacityStatus       string
+	// SharedTenancy and SharedProductFamily select regular instances.
+	SharedTenancy       string
+	SharedProductFamily string
+	// MetalTenancy and MetalProductFamily select bare metal instances.
+	MetalTenancy       string
+	MetalProductFamily string
+}
+
+// DefaultPricingFilters returns the pricing filters matching the current AWS pricing taxonomy.
+func DefaultPricingFilters() PricingFilters {
+	return PricingFilters{
+		ServiceCode:          "AmazonEC2",
+		OperatingSystem:      "Linux",
+		PreInstalledSoftware: "NA",
+		CapacityStatus:       "Used",
+		SharedTenancy:        "Shared",
+		SharedProductFamily:  "Compute Instance",
+		MetalTenancy:         "Dedicated",
+		MetalProductFamily:   "Compute Instance (bare metal)",
+	}
+}
+
+// ParsePricingFilters parses pricing filter overrides like operatingSystem=Linux. Filters that are not given are left
+// empty, falling back to DefaultPricingFilters.
+func ParsePricingFilters(overrides []string) (PricingFilters, error) {
+	var filters PricingFilters
+	fields := map[string]*string{
+		"serviceCode":          &filters.ServiceCode,
+		"operatingSystem":      &filters.OperatingSystem,
+		"preInstalledSoftware": &filters.PreInstalledSoftware,
+		"capacityStatus":       &filters.CapacityStatus,
+		"sharedTenancy":        &filters.SharedTenancy,
+		"sharedProductFamily":  &filters.SharedProductFamily,
+		"metalTenancy":         &filters.MetalTenancy,
+		"metalProductFamily":   &filters.MetalProductFamily,
+	}
+	for _, override := range overrides {
+		key, value, ok := strings.Cut(override, "=")
+		field, known := fields[key]
+		if !ok || !known || value == "" {
+			return PricingFilters{}, fmt.Errorf("invalid pricing filter %q: must be like operatingSystem=Linux", override)
+		}
+		*field = value
+	}
+	return filters, nil
+}
+
+// AWSProviderOption configures the AWS API clients created by NewAWSProvider.
+type AWSProviderOption func(*awsProviderOptions)
+
+type awsProviderOptions struct {
+	pricingEndpoint       string
+	ec2Endpoint           string
+	pricingRegionFallback bool
+}
+
+// WithPricingEndpoint sends pricing API requests to endpoint instead of the AWS endpoint, e.g. for localstack or a
+// pricing proxy. An empty endpoint uses the AWS endpoint.
+func WithPricingEndpoint(endpoint string) AWSProviderOption {
+	return func(o *awsProviderOptions) {
+		o.pricingEndpoint = endpoint
+	}
+}
+
+// WithEC2Endpoint sends EC2 API requests to endpoint instead of the AWS endpoint. An empty endpoint uses the AWS
+// endpoint.
+func WithEC2Endpoint(endpoint string) AWSProviderOption {
+	return func(o *awsProviderOptions) {
+		o.ec2Endpoint = endpoint
+	}
+}
+
+func newAWSProviderOptions(opts []AWSProviderOption) awsProviderOptions {
+	var o awsProviderOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// regionPartition returns the AWS partition of region, e.g. "aws-cn" for the China regions.
+func regionPartition(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return "aws-cn"
+	case strings.HasPrefix(region, "us-gov-"):
+		return "aws-us-gov"
+	default:
+		return "aws"
+	}
+}
+
+// RegionCurrency returns the currency AWS prices region in, CNY in the China regions and USD otherwise.
+func RegionCurrency(region string) string {
+	if regionPartition(region) == "aws-cn" {
+		return "CNY"
+	}
+	return "USD"
+}
+
+// pricingAPIRegion returns the region of the pricing API endpoint to fetch prices for region from. The pricing API
+// doesn't have an endpoint in all regions, and the prices of the China regions are only served from within the China
+// partition.
+func pricingAPIRegion(region string) string {
+	switch {
+	case regionPartition(region) == "aws-cn":
+		return "cn-northwest-1"
+	case strings.HasPrefix(region, "ap-"):
+		return "ap-south-1"
+	default:
+		return "us-east-1"
 	}
+}
+
+// pricePerUnit is the price of a price dimension in the pricing API by currency. Prices are in USD, except in the China
+// partition where they are in CNY.
+type pricePerUnit map[string]string
+
+// currency returns the currency of amount, or an empty string if the price is in neither USD nor CNY.
+func (p pricePerUnit) currency() string {
+	for _, currency := range []string{"USD", "CNY"} {
+		if p[currency] != "" {
+			return currency
+		}
+	}
+	return ""
+}
+
+func (p pricePerUnit) amount() string {
+	return p[p.currency()]
+}
+
+// hourlyUnit is the unit of the hourly EC2 price dimensions in the pricing API.
+const hourlyUnit = "Hrs"
+
+// NewAWSPricingClient returns a pricing API client configured based on a particular region.
+func NewAWSPricingClient(cfg aws.Config, region string, opts ...AWSProviderOption) *pricing.Client {
+	endpoint := newAWSProviderOptions(opts).pricingEndpoint
 	return pricing.NewFromConfig(cfg, func(o *pricing.Options) {
-		o.Region = pricingAPIRegion
+		o.Region = pricingAPIRegion(region)
+		if endpoint != "" {
+			o.EndpointResolver = pricing.EndpointResolverFromURL(endpoint)
+		}
 	})
 }
 
-func NewAWSProvider(cfg aws.Config) *AWSProvider {
+// NewEC2Client returns an EC2 API client for the region of cfg.
+func NewEC2Client(cfg aws.Config, opts ...AWSProviderOption) *ec2.Client {
+	endpoint := newAWSProviderOptions(opts).ec2Endpoint
+	return ec2.NewFromConfig(cfg, func(o *ec2.Options) {
+		if endpoint != "" {
+			o.EndpointResolver = ec2.EndpointResolverFromURL(endpoint)
+		}
+	})
+}
+
+func NewAWSProvider(cfg aws.Config, opts ...AWSProviderOption) *AWSProvider {
 	return &AWSProvider{
 		Region:        cfg.Region,
-		EC2Client:     ec2.NewFromConfig(cfg),
-		PricingClient: NewAWSPricingClient(cfg, cfg.Region),
+		EC2Client:     NewEC2Client(cfg, opts...),
+		PricingClient: newAWSPricingRegionClient(cfg, opts...),
+		Filters:       DefaultPricingFilters(),
+	}
+}
+
+// newAWSPricingRegionClient returns the pricing API client for the region of cfg, which falls back to the us-east-1
+// pricing API if it is unreachable and WithPricingRegionFallback is set.
+func newAWSPricingRegionClient(cfg aws.Config, opts ...AWSProviderOption) pricing.GetProductsAPIClient {
+	o := newAWSProviderOptions(opts)
+	if o.pricingEndpoint != "" {
+		return NewAWSPricingClient(cfg, cfg.Region, opts...)
+	}
+	var fallback pricing.GetProductsAPIClient
+	if o.pricingRegionFallback &&
+		regionPartition(cfg.Region) == "aws" &&
+		pricingAPIRegion(cfg.Region) != fallbackPricingAPIRegion {
+		fallback = NewAWSPricingClient(cfg, fallbackPricingAPIRegion, opts...)
 	}
+	return NewPricingRegionClient(cfg.Region, NewAWSPricingClient(cfg, cfg.Region, opts...), fallback)
+}
+
+func (p *AWSProvider) PricingSource(_ PricingType) string {
+	return PricingSourceAWS
+}
+
+// filters returns the configured pricing filters with empty fields set to their defaults.
+func (p *AWSProvider) filters() PricingFilters {
+	filters := p.Filters
+	defaults := DefaultPricingFilters()
+	for _, f := range []struct {
+		value *string
+		def   string
+	}{
+		{&filters.ServiceCode, defaults.ServiceCode},
+		{&filters.OperatingSystem, defaults.OperatingSystem},
+		{&filters.PreInstalledSoftware, defaults.PreInstalledSoftware},
+		{&filters.CapacityStatus, defaults.CapacityStatus},
+		{&filters.SharedTenancy, defaults.SharedTenancy},
+		{&filters.SharedProductFamily, defaults.SharedProductFamily},
+		{&filters.MetalTenancy, defaults.MetalTenancy},
+		{&filters.MetalProductFamily, defaults.MetalProductFamily},
+	} {
+		if *f.value == "" {
+			*f.value = f.def
+		}
+	}
+	return filters
 }
 
 func (p *AWSProvider) GetOnDemandPricing(ctx context.Context) (OnDemandPriceList, error) {
-	onDemandPrices, err := p.fetchOnDemandPricing(
+	prices, _, err := p.onDemandPricing(ctx, p.filters())
+	return prices, err
+}
+
+// GetOnDemandPricingWithEffectiveDates returns the on-demand prices with the most recent effective date of the price
+// list entries of each instance type.
+func (p *AWSProvider) GetOnDemandPricingWithEffectiveDates(
+	ctx context.Context,
+) (OnDemandPriceList, EffectiveDates, error) {
+	return p.onDemandPricing(ctx, p.filters())
+}
+
+// GetLicensedOnDemandPricing returns the on-demand prices of instances running the operating system of licenseModel
+// with its license fee included. License models without a license fee use the regular on-demand prices.
+func (p *AWSProvider) GetLicensedOnDemandPricing(
+	ctx context.Context,
+	licenseModel LicenseModel,
+) (OnDemandPriceList, error) {
+	if !licenseModel.IncludesLicense() {
+		return p.GetOnDemandPricing(ctx)
+	}
+	filters := p.filters()
+	filters.OperatingSystem = licenseModel.operatingSystem()
+	prices, _, err := p.onDemandPricing(ctx, filters, pricingtypes.Filter{
+		Field: aws.String("licenseModel"),
+		Type:  pricingtypes.FilterTypeTermMatch,
+		Value: aws.String("No License required"),
+	})
+	return prices, err
+}
+
+func (p *AWSProvider) onDemandPricing(
+	ctx context.Context,
+	filters PricingFilters,
+	additionalFilters ...pricingtypes.Filter,
+) (OnDemandPriceList, EffectiveDates, error) {
+	onDemand := pricingtypes.Filter{
+		Field: aws.String("marketoption"),
+		Type:  pricingtypes.FilterTypeTermMatch,
+		Value: aws.String("OnDemand"),
+	}
+	onDemandPrices, onDemandDates, err := p.fetchEC2Pricing(
 		ctx,
-		pricingtypes.Filter{
-			Field: aws.String("tenancy"),
-			Type:  pricingtypes.FilterTypeTermMatch,
-			Value: aws.String("Shared"),
-		},
-		pricingtypes.Filter{
-			Field: aws.String("productFamily"),
-			Type:  pricingtypes.FilterTypeTermMatch,
-			Value: aws.String("Compute Instance"),
-		},
+		filters,
+		append([]pricingtypes.Filter{
+			onDemand,
+			{
+				Field: aws.String("tenancy"),
+				Type:  pricingtypes.FilterTypeTermMatch,
+				Value: aws.String(filters.SharedTenancy),
+			},
+			{
+				Field: aws.String("productFamily"),
+				Type:  pricingtypes.FilterTypeTermMatch,
+				Value: aws.String(filters.SharedProductFamily),
+			},
+		}, additionalFilters...)...,
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	onDemandMetalPrices, err := p.fetchOnDemandPricing(
+	onDemandMetalPrices, onDemandMetalDates, err := p.fetchEC2Pricing(
 		ctx,
+		filters,
+		append([]pricingtypes.Filter{
+			onDemand,
+			{
+				Field: aws.String("tenancy"),
+				Type:  pricingtypes.FilterTypeTermMatch,
+				Value: aws.String(filters.MetalTenancy),
+			},
+			{
+				Field: aws.String("productFamily"),
+				Type:  pricingtypes.FilterTypeTermMatch,
+				Value: aws.String(filters.MetalProductFamily),
+			},
+		}, additionalFilters...)...,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(onDemandPrices) == 0 || len(onDemandMetalPrices) == 0 {
+		return nil, nil, errors.New("no on-demand pricing found")
+	}
+	return lo.Assign(onDemandPrices, onDemandMetalPrices), lo.Assign(onDemandDates, onDemandMetalDates), nil
+}
+
+// GetCapacityBlockPricing returns the hourly price of EC2 Capacity Blocks for ML by instance type. Capacity Blocks are
+// only offered for a few instance types in a few regions, so an empty list is not considered an error.
+func (p *AWSProvider) GetCapacityBlockPricing(ctx context.Context) (CapacityBlockPriceList, error) {
+	prices, _, err := p.fetchEC2Pricing(
+		ctx,
+		p.filters(),
 		pricingtypes.Filter{
-			Field: aws.String("tenancy"),
-			Type:  pricingtypes.FilterTypeTermMatch,
-			Value: aws.String("Dedicated"),
-		},
-		pricingtypes.Filter{
-			Field: aws.String("productFamily"),
+			Field: aws.String("marketoption"),
 			Type:  pricingtypes.FilterTypeTermMatch,
-			Value: aws.String("Compute Instance (bare metal)"),
+			Value: aws.String("CapacityBlock"),
 		},
 	)
 	if err != nil {
 		return nil, err
 	}
-	if len(onDemandPrices) == 0 || len(onDemandMetalPrices) == 0 {
-		return nil, errors.New("no on-demand pricing found")
-	}
-	return lo.Assign(onDemandPrices, onDemandMetalPrices), nil
+	return prices, nil
 }
 
+// GetSpotPricing returns the most recent spot price of each instance type in each zone. If there are prices for more
+// than one product description for the same instance type and zone, the most recent spot price for
+// "Linux/UNIX (Amazon VPC)" is used regardless of the order the records are returned in.
 func (p *AWSProvider) GetSpotPricing(ctx context.Context) (SpotPriceList, error) {
-	prices := make(SpotPriceList)
+	return p.spotPricing(ctx, LicenseModelNone)
+}
 
-	spotPriceHistoryPaginator := ec2.NewDescribeSpotPriceHistoryPaginator(
-		p.EC2Client,
-		&ec2.DescribeSpotPriceHistoryInput{
-			ProductDescriptions: []string{"Linux/UNIX", "Linux/UNIX (Amazon VPC)"},
-			StartTime:           aws.Time(time.Now()),
-		},
-	)
-	for spotPriceHistoryPaginator.HasMorePages() {
+// GetLicensedSpotPricing returns the most recent spot price of each instance type in each zone for the operating
+// system of licenseModel, preferring its VPC product description like GetSpotPricing.
+func (p *AWSProvider) GetLicensedSpotPricing(ctx context.Context, licenseModel LicenseModel) (SpotPriceList, error) {
+	return p.spotPricing(ctx, licenseModel)
+}
+
+// spotPricing returns the spot prices of the product descriptions of licenseModel. Both describe the same operating
+// system, but EC2-Classic is retired so the VPC one is the price actually charged and takes precedence when they
+// differ.
+func (p *AWSProvider) spotPricing(ctx context.Context, licenseModel LicenseModel) (SpotPriceList, error) {
+	classicDescription, vpcDescription := licenseModel.spotProductDescriptions()
+	history := newSpotPriceHistory(vpcDescription)
+	input := &ec2.DescribeSpotPriceHistoryInput{
+		ProductDescriptions: []string{classicDescription, vpcDescription},
+		StartTime:           aws.Time(time.Now()),
+	}
+	p.zonesMu.RLock()
+	zones := p.Zones
+	p.zonesMu.RUnlock()
+	if len(zones) != 0 {
+		input.Filters = []ec2types.Filter{{Name: aws.String("availability-zone"), Values: zones}}
+	}
+	spotPriceHistoryPaginator := ec2.NewDescribeSpotPriceHistoryPaginator(p.EC2Client, input)
+	for page := 1; spotPriceHistoryPaginator.HasMorePages(); page++ {
+		if p.MaxSpotPricePages > 0 && page > p.MaxSpotPricePages {
+			log.Printf("stopping spot price history pagination after %d pages", p.MaxSpotPricePages)
+			break
+		}
 		output, err := spotPriceHistoryPaginator.NextPage(ctx)
 		if err != nil {
 			return nil, err
 		}
 		for _, sph := range output.SpotPriceHistory {
-			spotPriceStr := aws.ToString(sph.SpotPrice)
-			spotPrice, err := strconv.ParseFloat(spotPriceStr, 64)
-			// these errors shouldn't occur, but if pricing API does have an error, we ignore the record
-			if err != nil {
-				log.Printf("unable to parse price record %#v", sph)
-				continue
-			}
-			if sph.Timestamp == nil {
-				continue
-			}
-			instanceType := string(sph.InstanceType)
-			az := aws.ToString(sph.AvailabilityZone)
-			_, ok := prices[instanceType]
-			if !ok {
-				prices[instanceType] = map[string]float64{}
-			}
-			prices[instanceType][az] = spotPrice
+			history.add(sph)
 		}
 	}
-	if len(prices) == 0 {
+	if len(history.prices) == 0 {
 		return nil, errors.New("no spot pricing found")
 	}
-	return prices, nil
+	return history.prices, nil
+}
+
+// spotPriceHistory is the spot prices of spot price history records along with the timestamp and product description
+// of the record each price came from, so that only the preferred record is kept.
+type spotPriceHistory struct {
+	vpcDescription string
+	prices         SpotPriceList
+	timestamps     map[string]map[string]time.Time
+	vpc            map[string]map[string]bool
+}
+
+func newSpotPriceHistory(vpcDescription string) *spotPriceHistory {
+	return &spotPriceHistory{
+		vpcDescription: vpcDescription,
+		prices:         make(SpotPriceList),
+		timestamps:     map[string]map[string]time.Time{},
+		vpc:            map[string]map[string]bool{},
+	}
+}
+
+// add keeps the price of sph unless there already is a price from a VPC record or a newer record of the same product
+// description.
+func (h *spotPriceHistory) add(sph ec2types.SpotPrice) {
+	spotPriceStr := aws.ToString(sph.SpotPrice)
+	spotPrice, err := strconv.ParseFloat(spotPriceStr, 64)
+	// these errors shouldn't occur, but if pricing API does have an error, we ignore the record
+	if err != nil {
+		log.Printf("unable to parse price record %#v", sph)
+		return
+	}
+	if sph.Timestamp == nil {
+		return
+	}
+	instanceType := string(sph.InstanceType)
+	az := aws.ToString(sph.AvailabilityZone)
+	_, ok := h.prices[instanceType]
+	if !ok {
+		h.prices[instanceType] = map[string]float64{}
+		h.timestamps[instanceType] = map[string]time.Time{}
+		h.vpc[instanceType] = map[string]bool{}
+	}
+	isVPC := string(sph.ProductDescription) == h.vpcDescription
+	if _, seen := h.prices[instanceType][az]; seen {
+		if h.vpc[instanceType][az] && !isVPC {
+			return
+		}
+		if h.vpc[instanceType][az] == isVPC && !sph.Timestamp.After(h.timestamps[instanceType][az]) {
+			return
+		}
+	}
+	h.prices[instanceType][az] = spotPrice
+	h.timestamps[instanceType][az] = *sph.Timestamp
+	h.vpc[instanceType][az] = isVPC
 }
 
 func (p *AWSProvider) GetFargatePricing(ctx context.Context) (FargatePrice, error) {
+	return p.GetRegionalFargatePricing(ctx, p.Region)
+}
+
+// GetRegionalFargatePricing returns the Fargate pricing of region, which needs to be in the same partition as the
+// region of the provider.
+func (p *AWSProvider) GetRegionalFargatePricing(ctx context.Context, region string) (FargatePrice, error) {
 	price := &FargatePrice{}
 	filters := []pricingtypes.Filter{
 		{
 			Field: aws.String("regionCode"),
 			Type:  pricingtypes.FilterTypeTermMatch,
-			Value: aws.String(p.Region),
+			Value: aws.String(region),
 		},
 	}
 	productsPaginator := pricing.NewGetProductsPaginator(p.PricingClient, &pricing.GetProductsInput{
@@ -149,11 +504,86 @@ func (p *AWSProvider) GetFargatePricing(ctx context.Context) (FargatePrice, erro
 	return *price, nil
 }
 
-func (p *AWSProvider) fetchOnDemandPricing(
+// GetControlPlanePricing returns the standard support hourly fee charged per EKS cluster.
+func (p *AWSProvider) GetControlPlanePricing(ctx context.Context) (float64, error) {
+	productsPaginator := pricing.NewGetProductsPaginator(p.PricingClient, &pricing.GetProductsInput{
+		Filters: []pricingtypes.Filter{
+			{
+				Field: aws.String("regionCode"),
+				Type:  pricingtypes.FilterTypeTermMatch,
+				Value: aws.String(p.Region),
+			},
+		},
+		ServiceCode: aws.String("AmazonEKS"),
+	})
+	for productsPaginator.HasMorePages() {
+		output, err := productsPaginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		price, ok, err := p.parseControlPlanePage(output)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			return price, nil
+		}
+	}
+	return 0, errors.New("no EKS control plane pricing found")
+}
+
+func (p *AWSProvider) GetInstanceSpecs(ctx context.Context) (InstanceSpecList, error) {
+	specs := make(InstanceSpecList)
+
+	instanceTypesPaginator := ec2.NewDescribeInstanceTypesPaginator(p.EC2Client, &ec2.DescribeInstanceTypesInput{})
+	for instanceTypesPaginator.HasMorePages() {
+		output, err := instanceTypesPaginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range output.InstanceTypes {
+			spec := InstanceSpec{}
+			if info.VCpuInfo != nil {
+				spec.VCPUs = aws.ToInt32(info.VCpuInfo.DefaultVCpus)
+			}
+			if info.MemoryInfo != nil {
+				spec.MemoryMiB = aws.ToInt64(info.MemoryInfo.SizeInMiB)
+			}
+			if info.NetworkInfo != nil {
+				spec.NetworkPerformance = aws.ToString(info.NetworkInfo.NetworkPerformance)
+			}
+			if info.EbsInfo != nil && info.EbsInfo.EbsOptimizedInfo != nil {
+				spec.EBSBandwidthMbps = aws.ToInt32(info.EbsInfo.EbsOptimizedInfo.MaximumBandwidthInMbps)
+			}
+			if info.InstanceStorageInfo != nil {
+				spec.InstanceStorageGB = aws.ToInt64(info.InstanceStorageInfo.TotalSizeInGB)
+			}
+			if info.ProcessorInfo != nil {
+				for _, architecture := range info.ProcessorInfo.SupportedArchitectures {
+					spec.Architectures = append(spec.Architectures, string(architecture))
+				}
+			}
+			if info.GpuInfo != nil {
+				for _, gpu := range info.GpuInfo.Gpus {
+					spec.GPUs += aws.ToInt32(gpu.Count)
+				}
+			}
+			specs[string(info.InstanceType)] = spec
+		}
+	}
+	if len(specs) == 0 {
+		return nil, errors.New("no instance type specifications found")
+	}
+	return specs, nil
+}
+
+func (p *AWSProvider) fetchEC2Pricing(
 	ctx context.Context,
+	pricingFilters PricingFilters,
 	additionalFilters ...pricingtypes.Filter,
-) (map[string]float64, error) {
+) (map[string]float64, EffectiveDates, error) {
 	prices := map[string]float64{}
+	effectiveDates := EffectiveDates{}
 	filters := append(
 		[]pricingtypes.Filter{
 			{
@@ -164,51 +594,49 @@ func (p *AWSProvider) fetchOnDemandPricing(
 			{
 				Field: aws.String("serviceCode"),
 				Type:  pricingtypes.FilterTypeTermMatch,
-				Value: aws.String("AmazonEC2"),
+				Value: aws.String(pricingFilters.ServiceCode),
 			},
 			{
 				Field: aws.String("preInstalledSw"),
 				Type:  pricingtypes.FilterTypeTermMatch,
-				Value: aws.String("NA"),
+				Value: aws.String(pricingFilters.PreInstalledSoftware),
 			},
 			{
 				Field: aws.String("operatingSystem"),
 				Type:  pricingtypes.FilterTypeTermMatch,
-				Value: aws.String("Linux"),
+				Value: aws.String(pricingFilters.OperatingSystem),
 			},
 			{
 				Field: aws.String("capacitystatus"),
 				Type:  pricingtypes.FilterTypeTermMatch,
-				Value: aws.String("Used"),
-			},
-			{
-				Field: aws.String("marketoption"),
-				Type:  pricingtypes.FilterTypeTermMatch,
-				Value: aws.String("OnDemand"),
+				Value: aws.String(pricingFilters.CapacityStatus),
 			},
 		},
 		additionalFilters...,
 	)
 	productsPaginator := pricing.NewGetProductsPaginator(p.PricingClient, &pricing.GetProductsInput{
 		Filters:     filters,
-		ServiceCode: aws.String("AmazonEC2"),
+		ServiceCode: aws.String(pricingFilters.ServiceCode),
 	})
 	for productsPaginator.HasMorePages() {
 		output, err := productsPaginator.NextPage(ctx)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		prices, err = p.parseOnDemandPage(prices, output)
+		prices, err = p.parseOnDemandPage(prices, effectiveDates, output)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
-	return prices, nil
+	return prices, effectiveDates, nil
 }
 
+// parseOnDemandPage adds the hourly prices of a page of EC2 products to prices and the most recent effective date of
+// the prices of each instance type to effectiveDates.
 func (p *AWSProvider) parseOnDemandPage(
 	prices map[string]float64,
+	effectiveDates EffectiveDates,
 	output *pricing.GetProductsOutput,
 ) (map[string]float64, error) {
 	// this isn't the full pricing struct, just the portions we care about
@@ -220,10 +648,10 @@ func (p *AWSProvider) parseOnDemandPage(
 		}
 		Terms struct {
 			OnDemand map[string]struct {
+				EffectiveDate   string
 				PriceDimensions map[string]struct {
-					PricePerUnit struct {
-						USD string
-					}
+					Unit         string
+					PricePerUnit pricePerUnit
 				}
 			}
 		}
@@ -240,11 +668,27 @@ func (p *AWSProvider) parseOnDemandPage(
 		}
 		for _, term := range pItem.Terms.OnDemand {
 			for _, v := range term.PriceDimensions {
-				price, err := strconv.ParseFloat(v.PricePerUnit.USD, 64)
+				// guards against importing e.g. a per second or per GB dimension as the hourly price
+				if v.Unit != hourlyUnit {
+					log.Printf(
+						"skipping on-demand price of %s in %s per %q, expected per %s",
+						pItem.Product.Attributes.InstanceType,
+						v.PricePerUnit.currency(),
+						v.Unit,
+						hourlyUnit,
+					)
+					continue
+				}
+				price, err := strconv.ParseFloat(v.PricePerUnit.amount(), 64)
 				if err != nil || price == 0 {
 					continue
 				}
 				prices[pItem.Product.Attributes.InstanceType] = price
+				// a missing or malformed effective date only leaves the date unknown
+				effectiveDate, err := time.Parse(time.RFC3339, term.EffectiveDate)
+				if err == nil && effectiveDate.After(effectiveDates[pItem.Product.Attributes.InstanceType]) {
+					effectiveDates[pItem.Product.Attributes.InstanceType] = effectiveDate
+				}
 			}
 		}
 	}
@@ -267,9 +711,7 @@ func (p *AWSProvider) parseFargatePage(
 		Terms struct {
 			OnDemand map[string]struct {
 				PriceDimensions map[string]struct {
-					PricePerUnit struct {
-						USD string
-					}
+					PricePerUnit pricePerUnit
 				}
 			}
 		}
@@ -287,11 +729,22 @@ func (p *AWSProvider) parseFargatePage(
 		name := pItem.Product.Attributes.UsageType
 		for _, term := range pItem.Terms.OnDemand {
 			for _, v := range term.PriceDimensions {
-				price, err := strconv.ParseFloat(v.PricePerUnit.USD, 64)
+				price, err := strconv.ParseFloat(v.PricePerUnit.amount(), 64)
 				if err != nil || price == 0 {
 					continue
 				}
-				if strings.Contains(name, "vCPU-Hours") {
+				if strings.Contains(name, "Windows") {
+					// e.g. USE1-Fargate-Windows-vCPU-Hours:perCPU or USE1-Fargate-Windows-OS-Hours:perCPU
+					if strings.Contains(name, "OS-Hours") {
+						fargatePrice.WindowsOSPerVCPUHour = price
+					} else if strings.Contains(name, "vCPU-Hours") {
+						fargatePrice.WindowsVCPUPerHour = price
+					} else if strings.Contains(name, "GB-Hours") {
+						fargatePrice.WindowsGBPerHour = price
+					} else {
+						return nil, fmt.Errorf("unsupported fargate price information found: %s", name)
+					}
+				} else if strings.Contains(name, "vCPU-Hours") {
 					fargatePrice.VCPUPerHour = price
 				} else if strings.Contains(name, "GB-Hours") {
 					fargatePrice.GBPerHour = price
@@ -303,3 +756,43 @@ func (p *AWSProvider) parseFargatePage(
 	}
 	return fargatePrice, nil
 }
+
+func (p *AWSProvider) parseControlPlanePage(output *pricing.GetProductsOutput) (float64, bool, error) {
+	// this isn't the full pricing struct, just the portions we care about
+	type priceItem struct {
+		Product struct {
+			Attributes struct {
+				UsageType string
+			}
+		}
+		Terms struct {
+			OnDemand map[string]struct {
+				PriceDimensions map[string]struct {
+					PricePerUnit pricePerUnit
+				}
+			}
+		}
+	}
+
+	for _, outer := range output.PriceList {
+		var pItem priceItem
+		err := json.Unmarshal([]byte(outer), &pItem)
+		if err != nil {
+			return 0, false, fmt.Errorf("decoding: %w", err)
+		}
+		// e.g. USE1-AmazonEKS-Hours:perCluster, which excludes the extended support fee
+		if !strings.HasSuffix(pItem.Product.Attributes.UsageType, "AmazonEKS-Hours:perCluster") {
+			continue
+		}
+		for _, term := range pItem.Terms.OnDemand {
+			for _, v := range term.PriceDimensions {
+				price, err := strconv.ParseFloat(v.PricePerUnit.amount(), 64)
+				if err != nil || price == 0 {
+					continue
+				}
+				return price, true, nil
+			}
+		}
+	}
+	return 0, false, nil
+}
@@ -0,0 +1,781 @@
+package pricing_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	awspricing "github.com/aws/aws-sdk-go-v2/service/pricing"
+	"github.com/aws/smithy-go"
+
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing"
+)
+
+type testPricingClient struct {
+	priceList []string
+	inputs    []*awspricing.GetProductsInput
+}
+
+func (c *testPricingClient) GetProducts(
+	_ context.Context,
+	input *awspricing.GetProductsInput,
+	_ ...func(*awspricing.Options),
+) (*awspricing.GetProductsOutput, error) {
+	c.inputs = append(c.inputs, input)
+	return &awspricing.GetProductsOutput{
+		PriceList: c.priceList,
+	}, nil
+}
+
+func filterValue(input *awspricing.GetProductsInput, field string) string {
+	for _, filter := range input.Filters {
+		if aws.ToString(filter.Field) == field {
+			return aws.ToString(filter.Value)
+		}
+	}
+	return ""
+}
+
+const capacityBlockFixture = `{
+	"product": {
+		"productFamily": "Compute Instance",
+		"attributes": {
+			"instanceType": "p5.48xlarge",
+			"marketoption": "CapacityBlock",
+			"regionCode": "us-east-2",
+			"usagetype": "USE2-CapacityBlock:p5.48xlarge"
+		}
+	},
+	"terms": {
+		"OnDemand": {
+			"ABCDEFGHIJKLMNOP.JRTCKXETXF": {
+				"priceDimensions": {
+					"ABCDEFGHIJKLMNOP.JRTCKXETXF.6YS6EN2CT7": {
+						"unit": "Hrs",
+						"pricePerUnit": {"USD": "31.4640000000"}
+					}
+				}
+			}
+		}
+	}
+}`
+
+func TestAWSProviderGetCapacityBlockPricing(t *testing.T) {
+	client := &testPricingClient{priceList: []string{capacityBlockFixture}}
+	provider := &pricing.AWSProvider{
+		Region:        "us-east-2",
+		PricingClient: client,
+	}
+
+	prices, err := provider.GetCapacityBlockPricing(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp, got := 31.464, prices["p5.48xlarge"]; exp != got {
+		t.Errorf("expected p5.48xlarge capacity block price == %f, got %f", exp, got)
+	}
+	if exp, got := 1, len(client.inputs); exp != got {
+		t.Fatalf("expected %d GetProducts call, got %d", exp, got)
+	}
+	if exp, got := "CapacityBlock", filterValue(client.inputs[0], "marketoption"); exp != got {
+		t.Errorf("expected marketoption filter == %s, got %s", exp, got)
+	}
+	if exp, got := "us-east-2", filterValue(client.inputs[0], "regionCode"); exp != got {
+		t.Errorf("expected regionCode filter == %s, got %s", exp, got)
+	}
+}
+
+func TestAWSProviderCustomFilters(t *testing.T) {
+	client := &testPricingClient{priceList: []string{capacityBlockFixture}}
+	provider := &pricing.AWSProvider{
+		Region:        "us-east-2",
+		PricingClient: client,
+		Filters: pricing.PricingFilters{
+			SharedProductFamily: "Compute Instance (shared)",
+			MetalTenancy:        "Host",
+			CapacityStatus:      "UnusedCapacityReservation",
+		},
+	}
+
+	_, err := provider.GetOnDemandPricing(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp, got := 2, len(client.inputs); exp != got {
+		t.Fatalf("expected %d GetProducts calls, got %d", exp, got)
+	}
+	for field, exp := range map[string]string{
+		"productFamily":   "Compute Instance (shared)",
+		"tenancy":         "Shared",
+		"capacitystatus":  "UnusedCapacityReservation",
+		"operatingSystem": "Linux",
+	} {
+		if got := filterValue(client.inputs[0], field); exp != got {
+			t.Errorf("expected %s filter == %s, got %s", field, exp, got)
+		}
+	}
+	for field, exp := range map[string]string{
+		"productFamily":  "Compute Instance (bare metal)",
+		"tenancy":        "Host",
+		"capacitystatus": "UnusedCapacityReservation",
+	} {
+		if got := filterValue(client.inputs[1], field); exp != got {
+			t.Errorf("expected %s filter == %s, got %s", field, exp, got)
+		}
+	}
+	if exp, got := "AmazonEC2", aws.ToString(client.inputs[0].ServiceCode); exp != got {
+		t.Errorf("expected service code == %s, got %s", exp, got)
+	}
+}
+
+func TestParsePricingFilters(t *testing.T) {
+	filters, err := pricing.ParsePricingFilters([]string{"operatingSystem=RHEL", "metalProductFamily=Compute Instance (metal)"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	exp := pricing.PricingFilters{OperatingSystem: "RHEL", MetalProductFamily: "Compute Instance (metal)"}
+	if filters != exp {
+		t.Errorf("expected filters %+v, got %+v", exp, filters)
+	}
+
+	for _, override := range []string{"operatingSystem", "os=Linux", "capacityStatus="} {
+		if _, err := pricing.ParsePricingFilters([]string{override}); err == nil {
+			t.Errorf("expected an error parsing %q", override)
+		}
+	}
+}
+
+func TestAWSProviderGetOnDemandPricingSkipsNonHourlyUnits(t *testing.T) {
+	hourly := strings.Replace(capacityBlockFixture, "p5.48xlarge", "m5.large", -1)
+	hourly = strings.Replace(hourly, `{"USD": "31.4640000000"}`, `{"USD": "0.0960000000"}`, 1)
+	perSecond := strings.Replace(capacityBlockFixture, "p5.48xlarge", "m5.xlarge", -1)
+	perSecond = strings.Replace(perSecond, `"unit": "Hrs"`, `"unit": "Second"`, 1)
+	provider := &pricing.AWSProvider{
+		Region:        "us-east-1",
+		PricingClient: &testPricingClient{priceList: []string{hourly, perSecond}},
+	}
+
+	prices, err := provider.GetOnDemandPricing(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp, got := 0.096, prices["m5.large"]; exp != got {
+		t.Errorf("expected m5.large on-demand price == %f, got %f", exp, got)
+	}
+	if price, ok := prices["m5.xlarge"]; ok {
+		t.Errorf("expected no m5.xlarge on-demand price for a per second unit, got %f", price)
+	}
+}
+
+func TestAWSProviderGetOnDemandPricingWithEffectiveDates(t *testing.T) {
+	onDemand := func(instanceType string, effectiveDate string) string {
+		item := strings.Replace(capacityBlockFixture, "p5.48xlarge", instanceType, -1)
+		return strings.Replace(
+			item,
+			`"priceDimensions": {`,
+			`"effectiveDate": "`+effectiveDate+`", "priceDimensions": {`,
+			1,
+		)
+	}
+	provider := &pricing.AWSProvider{
+		Region: "us-east-1",
+		PricingClient: &testPricingClient{priceList: []string{
+			onDemand("m5.large", "2024-03-01T00:00:00Z"),
+			// the most recent of the entries of an instance type is used
+			onDemand("m5.large", "2024-05-01T00:00:00Z"),
+			onDemand("m5.large", "2023-11-01T00:00:00Z"),
+			onDemand("m5.xlarge", "not a date"),
+		}},
+	}
+
+	_, dates, err := provider.GetOnDemandPricingWithEffectiveDates(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp, got := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC), dates["m5.large"]; !exp.Equal(got) {
+		t.Errorf("expected m5.large effective date == %s, got %s", exp, got)
+	}
+	if date, ok := dates["m5.xlarge"]; ok {
+		t.Errorf("expected no m5.xlarge effective date for a malformed date, got %s", date)
+	}
+}
+
+const controlPlaneFixture = `{
+	"product": {
+		"productFamily": "Compute",
+		"attributes": {
+			"regionCode": "us-east-1",
+			"servicecode": "AmazonEKS",
+			"usagetype": "USE1-AmazonEKS-Hours:perCluster",
+			"operation": "CreateOperation"
+		}
+	},
+	"terms": {
+		"OnDemand": {
+			"QRSTUVWXYZABCDEF.JRTCKXETXF": {
+				"priceDimensions": {
+					"QRSTUVWXYZABCDEF.JRTCKXETXF.6YS6EN2CT7": {
+						"unit": "Hours",
+						"pricePerUnit": {"USD": "0.1000000000"}
+					}
+				}
+			}
+		}
+	}
+}`
+
+const extendedSupportFixture = `{
+	"product": {
+		"productFamily": "Compute",
+		"attributes": {
+			"regionCode": "us-east-1",
+			"servicecode": "AmazonEKS",
+			"usagetype": "USE1-AmazonEKS-Hours:extendedSupport",
+			"operation": "ExtendedSupport"
+		}
+	},
+	"terms": {
+		"OnDemand": {
+			"GHIJKLMNOPQRSTUV.JRTCKXETXF": {
+				"priceDimensions": {
+					"GHIJKLMNOPQRSTUV.JRTCKXETXF.6YS6EN2CT7": {
+						"unit": "Hours",
+						"pricePerUnit": {"USD": "0.6000000000"}
+					}
+				}
+			}
+		}
+	}
+}`
+
+func TestAWSProviderGetControlPlanePricing(t *testing.T) {
+	client := &testPricingClient{priceList: []string{extendedSupportFixture, controlPlaneFixture}}
+	provider := &pricing.AWSProvider{
+		Region:        "us-east-1",
+		PricingClient: client,
+	}
+
+	price, err := provider.GetControlPlanePricing(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp, got := 0.1, price; exp != got {
+		t.Errorf("expected control plane price == %f, got %f", exp, got)
+	}
+	if exp, got := "AmazonEKS", aws.ToString(client.inputs[0].ServiceCode); exp != got {
+		t.Errorf("expected service code == %s, got %s", exp, got)
+	}
+
+	client = &testPricingClient{priceList: []string{extendedSupportFixture}}
+	provider.PricingClient = client
+	if _, err := provider.GetControlPlanePricing(context.Background()); err == nil {
+		t.Errorf("expected an error without a cluster-hours price")
+	}
+}
+
+func fargateFixture(usageType string, price string) string {
+	return fmt.Sprintf(`{
+	"product": {
+		"productFamily": "Compute",
+		"attributes": {
+			"regionCode": "us-east-1",
+			"usagetype": "%s"
+		}
+	},
+	"terms": {
+		"OnDemand": {
+			"ABCDEFGHIJKLMNOP.JRTCKXETXF": {
+				"priceDimensions": {
+					"ABCDEFGHIJKLMNOP.JRTCKXETXF.6YS6EN2CT7": {
+						"unit": "hours",
+						"pricePerUnit": {"USD": "%s"}
+					}
+				}
+			}
+		}
+	}
+}`, usageType, price)
+}
+
+func TestAWSProviderGetFargatePricingWindows(t *testing.T) {
+	client := &testPricingClient{priceList: []string{
+		fargateFixture("USE1-Fargate-vCPU-Hours:perCPU", "0.0404800000"),
+		fargateFixture("USE1-Fargate-GB-Hours", "0.0044450000"),
+		fargateFixture("USE1-Fargate-Windows-vCPU-Hours:perCPU", "0.0465520000"),
+		fargateFixture("USE1-Fargate-Windows-GB-Hours", "0.0051117500"),
+		fargateFixture("USE1-Fargate-Windows-OS-Hours:perCPU", "0.0460000000"),
+	}}
+	provider := &pricing.AWSProvider{
+		Region:        "us-east-1",
+		PricingClient: client,
+	}
+
+	price, err := provider.GetFargatePricing(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := pricing.FargatePrice{
+		VCPUPerHour:          0.04048,
+		GBPerHour:            0.004445,
+		WindowsVCPUPerHour:   0.046552,
+		WindowsGBPerHour:     0.00511175,
+		WindowsOSPerVCPUHour: 0.046,
+	}
+	if expected != price {
+		t.Errorf("expected fargate price == %+v, got %+v", expected, price)
+	}
+}
+
+type testEC2Client struct {
+	spotPricePages [][]ec2types.SpotPrice
+	spotPriceCalls int
+	// productDescriptions and filters are those of the last spot price history request
+	productDescriptions []string
+	filters             []ec2types.Filter
+}
+
+func (c *testEC2Client) DescribeSpotPriceHistory(
+	_ context.Context,
+	input *ec2.DescribeSpotPriceHistoryInput,
+	_ ...func(*ec2.Options),
+) (*ec2.DescribeSpotPriceHistoryOutput, error) {
+	c.spotPriceCalls++
+	c.productDescriptions = input.ProductDescriptions
+	c.filters = input.Filters
+	page := 0
+	if input.NextToken != nil {
+		page, _ = strconv.Atoi(*input.NextToken)
+	}
+	output := &ec2.DescribeSpotPriceHistoryOutput{}
+	if page < len(c.spotPricePages) {
+		output.SpotPriceHistory = c.spotPricePages[page]
+	}
+	if page+1 < len(c.spotPricePages) {
+		output.NextToken = aws.String(strconv.Itoa(page + 1))
+	}
+	return output, nil
+}
+
+func (c *testEC2Client) DescribeInstanceTypes(
+	_ context.Context,
+	_ *ec2.DescribeInstanceTypesInput,
+	_ ...func(*ec2.Options),
+) (*ec2.DescribeInstanceTypesOutput, error) {
+	return &ec2.DescribeInstanceTypesOutput{}, nil
+}
+
+func testSpotPrice(instanceType string, zone string, price string, timestamp time.Time) ec2types.SpotPrice {
+	return ec2types.SpotPrice{
+		InstanceType:       ec2types.InstanceType(instanceType),
+		AvailabilityZone:   aws.String(zone),
+		SpotPrice:          aws.String(price),
+		Timestamp:          aws.Time(timestamp),
+		ProductDescription: ec2types.RIProductDescription("Linux/UNIX"),
+	}
+}
+
+func TestAWSProviderGetSpotPricingReadsAllPages(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-time.Hour)
+	client := &testEC2Client{
+		spotPricePages: [][]ec2types.SpotPrice{
+			{
+				testSpotPrice("m5.large", "us-east-1a", "0.035", now),
+				testSpotPrice("m5.large", "us-east-1b", "0.036", now),
+			},
+			// a page of only instance types and zones seen before doesn't end the pagination
+			{
+				testSpotPrice("m5.large", "us-east-1a", "0.030", old),
+			},
+			{
+				testSpotPrice("m5.xlarge", "us-east-1a", "0.070", now),
+			},
+		},
+	}
+	provider := &pricing.AWSProvider{
+		Region:    "us-east-1",
+		EC2Client: client,
+	}
+
+	prices, err := provider.GetSpotPricing(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp, got := 3, client.spotPriceCalls; exp != got {
+		t.Errorf("expected %d DescribeSpotPriceHistory calls, got %d", exp, got)
+	}
+	for _, tc := range []struct {
+		instanceType string
+		zone         string
+		price        float64
+	}{
+		{"m5.large", "us-east-1a", 0.035},
+		{"m5.large", "us-east-1b", 0.036},
+		{"m5.xlarge", "us-east-1a", 0.070},
+	} {
+		if got := prices[tc.instanceType][tc.zone]; tc.price != got {
+			t.Errorf("expected %s in %s == %f, got %f", tc.instanceType, tc.zone, tc.price, got)
+		}
+	}
+}
+
+func TestAWSProviderGetSpotPricingPrefersVPC(t *testing.T) {
+	now := time.Now()
+	testVPCSpotPrice := func(instanceType string, zone string, price string, timestamp time.Time) ec2types.SpotPrice {
+		sp := testSpotPrice(instanceType, zone, price, timestamp)
+		sp.ProductDescription = ec2types.RIProductDescriptionLinuxUnixAmazonVpc
+		return sp
+	}
+	for name, records := range map[string][]ec2types.SpotPrice{
+		"vpc first": {
+			testVPCSpotPrice("m5.large", "us-east-1a", "0.035", now.Add(-time.Minute)),
+			testSpotPrice("m5.large", "us-east-1a", "0.040", now),
+		},
+		"vpc last": {
+			testSpotPrice("m5.large", "us-east-1a", "0.040", now),
+			testVPCSpotPrice("m5.large", "us-east-1a", "0.035", now.Add(-time.Minute)),
+		},
+		"newer vpc": {
+			testVPCSpotPrice("m5.large", "us-east-1a", "0.030", now.Add(-time.Hour)),
+			testSpotPrice("m5.large", "us-east-1a", "0.040", now),
+			testVPCSpotPrice("m5.large", "us-east-1a", "0.035", now.Add(-time.Minute)),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			provider := &pricing.AWSProvider{
+				Region:    "us-east-1",
+				EC2Client: &testEC2Client{spotPricePages: [][]ec2types.SpotPrice{records}},
+			}
+			prices, err := provider.GetSpotPricing(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if exp, got := 0.035, prices["m5.large"]["us-east-1a"]; exp != got {
+				t.Errorf("expected m5.large in us-east-1a == %f, got %f", exp, got)
+			}
+		})
+	}
+}
+
+func TestAWSProviderGetSpotPricingVPCOnLaterPages(t *testing.T) {
+	now := time.Now()
+	vpcSpotPrice := func(zone string, price string) ec2types.SpotPrice {
+		sp := testSpotPrice("m5.large", zone, price, now.Add(-time.Minute))
+		sp.ProductDescription = ec2types.RIProductDescriptionLinuxUnixAmazonVpc
+		return sp
+	}
+	client := &testEC2Client{
+		spotPricePages: [][]ec2types.SpotPrice{
+			{
+				testSpotPrice("m5.large", "us-east-1a", "0.040", now),
+				testSpotPrice("m5.large", "us-east-1b", "0.040", now),
+			},
+			{vpcSpotPrice("us-east-1a", "0.035")},
+			{vpcSpotPrice("us-east-1b", "0.036")},
+		},
+	}
+	provider := &pricing.AWSProvider{
+		Region:    "us-east-1",
+		EC2Client: client,
+	}
+	prices, err := provider.GetSpotPricing(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for zone, exp := range map[string]float64{"us-east-1a": 0.035, "us-east-1b": 0.036} {
+		if got := prices["m5.large"][zone]; exp != got {
+			t.Errorf("expected m5.large in %s == %f, got %f", zone, exp, got)
+		}
+	}
+}
+
+func TestAWSProviderGetLicensedSpotPricing(t *testing.T) {
+	now := time.Now()
+	windowsSpotPrice := testSpotPrice("m5.large", "us-east-1a", "0.125", now)
+	windowsSpotPrice.ProductDescription = ec2types.RIProductDescriptionWindowsAmazonVpc
+	classicSpotPrice := testSpotPrice("m5.large", "us-east-1a", "0.150", now)
+	classicSpotPrice.ProductDescription = ec2types.RIProductDescriptionWindows
+	records := []ec2types.SpotPrice{windowsSpotPrice, classicSpotPrice}
+	client := &testEC2Client{spotPricePages: [][]ec2types.SpotPrice{records}}
+	provider := &pricing.AWSProvider{Region: "us-east-1", EC2Client: client}
+
+	prices, err := provider.GetLicensedSpotPricing(context.Background(), pricing.LicenseModelWindows)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp, got := 0.125, prices["m5.large"]["us-east-1a"]; exp != got {
+		t.Errorf("expected windows m5.large in us-east-1a == %f, got %f", exp, got)
+	}
+	exp := []string{"Windows", "Windows (Amazon VPC)"}
+	if got := client.productDescriptions; strings.Join(exp, ",") != strings.Join(got, ",") {
+		t.Errorf("expected product descriptions == %v, got %v", exp, got)
+	}
+}
+
+func TestAWSProviderGetSpotPricingZones(t *testing.T) {
+	records := []ec2types.SpotPrice{testSpotPrice("m5.large", "us-east-1a", "0.035", time.Now())}
+	client := &testEC2Client{spotPricePages: [][]ec2types.SpotPrice{records}}
+	provider := &pricing.AWSProvider{Region: "us-east-1", EC2Client: client}
+
+	if _, err := provider.GetSpotPricing(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(client.filters) != 0 {
+		t.Errorf("expected no filters without zones, got %v", client.filters)
+	}
+
+	provider.ScopeToZones([]string{"us-east-1a", "us-east-1b"})
+	if _, err := provider.GetSpotPricing(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp, got := 1, len(client.filters); exp != got {
+		t.Fatalf("expected %d filter, got %d", exp, got)
+	}
+	filter := client.filters[0]
+	if exp, got := "availability-zone", aws.ToString(filter.Name); exp != got {
+		t.Errorf("expected filter on %s, got %s", exp, got)
+	}
+	if exp, got := "us-east-1a,us-east-1b", strings.Join(filter.Values, ","); exp != got {
+		t.Errorf("expected zones %s, got %s", exp, got)
+	}
+}
+
+func TestAWSProviderGetSpotPricingMaxPages(t *testing.T) {
+	client := &testEC2Client{}
+	for i := 0; i < 10; i++ {
+		client.spotPricePages = append(client.spotPricePages, []ec2types.SpotPrice{
+			testSpotPrice(fmt.Sprintf("m5.%dxlarge", i), "us-east-1a", "0.1", time.Now()),
+		})
+	}
+	provider := &pricing.AWSProvider{
+		Region:            "us-east-1",
+		EC2Client:         client,
+		MaxSpotPricePages: 4,
+	}
+
+	prices, err := provider.GetSpotPricing(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp, got := 4, client.spotPriceCalls; exp != got {
+		t.Errorf("expected %d DescribeSpotPriceHistory calls, got %d", exp, got)
+	}
+	if exp, got := 4, len(prices); exp != got {
+		t.Errorf("expected %d instance types, got %d", exp, got)
+	}
+}
+
+type testHTTPClient struct {
+	requests []*http.Request
+}
+
+func (c *testHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	c.requests = append(c.requests, req)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/x-amz-json-1.1"}},
+		Body:       io.NopCloser(strings.NewReader(`{"FormatVersion":"aws_v1","PriceList":[]}`)),
+		Request:    req,
+	}, nil
+}
+
+func TestAWSProviderUsesConfiguredHTTPClient(t *testing.T) {
+	client := &testHTTPClient{}
+	provider := pricing.NewAWSProvider(aws.Config{
+		Region:      "eu-west-1",
+		Credentials: aws.AnonymousCredentials{},
+		HTTPClient:  client,
+	})
+
+	if _, err := provider.GetFargatePricing(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp, got := 1, len(client.requests); exp != got {
+		t.Fatalf("expected %d requests through the configured HTTP client, got %d", exp, got)
+	}
+	if exp, got := "api.pricing.us-east-1.amazonaws.com", client.requests[0].URL.Host; exp != got {
+		t.Errorf("expected request to %s, got %s", exp, got)
+	}
+}
+
+func TestAWSProviderGetRegionalFargatePricing(t *testing.T) {
+	client := &testPricingClient{}
+	provider := &pricing.AWSProvider{
+		Region:        "us-east-1",
+		PricingClient: client,
+	}
+
+	if _, err := provider.GetRegionalFargatePricing(context.Background(), "eu-west-1"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp, got := "eu-west-1", filterValue(client.inputs[0], "regionCode"); exp != got {
+		t.Errorf("expected regionCode filter == %s, got %s", exp, got)
+	}
+}
+
+func TestAWSProviderCustomEndpoints(t *testing.T) {
+	client := &testHTTPClient{}
+	provider := pricing.NewAWSProvider(
+		aws.Config{
+			Region:      "eu-west-1",
+			Credentials: aws.AnonymousCredentials{},
+			HTTPClient:  client,
+		},
+		pricing.WithPricingEndpoint("http://pricing.internal:4566"),
+		pricing.WithEC2Endpoint("http://ec2.internal:4566"),
+	)
+
+	if _, err := provider.GetFargatePricing(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// the canned pricing response isn't a valid EC2 response, only the request matters
+	_, _ = provider.GetSpotPricing(context.Background())
+	if exp, got := 2, len(client.requests); exp > got {
+		t.Fatalf("expected at least %d requests through the configured HTTP client, got %d", exp, got)
+	}
+	if exp, got := "pricing.internal:4566", client.requests[0].URL.Host; exp != got {
+		t.Errorf("expected pricing request to %s, got %s", exp, got)
+	}
+	if exp, got := "ec2.internal:4566", client.requests[1].URL.Host; exp != got {
+		t.Errorf("expected EC2 request to %s, got %s", exp, got)
+	}
+}
+
+func TestAWSProviderChinaPartition(t *testing.T) {
+	for _, region := range []string{"cn-north-1", "cn-northwest-1"} {
+		client := &testHTTPClient{}
+		provider := pricing.NewAWSProvider(aws.Config{
+			Region:      region,
+			Credentials: aws.AnonymousCredentials{},
+			HTTPClient:  client,
+		})
+
+		if _, err := provider.GetFargatePricing(context.Background()); err != nil {
+			t.Fatalf("%s: unexpected error: %s", region, err)
+		}
+		_, _ = provider.GetSpotPricing(context.Background())
+		if exp, got := 2, len(client.requests); exp > got {
+			t.Fatalf("%s: expected at least %d requests, got %d", region, exp, got)
+		}
+		// the pricing API of the aws partition has no prices for the China regions
+		if exp, got := "api.pricing.cn-northwest-1.amazonaws.com.cn", client.requests[0].URL.Host; exp != got {
+			t.Errorf("%s: expected pricing request to %s, got %s", region, exp, got)
+		}
+		if exp, got := "ec2."+region+".amazonaws.com.cn", client.requests[1].URL.Host; exp != got {
+			t.Errorf("%s: expected EC2 request to %s, got %s", region, exp, got)
+		}
+	}
+}
+
+func TestAWSProviderGetCapacityBlockPricingCNY(t *testing.T) {
+	fixture := strings.Replace(capacityBlockFixture, `{"USD": "31.4640000000"}`, `{"CNY": "226.5408000000"}`, 1)
+	provider := &pricing.AWSProvider{
+		Region:        "cn-northwest-1",
+		PricingClient: &testPricingClient{priceList: []string{fixture}},
+	}
+
+	prices, err := provider.GetCapacityBlockPricing(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp, got := 226.5408, prices["p5.48xlarge"]; exp != got {
+		t.Errorf("expected p5.48xlarge capacity block price == %f, got %f", exp, got)
+	}
+}
+
+type errPricingClient struct {
+	err error
+}
+
+func (c *errPricingClient) GetProducts(
+	context.Context,
+	*awspricing.GetProductsInput,
+	...func(*awspricing.Options),
+) (*awspricing.GetProductsOutput, error) {
+	return nil, c.err
+}
+
+func TestPricingRegionClientUnreachableRegion(t *testing.T) {
+	endpointErr := &smithy.OperationError{
+		ServiceID:     "Pricing",
+		OperationName: "GetProducts",
+		Err: &net.DNSError{
+			Err:        "no such host",
+			Name:       "api.pricing.ap-south-1.amazonaws.com",
+			IsNotFound: true,
+		},
+	}
+	client := pricing.NewPricingRegionClient("ap-southeast-2", &errPricingClient{err: endpointErr}, nil)
+
+	_, err := client.GetProducts(context.Background(), &awspricing.GetProductsInput{})
+	var regionErr *pricing.PricingRegionError
+	if !errors.As(err, &regionErr) {
+		t.Fatalf("expected a PricingRegionError, got %v", err)
+	}
+	if exp, got := "ap-south-1", regionErr.PricingRegion; exp != got {
+		t.Errorf("expected pricing region == %s, got %s", exp, got)
+	}
+	for _, exp := range []string{"ap-south-1", "ap-southeast-2", "us-east-1", "no such host"} {
+		if !strings.Contains(err.Error(), exp) {
+			t.Errorf("expected error to mention %s, got %s", exp, err)
+		}
+	}
+
+	// with a fallback, requests go to it instead
+	fallback := &testPricingClient{priceList: []string{capacityBlockFixture}}
+	client = pricing.NewPricingRegionClient("ap-southeast-2", &errPricingClient{err: endpointErr}, fallback)
+	output, err := client.GetProducts(context.Background(), &awspricing.GetProductsInput{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp, got := 1, len(output.PriceList); exp != got {
+		t.Errorf("expected %d products from the fallback, got %d", exp, got)
+	}
+
+	// other errors, including transient network and credential errors, are returned as is
+	for _, otherErr := range []error{
+		&smithy.GenericAPIError{Code: "ThrottlingException"},
+		&smithy.GenericAPIError{Code: "InvalidClientTokenId"},
+		&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")},
+		&net.DNSError{Err: "i/o timeout", Name: "api.pricing.ap-south-1.amazonaws.com", IsTimeout: true},
+	} {
+		client = pricing.NewPricingRegionClient("ap-southeast-2", &errPricingClient{err: otherErr}, fallback)
+		_, err = client.GetProducts(context.Background(), &awspricing.GetProductsInput{})
+		if !errors.Is(err, otherErr) || errors.As(err, &regionErr) {
+			t.Errorf("expected %v as is, got %v", otherErr, err)
+		}
+	}
+
+	// an opt-in region which isn't enabled falls back too
+	optInErr := &smithy.GenericAPIError{Code: "OptInRequired"}
+	client = pricing.NewPricingRegionClient("ap-southeast-2", &errPricingClient{err: optInErr}, fallback)
+	if _, err = client.GetProducts(context.Background(), &awspricing.GetProductsInput{}); err != nil {
+		t.Errorf("expected an opt-in region to fall back, got %s", err)
+	}
+}
+
+func TestNewProxyHTTPClient(t *testing.T) {
+	proxyURL, _ := url.Parse("http://proxy.internal:3128")
+	client := pricing.NewProxyHTTPClient(proxyURL)
+
+	req := httptest.NewRequest(http.MethodPost, "https://api.pricing.us-east-1.amazonaws.com/", nil)
+	got, err := client.GetTransport().Proxy(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got.String() != proxyURL.String() {
+		t.Errorf("expected proxy %s, got %s", proxyURL, got)
+	}
+}
@@ -0,0 +1,45 @@
+package pricing
+
+import (
+	"sort"
+)
+
+// PriceComparison compares the on-demand price of an instance type with its cheapest spot price. Prices that are not
+// known are left nil.
+type PriceComparison struct {
+	InstanceType string   `json:"instanceType"`
+	OnDemand     *float64 `json:"onDemand"`
+	SpotMin      *float64 `json:"spotMin"`
+	SpotMinZone  string   `json:"spotMinZone,omitempty"`
+	SavingsPct   *float64 `json:"savingsPct"`
+}
+
+// Compare returns a comparison of on-demand and cheapest-zone spot pricing for every known instance type, sorted by
+// instance type.
+func (pr *Repository) Compare() []PriceComparison {
+	spotPrices := pr.SpotPrices()
+	comparisons := []PriceComparison{}
+	for _, instanceType := range pr.InstanceTypes() {
+		comparison := PriceComparison{InstanceType: instanceType}
+		if onDemand, ok := pr.OnDemandPrice(instanceType); ok {
+			comparison.OnDemand = &onDemand
+		}
+		for zone, price := range spotPrices[instanceType] {
+			price := price
+			if comparison.SpotMin == nil || price < *comparison.SpotMin ||
+				(price == *comparison.SpotMin && zone < comparison.SpotMinZone) {
+				comparison.SpotMin = &price
+				comparison.SpotMinZone = zone
+			}
+		}
+		if comparison.OnDemand != nil && comparison.SpotMin != nil && *comparison.OnDemand > 0 {
+			savings := (*comparison.OnDemand - *comparison.SpotMin) / *comparison.OnDemand * 100
+			comparison.SavingsPct = &savings
+		}
+		comparisons = append(comparisons, comparison)
+	}
+	sort.Slice(comparisons, func(i, j int) bool {
+		return comparisons[i].InstanceType < comparisons[j].InstanceType
+	})
+	return comparisons
+}
@@ -0,0 +1,214 @@
+package pricing
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	cetypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// CostExplorerAPI is the subset of the Cost Explorer API used by the Cost Explorer provider.
+type CostExplorerAPI interface {
+	GetCostAndUsage(
+		context.Context,
+		*costexplorer.GetCostAndUsageInput,
+		...func(*costexplorer.Options),
+	) (*costexplorer.GetCostAndUsageOutput, error)
+}
+
+// CostExplorerProvider derives effective on-demand hourly rates from recent Cost Explorer data so that Reserved
+// Instances and Savings Plans applied at the organization level are reflected in the prices. All other pricing is
+// delegated to Fallback.
+//
+// The effective rate of an instance type is its amortized cost divided by its running hours over the lookback period,
+// across all non-spot purchase types. This is an approximation: it is an average over the whole period and across all
+// linked accounts and operating systems, discounts are spread over every instance of the type rather than the ones
+// they were actually applied to, and instance types without usage in the period fall back to the public price.
+type CostExplorerProvider struct {
+	Region   string
+	Client   CostExplorerAPI
+	Fallback Provider
+	// Lookback is how far back to average Cost Explorer data over, defaults to 7 days.
+	Lookback time.Duration
+}
+
+// NewCostExplorerProvider returns a CostExplorerProvider which falls back to fallback for pricing not available from
+// Cost Explorer.
+func NewCostExplorerProvider(cfg aws.Config, fallback Provider) *CostExplorerProvider {
+	// Cost Explorer is only served from us-east-1, or cn-northwest-1 in the China partition
+	costExplorerRegion := "us-east-1"
+	if regionPartition(cfg.Region) == "aws-cn" {
+		costExplorerRegion = "cn-northwest-1"
+	}
+	return &CostExplorerProvider{
+		Region: cfg.Region,
+		Client: costexplorer.NewFromConfig(cfg, func(o *costexplorer.Options) {
+			o.Region = costExplorerRegion
+		}),
+		Fallback: fallback,
+	}
+}
+
+// GetOnDemandPricing returns the public on-demand prices from the fallback provider with the effective rate from Cost
+// Explorer for every instance type that had usage during the lookback period.
+func (p *CostExplorerProvider) GetOnDemandPricing(ctx context.Context) (OnDemandPriceList, error) {
+	prices, _, err := p.GetOnDemandPricingWithEffectiveDates(ctx)
+	return prices, err
+}
+
+// GetOnDemandPricingWithEffectiveDates returns the prices of GetOnDemandPricing with the effective dates of the public
+// prices of the fallback provider. Instance types priced at their effective rate from Cost Explorer have no date.
+func (p *CostExplorerProvider) GetOnDemandPricingWithEffectiveDates(
+	ctx context.Context,
+) (OnDemandPriceList, EffectiveDates, error) {
+	prices, dates, err := onDemandPricingWithEffectiveDates(ctx, p.Fallback)
+	if err != nil {
+		return nil, nil, err
+	}
+	effective, err := p.effectiveRates(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	merged := make(OnDemandPriceList, len(prices)+len(effective))
+	for instanceType, price := range prices {
+		merged[instanceType] = price
+	}
+	for instanceType, price := range effective {
+		merged[instanceType] = price
+		delete(dates, instanceType)
+	}
+	return merged, dates, nil
+}
+
+func (p *CostExplorerProvider) PricingSource(pricingType PricingType) string {
+	if pricingType == PricingTypeOnDemand {
+		return PricingSourceCostExplorer
+	}
+	return pricingSource(p.Fallback, pricingType)
+}
+
+// GetLicensedOnDemandPricing returns the license included prices of the fallback provider, as Cost Explorer effective
+// rates aren't broken down by operating system.
+func (p *CostExplorerProvider) GetLicensedOnDemandPricing(
+	ctx context.Context,
+	licenseModel LicenseModel,
+) (OnDemandPriceList, error) {
+	return licensedOnDemandPricing(ctx, p.Fallback, licenseModel)
+}
+
+func (p *CostExplorerProvider) ScopeToZones(zones []string) {
+	scopeToZones(p.Fallback, zones)
+}
+
+func (p *CostExplorerProvider) GetSpotPricing(ctx context.Context) (SpotPriceList, error) {
+	return p.Fallback.GetSpotPricing(ctx)
+}
+
+func (p *CostExplorerProvider) GetLicensedSpotPricing(
+	ctx context.Context,
+	licenseModel LicenseModel,
+) (SpotPriceList, error) {
+	return licensedSpotPricing(ctx, p.Fallback, licenseModel)
+}
+
+func (p *CostExplorerProvider) GetFargatePricing(ctx context.Context) (FargatePrice, error) {
+	return p.Fallback.GetFargatePricing(ctx)
+}
+
+func (p *CostExplorerProvider) GetRegionalFargatePricing(ctx context.Context, region string) (FargatePrice, error) {
+	return regionalFargatePricing(ctx, p.Fallback, region)
+}
+
+func (p *CostExplorerProvider) GetCapacityBlockPricing(ctx context.Context) (CapacityBlockPriceList, error) {
+	return p.Fallback.GetCapacityBlockPricing(ctx)
+}
+
+func (p *CostExplorerProvider) GetInstanceSpecs(ctx context.Context) (InstanceSpecList, error) {
+	return p.Fallback.GetInstanceSpecs(ctx)
+}
+
+func (p *CostExplorerProvider) GetControlPlanePricing(ctx context.Context) (float64, error) {
+	return p.Fallback.GetControlPlanePricing(ctx)
+}
+
+// effectiveRates returns the amortized cost per running hour of each instance type during the lookback period.
+func (p *CostExplorerProvider) effectiveRates(ctx context.Context) (map[string]float64, error) {
+	lookback := p.Lookback
+	if lookback == 0 {
+		lookback = 7 * 24 * time.Hour
+	}
+	end := time.Now().UTC()
+	start := end.Add(-lookback)
+
+	input := &costexplorer.GetCostAndUsageInput{
+		Granularity: cetypes.GranularityDaily,
+		Metrics:     []string{"AmortizedCost", "UsageQuantity"},
+		TimePeriod: &cetypes.DateInterval{
+			Start: aws.String(start.Format("2006-01-02")),
+			End:   aws.String(end.Format("2006-01-02")),
+		},
+		Filter: &cetypes.Expression{
+			And: []cetypes.Expression{
+				{Dimensions: &cetypes.DimensionValues{
+					Key:    cetypes.DimensionRegion,
+					Values: []string{p.Region},
+				}},
+				{Dimensions: &cetypes.DimensionValues{
+					Key:    cetypes.DimensionUsageTypeGroup,
+					Values: []string{"EC2: Running Hours"},
+				}},
+			},
+		},
+		GroupBy: []cetypes.GroupDefinition{
+			{Type: cetypes.GroupDefinitionTypeDimension, Key: aws.String(string(cetypes.DimensionInstanceType))},
+			{Type: cetypes.GroupDefinitionTypeDimension, Key: aws.String(string(cetypes.DimensionPurchaseType))},
+		},
+	}
+
+	costs := map[string]float64{}
+	hours := map[string]float64{}
+	for {
+		output, err := p.Client.GetCostAndUsage(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range output.ResultsByTime {
+			for _, group := range result.Groups {
+				if len(group.Keys) != 2 {
+					continue
+				}
+				instanceType, purchaseType := group.Keys[0], group.Keys[1]
+				// spot is priced from the spot market per zone instead
+				if strings.Contains(purchaseType, "Spot") {
+					continue
+				}
+				cost, err := strconv.ParseFloat(aws.ToString(group.Metrics["AmortizedCost"].Amount), 64)
+				if err != nil {
+					continue
+				}
+				usage, err := strconv.ParseFloat(aws.ToString(group.Metrics["UsageQuantity"].Amount), 64)
+				if err != nil {
+					continue
+				}
+				costs[instanceType] += cost
+				hours[instanceType] += usage
+			}
+		}
+		if output.NextPageToken == nil {
+			break
+		}
+		input.NextPageToken = output.NextPageToken
+	}
+
+	rates := map[string]float64{}
+	for instanceType, usage := range hours {
+		if usage > 0 {
+			rates[instanceType] = costs[instanceType] / usage
+		}
+	}
+	return rates, nil
+}
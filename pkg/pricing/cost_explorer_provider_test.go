@@ -0,0 +1,92 @@
+package pricing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	cetypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing"
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing/pricingtest"
+)
+
+type testCostExplorerClient struct {
+	pages  []*costexplorer.GetCostAndUsageOutput
+	inputs []costexplorer.GetCostAndUsageInput
+}
+
+func (c *testCostExplorerClient) GetCostAndUsage(
+	_ context.Context,
+	input *costexplorer.GetCostAndUsageInput,
+	_ ...func(*costexplorer.Options),
+) (*costexplorer.GetCostAndUsageOutput, error) {
+	c.inputs = append(c.inputs, *input)
+	return c.pages[len(c.inputs)-1], nil
+}
+
+func costExplorerGroup(instanceType, purchaseType, cost, hours string) cetypes.Group {
+	return cetypes.Group{
+		Keys: []string{instanceType, purchaseType},
+		Metrics: map[string]cetypes.MetricValue{
+			"AmortizedCost": {Amount: aws.String(cost), Unit: aws.String("USD")},
+			"UsageQuantity": {Amount: aws.String(hours), Unit: aws.String("Hrs")},
+		},
+	}
+}
+
+func TestCostExplorerProviderGetOnDemandPricing(t *testing.T) {
+	client := &testCostExplorerClient{pages: []*costexplorer.GetCostAndUsageOutput{
+		{
+			ResultsByTime: []cetypes.ResultByTime{{
+				Groups: []cetypes.Group{
+					costExplorerGroup("m5.large", "On Demand Instances", "9.6", "100"),
+					costExplorerGroup("m5.large", "Savings Plans", "6", "100"),
+					costExplorerGroup("m5.large", "Spot Instances", "3.5", "100"),
+				},
+			}},
+			NextPageToken: aws.String("page-2"),
+		},
+		{
+			ResultsByTime: []cetypes.ResultByTime{{
+				Groups: []cetypes.Group{
+					costExplorerGroup("c5.large", "Standard Reserved Instances", "10.2", "200"),
+				},
+			}},
+		},
+	}}
+	provider := &pricing.CostExplorerProvider{
+		Region: "us-east-1",
+		Client: client,
+		Fallback: &pricingtest.Provider{OnDemand: pricing.OnDemandPriceList{
+			"m5.large":  0.096,
+			"c5.large":  0.085,
+			"m5.xlarge": 0.192,
+		}},
+	}
+
+	prices, err := provider.GetOnDemandPricing(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for instanceType, exp := range map[string]float64{
+		// (9.6 + 6) / 200, spot is excluded
+		"m5.large": 0.078,
+		// 10.2 / 200
+		"c5.large": 0.051,
+		// no usage, so the public price is used
+		"m5.xlarge": 0.192,
+	} {
+		if got := prices[instanceType]; exp != got {
+			t.Errorf("expected %s price == %f, got %f", instanceType, exp, got)
+		}
+	}
+
+	if exp, got := 2, len(client.inputs); exp != got {
+		t.Fatalf("expected %d GetCostAndUsage calls, got %d", exp, got)
+	}
+	if exp, got := "page-2", aws.ToString(client.inputs[1].NextPageToken); exp != got {
+		t.Errorf("expected second call with page token %s, got %s", exp, got)
+	}
+}
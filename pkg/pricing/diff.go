@@ -0,0 +1,114 @@
+package pricing
+
+import (
+	"sort"
+)
+
+// PriceChange is a change in the on-demand price of an instance type, or its spot price in a zone, between the last
+// two refreshes. The old price is nil for prices new in the last refresh and the new price nil for dropped prices.
+type PriceChange struct {
+	PricingType  PricingType `json:"pricingType"`
+	InstanceType string      `json:"instanceType"`
+	Zone         string      `json:"zone,omitempty"`
+	OldPrice     *float64    `json:"oldPrice"`
+	NewPrice     *float64    `json:"newPrice"`
+}
+
+// snapshotOnDemand keeps the current on-demand prices as the previous snapshot before they are replaced, if there
+// are any yet.
+func (pr *Repository) snapshotOnDemand() {
+	if pr.onDemandPrices.LastUpdated().IsZero() {
+		return
+	}
+	snapshot := pr.onDemandPrices.All()
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	pr.previousOnDemand = snapshot
+}
+
+// snapshotSpot keeps the current spot prices as the previous snapshot before they are updated, if there are any yet.
+func (pr *Repository) snapshotSpot() {
+	if pr.spotPrices.LastUpdated().IsZero() {
+		return
+	}
+	snapshot := pr.spotPrices.All()
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	pr.previousSpot = snapshot
+}
+
+// PreviousOnDemandPrice returns the on-demand price of an instance type before the last on-demand refresh, returning
+// false if it was unknown or on-demand pricing has been refreshed at most once.
+func (pr *Repository) PreviousOnDemandPrice(instanceType string) (float64, bool) {
+	pr.mu.RLock()
+	defer pr.mu.RUnlock()
+	price, ok := pr.previousOnDemand[instanceType]
+	return price, ok
+}
+
+// PreviousSpotPrice returns the spot price of an instance type in a zone before the last spot refresh, returning false
+// if it was unknown or spot pricing has been refreshed at most once.
+func (pr *Repository) PreviousSpotPrice(instanceType string, zone string) (float64, bool) {
+	pr.mu.RLock()
+	defer pr.mu.RUnlock()
+	price, ok := pr.previousSpot[spotKey{instanceType: instanceType, zone: zone}]
+	return price, ok
+}
+
+// PriceDiff returns the on-demand and spot prices which changed between the last two refreshes of each, sorted by
+// pricing type, instance type and zone. Only the snapshot before the last refresh is kept, so a pricing type which has
+// been refreshed at most once has no changes.
+func (pr *Repository) PriceDiff() []PriceChange {
+	pr.mu.RLock()
+	previousOnDemand, previousSpot := pr.previousOnDemand, pr.previousSpot
+	pr.mu.RUnlock()
+
+	changes := []PriceChange{}
+	if previousOnDemand != nil {
+		for instanceType, change := range diffPrices(previousOnDemand, pr.onDemandPrices.All()) {
+			change.PricingType = PricingTypeOnDemand
+			change.InstanceType = instanceType
+			changes = append(changes, change)
+		}
+	}
+	if previousSpot != nil {
+		for key, change := range diffPrices(previousSpot, pr.spotPrices.All()) {
+			change.PricingType = PricingTypeSpot
+			change.InstanceType = key.instanceType
+			change.Zone = key.zone
+			changes = append(changes, change)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.PricingType != b.PricingType {
+			return a.PricingType < b.PricingType
+		}
+		if a.InstanceType != b.InstanceType {
+			return a.InstanceType < b.InstanceType
+		}
+		return a.Zone < b.Zone
+	})
+	return changes
+}
+
+// diffPrices returns the old and new price of every key whose price differs between oldPrices and newPrices.
+func diffPrices[K comparable](oldPrices, newPrices map[K]float64) map[K]PriceChange {
+	changes := map[K]PriceChange{}
+	for key, oldPrice := range oldPrices {
+		oldPrice := oldPrice
+		newPrice, ok := newPrices[key]
+		if !ok {
+			changes[key] = PriceChange{OldPrice: &oldPrice}
+		} else if newPrice != oldPrice {
+			changes[key] = PriceChange{OldPrice: &oldPrice, NewPrice: &newPrice}
+		}
+	}
+	for key, newPrice := range newPrices {
+		newPrice := newPrice
+		if _, ok := oldPrices[key]; !ok {
+			changes[key] = PriceChange{NewPrice: &newPrice}
+		}
+	}
+	return changes
+}
@@ -0,0 +1,48 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+)
+
+// PriceDrift returns the ratio of each on-demand price in prices to its live price, for every instance type with a
+// non-zero price in both. A ratio below 1 means prices underestimates the live price.
+func PriceDrift(prices OnDemandPriceList, live OnDemandPriceList) map[string]float64 {
+	drift := map[string]float64{}
+	for instanceType, price := range prices {
+		livePrice, ok := live[instanceType]
+		if !ok || livePrice == 0 || price == 0 {
+			continue
+		}
+		drift[instanceType] = price / livePrice
+	}
+	return drift
+}
+
+// WithPriceDriftReference compares the on-demand prices of the repository against the live prices from reference on
+// every UpdatePriceDrift, e.g. to see how stale the StaticProvider snapshot has become.
+func WithPriceDriftReference(reference Provider) RepositoryOption {
+	return func(pr *Repository) {
+		pr.driftReference = reference
+	}
+}
+
+// UpdatePriceDrift fetches the live on-demand prices from the drift reference and updates the drift of the current
+// on-demand prices from them. The drift is left unchanged if fetching fails.
+func (pr *Repository) UpdatePriceDrift(ctx context.Context) error {
+	if pr.driftReference == nil {
+		return errors.New("no price drift reference configured")
+	}
+	live, err := pr.driftReference.GetOnDemandPricing(ctx)
+	if err != nil {
+		return err
+	}
+	pr.drift.Replace(PriceDrift(pr.onDemandPrices.All(), live))
+	return nil
+}
+
+// PriceDrift returns the last computed ratio of the on-demand price of each instance type to its live price, which is
+// empty if there is no drift reference or it hasn't been updated yet.
+func (pr *Repository) PriceDrift() map[string]float64 {
+	return pr.drift.All()
+}
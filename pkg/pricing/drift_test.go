@@ -0,0 +1,52 @@
+package pricing_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing"
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing/pricingtest"
+)
+
+func TestPriceDrift(t *testing.T) {
+	static := pricing.OnDemandPriceList{
+		"m5.large":  0.096,
+		"m5.xlarge": 0.2,
+		"c5.large":  0.085,
+		"r5.large":  0.126,
+	}
+	live := pricing.OnDemandPriceList{
+		"m5.large":  0.096,
+		"m5.xlarge": 0.16,
+		"c5.large":  0,
+		"t3.micro":  0.0104,
+	}
+
+	expected := map[string]float64{
+		"m5.large":  1,
+		"m5.xlarge": 1.25,
+	}
+	if got := pricing.PriceDrift(static, live); !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected drift %v, got %v", expected, got)
+	}
+}
+
+func TestRepositoryUpdatePriceDrift(t *testing.T) {
+	pr := pricing.NewRepository(
+		&pricingtest.Provider{OnDemand: pricing.OnDemandPriceList{"m5.large": 0.1}},
+		pricing.WithPriceDriftReference(&pricingtest.Provider{OnDemand: pricing.OnDemandPriceList{"m5.large": 0.125}}),
+	)
+	if err := pr.UpdateOnDemandPricing(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := pr.PriceDrift(); len(got) != 0 {
+		t.Errorf("expected no drift before updating, got %v", got)
+	}
+	if err := pr.UpdatePriceDrift(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp, got := 0.8, pr.PriceDrift()["m5.large"]; exp != got {
+		t.Errorf("expected m5.large drift == %f, got %f", exp, got)
+	}
+}
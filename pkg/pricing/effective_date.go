@@ -0,0 +1,42 @@
+package pricing
+
+import (
+	"context"
+	"time"
+)
+
+// EffectiveDates are the dates the on-demand prices of instance types took effect, i.e. when AWS last changed them, by
+// instance type.
+type EffectiveDates map[string]time.Time
+
+// EffectiveDatePricingProvider is implemented by providers which know when the on-demand prices they return took
+// effect.
+type EffectiveDatePricingProvider interface {
+	GetOnDemandPricingWithEffectiveDates(context.Context) (OnDemandPriceList, EffectiveDates, error)
+}
+
+// onDemandPricingWithEffectiveDates returns the on-demand prices of provider with the dates they took effect, without
+// any dates if the provider doesn't know them.
+func onDemandPricingWithEffectiveDates(
+	ctx context.Context,
+	provider Provider,
+) (OnDemandPriceList, EffectiveDates, error) {
+	dated, ok := provider.(EffectiveDatePricingProvider)
+	if !ok {
+		prices, err := provider.GetOnDemandPricing(ctx)
+		return prices, nil, err
+	}
+	return dated.GetOnDemandPricingWithEffectiveDates(ctx)
+}
+
+// OnDemandEffectiveDates returns when the current on-demand price of each instance type took effect, for the instance
+// types the pricing provider knows it of.
+func (pr *Repository) OnDemandEffectiveDates() EffectiveDates {
+	pr.mu.RLock()
+	defer pr.mu.RUnlock()
+	dates := make(EffectiveDates, len(pr.onDemandEffectiveDates))
+	for instanceType, date := range pr.onDemandEffectiveDates {
+		dates[instanceType] = date
+	}
+	return dates
+}
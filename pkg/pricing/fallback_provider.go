@@ -0,0 +1,138 @@
+package pricing
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// FallbackProvider returns pricing from Primary, falling back to Fallback for a pricing type whenever Primary fails
+// to return it, e.g. to keep serving the static snapshot while the AWS APIs are unreachable.
+type FallbackProvider struct {
+	Primary  Provider
+	Fallback Provider
+
+	mu      sync.Mutex
+	sources map[PricingType]string
+}
+
+// NewFallbackProvider returns a FallbackProvider which uses fallback whenever primary fails.
+func NewFallbackProvider(primary, fallback Provider) *FallbackProvider {
+	return &FallbackProvider{
+		Primary:  primary,
+		Fallback: fallback,
+		sources:  map[PricingType]string{},
+	}
+}
+
+// PricingSource returns the source of the provider which returned pricingType last.
+func (p *FallbackProvider) PricingSource(pricingType PricingType) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if source, ok := p.sources[pricingType]; ok {
+		return source
+	}
+	return PricingSourceUnknown
+}
+
+// ScopeToZones restricts the spot pricing of both providers to zones.
+func (p *FallbackProvider) ScopeToZones(zones []string) {
+	scopeToZones(p.Primary, zones)
+	scopeToZones(p.Fallback, zones)
+}
+
+func (p *FallbackProvider) GetOnDemandPricing(ctx context.Context) (OnDemandPriceList, error) {
+	return withFallback(p, PricingTypeOnDemand, func(provider Provider) (OnDemandPriceList, error) {
+		return provider.GetOnDemandPricing(ctx)
+	})
+}
+
+func (p *FallbackProvider) GetOnDemandPricingWithEffectiveDates(
+	ctx context.Context,
+) (OnDemandPriceList, EffectiveDates, error) {
+	type datedPrices struct {
+		prices OnDemandPriceList
+		dates  EffectiveDates
+	}
+	dated, err := withFallback(p, PricingTypeOnDemand, func(provider Provider) (datedPrices, error) {
+		prices, dates, err := onDemandPricingWithEffectiveDates(ctx, provider)
+		return datedPrices{prices: prices, dates: dates}, err
+	})
+	return dated.prices, dated.dates, err
+}
+
+func (p *FallbackProvider) GetLicensedOnDemandPricing(
+	ctx context.Context,
+	licenseModel LicenseModel,
+) (OnDemandPriceList, error) {
+	return withFallback(p, PricingTypeOnDemand, func(provider Provider) (OnDemandPriceList, error) {
+		return licensedOnDemandPricing(ctx, provider, licenseModel)
+	})
+}
+
+func (p *FallbackProvider) GetSpotPricing(ctx context.Context) (SpotPriceList, error) {
+	return withFallback(p, PricingTypeSpot, func(provider Provider) (SpotPriceList, error) {
+		return provider.GetSpotPricing(ctx)
+	})
+}
+
+func (p *FallbackProvider) GetLicensedSpotPricing(
+	ctx context.Context,
+	licenseModel LicenseModel,
+) (SpotPriceList, error) {
+	return withFallback(p, PricingTypeSpot, func(provider Provider) (SpotPriceList, error) {
+		return licensedSpotPricing(ctx, provider, licenseModel)
+	})
+}
+
+func (p *FallbackProvider) GetFargatePricing(ctx context.Context) (FargatePrice, error) {
+	return withFallback(p, PricingTypeFargate, func(provider Provider) (FargatePrice, error) {
+		return provider.GetFargatePricing(ctx)
+	})
+}
+
+func (p *FallbackProvider) GetRegionalFargatePricing(ctx context.Context, region string) (FargatePrice, error) {
+	return withFallback(p, PricingTypeFargate, func(provider Provider) (FargatePrice, error) {
+		return regionalFargatePricing(ctx, provider, region)
+	})
+}
+
+func (p *FallbackProvider) GetCapacityBlockPricing(ctx context.Context) (CapacityBlockPriceList, error) {
+	return withFallback(p, PricingTypeCapacityBlock, func(provider Provider) (CapacityBlockPriceList, error) {
+		return provider.GetCapacityBlockPricing(ctx)
+	})
+}
+
+func (p *FallbackProvider) GetInstanceSpecs(ctx context.Context) (InstanceSpecList, error) {
+	return withFallback(p, PricingTypeInstanceSpecs, func(provider Provider) (InstanceSpecList, error) {
+		return provider.GetInstanceSpecs(ctx)
+	})
+}
+
+func (p *FallbackProvider) GetControlPlanePricing(ctx context.Context) (float64, error) {
+	return withFallback(p, PricingTypeControlPlane, func(provider Provider) (float64, error) {
+		return provider.GetControlPlanePricing(ctx)
+	})
+}
+
+// withFallback calls get with the primary provider, and with the fallback provider if that fails, recording the
+// source of whichever succeeded.
+func withFallback[T any](p *FallbackProvider, pricingType PricingType, get func(Provider) (T, error)) (T, error) {
+	provider := p.Primary
+	result, err := get(provider)
+	if err != nil {
+		log.Printf("falling back for %s pricing: %s", pricingType, err)
+		provider = p.Fallback
+		result, err = get(provider)
+		if err != nil {
+			return result, err
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.sources == nil {
+		p.sources = map[PricingType]string{}
+	}
+	p.sources[pricingType] = pricingSource(provider, pricingType)
+	return result, nil
+}
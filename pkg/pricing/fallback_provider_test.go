@@ -0,0 +1,50 @@
+package pricing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing"
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing/pricingtest"
+)
+
+func TestRepositoryPricingSourceFollowsFallback(t *testing.T) {
+	primary := &pricingtest.Provider{
+		Spot: pricing.SpotPriceList{
+			"m5.large": {"us-east-1a": 0.035},
+		},
+		SpotErrs: []error{errors.New("throttled")},
+		Source:   pricing.PricingSourceAWS,
+	}
+	pr := pricing.NewRepository(pricing.NewFallbackProvider(primary, pricing.NewStaticProvider()))
+	ctx := context.Background()
+
+	if sources := pr.PricingSources(); len(sources) != 0 {
+		t.Errorf("expected no sources before the first update, got %v", sources)
+	}
+
+	if err := pr.UpdateSpotPricing(ctx); err != nil {
+		t.Fatalf("expected update to fall back instead of failing: %s", err)
+	}
+	if exp, got := pricing.PricingSourceStatic, pr.PricingSources()[pricing.PricingTypeSpot]; exp != got {
+		t.Errorf("expected spot source %q after primary failure, got %q", exp, got)
+	}
+
+	if err := pr.UpdateSpotPricing(ctx); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp, got := pricing.PricingSourceAWS, pr.PricingSources()[pricing.PricingTypeSpot]; exp != got {
+		t.Errorf("expected spot source %q after primary recovered, got %q", exp, got)
+	}
+	if price, ok := pr.SpotPrice("m5.large", "us-east-1a"); !ok || price != 0.035 {
+		t.Errorf("expected spot price from the primary provider, got %v (%v)", price, ok)
+	}
+
+	if err := pr.UpdateOnDemandPricing(ctx); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp, got := pricing.PricingSourceAWS, pr.PricingSources()[pricing.PricingTypeOnDemand]; exp != got {
+		t.Errorf("expected on-demand source %q, got %q", exp, got)
+	}
+}
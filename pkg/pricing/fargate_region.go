@@ -0,0 +1,39 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+)
+
+// RegionalFargatePricingProvider is implemented by providers which can return the Fargate pricing of regions other
+// than their own, e.g. to price the Fargate nodes of clusters in several regions.
+type RegionalFargatePricingProvider interface {
+	GetRegionalFargatePricing(ctx context.Context, region string) (FargatePrice, error)
+}
+
+// WithFargateRegions additionally fetches the Fargate pricing of regions, which is used for Fargate nodes in those
+// regions instead of the Fargate pricing of the region of the provider.
+func WithFargateRegions(regions ...string) RepositoryOption {
+	return func(pr *Repository) {
+		pr.fargateRegions = regions
+	}
+}
+
+// WithProviderRegion sets the region the pricing provider prices, so Fargate nodes in regions which are neither it nor
+// one of the Fargate regions have an unknown price rather than the price of the provider's region. Without it the
+// Fargate pricing of the provider is used for Fargate nodes in any region not in the Fargate regions.
+func WithProviderRegion(region string) RepositoryOption {
+	return func(pr *Repository) {
+		pr.providerRegion = region
+	}
+}
+
+// regionalFargatePricing returns the Fargate pricing of provider for region, returning an error if provider doesn't
+// support other regions.
+func regionalFargatePricing(ctx context.Context, provider Provider, region string) (FargatePrice, error) {
+	regional, ok := provider.(RegionalFargatePricingProvider)
+	if !ok {
+		return FargatePrice{}, fmt.Errorf("pricing provider does not support fargate pricing for region %s", region)
+	}
+	return regional.GetRegionalFargatePricing(ctx, region)
+}
@@ -0,0 +1,17 @@
+package pricing
+
+import (
+	"net/http"
+	"net/url"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+)
+
+// NewProxyHTTPClient returns an HTTP client for AWS SDK clients which sends all requests through proxyURL. Pass it
+// to config.WithHTTPClient when loading the AWS config. Without it the SDK already honors the HTTPS_PROXY and
+// NO_PROXY environment variables.
+func NewProxyHTTPClient(proxyURL *url.URL) *awshttp.BuildableClient {
+	return awshttp.NewBuildableClient().WithTransportOptions(func(tr *http.Transport) {
+		tr.Proxy = http.ProxyURL(proxyURL)
+	})
+}
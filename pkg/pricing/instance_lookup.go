@@ -0,0 +1,113 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/smithy-go"
+)
+
+// InstanceInfo is the instance type and zone of an EC2 instance.
+type InstanceInfo struct {
+	InstanceType string
+	Zone         string
+}
+
+// InstanceLookup resolves EC2 instance IDs to their instance type and zone for nodes that are missing the labels
+// for them.
+type InstanceLookup struct {
+	client ec2.DescribeInstancesAPIClient
+	cache  *priceCache[string, InstanceInfo]
+	// notFound caches the instance IDs DescribeInstances doesn't know, so they aren't looked up on every collection.
+	notFound *priceCache[string, struct{}]
+}
+
+// NewInstanceLookup returns an InstanceLookup using client. Results are cached for a day since an instance's type
+// and zone never change, and instances that aren't found for an hour.
+func NewInstanceLookup(client ec2.DescribeInstancesAPIClient) *InstanceLookup {
+	return &InstanceLookup{
+		client:   client,
+		cache:    newPriceCache[string, InstanceInfo](24 * time.Hour),
+		notFound: newPriceCache[string, struct{}](time.Hour),
+	}
+}
+
+// Lookup returns the instance info for the given instance IDs, only calling DescribeInstances for instances that
+// aren't already cached. Instances that don't exist are left out of the result.
+func (l *InstanceLookup) Lookup(ctx context.Context, instanceIDs []string) (map[string]InstanceInfo, error) {
+	result := map[string]InstanceInfo{}
+	var missing []string
+	for _, instanceID := range instanceIDs {
+		if info, ok := l.cache.Get(instanceID); ok {
+			result[instanceID] = info
+		} else if _, ok := l.notFound.Get(instanceID); !ok {
+			missing = append(missing, instanceID)
+		}
+	}
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	err := l.describe(ctx, missing, result)
+	if !instanceNotFound(err) {
+		return result, err
+	}
+	if len(missing) > 1 {
+		// DescribeInstances fails the whole call if any instance doesn't exist, so find the others one at a time
+		for _, instanceID := range missing {
+			if err := l.describe(ctx, []string{instanceID}, result); err != nil && !instanceNotFound(err) {
+				return result, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// Retain drops the cached instances other than instanceIDs, e.g. those of nodes that have since been removed.
+func (l *InstanceLookup) Retain(instanceIDs []string) {
+	keep := make(map[string]bool, len(instanceIDs))
+	for _, instanceID := range instanceIDs {
+		keep[instanceID] = true
+	}
+	l.cache.Retain(keep)
+	l.notFound.Retain(keep)
+}
+
+// describe adds the info of instanceIDs to result, caching the instances which don't exist.
+func (l *InstanceLookup) describe(ctx context.Context, instanceIDs []string, result map[string]InstanceInfo) error {
+	instancesPaginator := ec2.NewDescribeInstancesPaginator(l.client, &ec2.DescribeInstancesInput{
+		InstanceIds: instanceIDs,
+	})
+	for instancesPaginator.HasMorePages() {
+		output, err := instancesPaginator.NextPage(ctx)
+		if err != nil {
+			if instanceNotFound(err) && len(instanceIDs) == 1 {
+				l.notFound.Set(instanceIDs[0], struct{}{})
+			}
+			return err
+		}
+		for _, reservation := range output.Reservations {
+			for _, instance := range reservation.Instances {
+				info := InstanceInfo{
+					InstanceType: string(instance.InstanceType),
+				}
+				if instance.Placement != nil {
+					info.Zone = aws.ToString(instance.Placement.AvailabilityZone)
+				}
+				instanceID := aws.ToString(instance.InstanceId)
+				l.cache.Set(instanceID, info)
+				result[instanceID] = info
+			}
+		}
+	}
+	return nil
+}
+
+// instanceNotFound returns true if err means one of the instances described doesn't exist.
+func instanceNotFound(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "InvalidInstanceID.NotFound"
+}
@@ -0,0 +1,108 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// LicenseModel is how the operating system of an instance is licensed, which decides whether its on-demand price
+// includes a license fee.
+type LicenseModel string
+
+const (
+	// LicenseModelNone is an operating system without a license fee, e.g. Amazon Linux or Bottlerocket.
+	LicenseModelNone LicenseModel = "none"
+	// LicenseModelBYOL is an operating system licensed separately, which is priced like LicenseModelNone.
+	LicenseModelBYOL LicenseModel = "byol"
+	// LicenseModelWindows is Windows with the license fee included in the price.
+	LicenseModelWindows LicenseModel = "windows"
+	// LicenseModelRHEL is Red Hat Enterprise Linux with the license fee included in the price.
+	LicenseModelRHEL LicenseModel = "rhel"
+)
+
+// ParseLicenseModel parses a license model, where an empty name is LicenseModelNone.
+func ParseLicenseModel(name string) (LicenseModel, error) {
+	switch m := LicenseModel(strings.ToLower(strings.TrimSpace(name))); m {
+	case "":
+		return LicenseModelNone, nil
+	case LicenseModelNone, LicenseModelBYOL, LicenseModelWindows, LicenseModelRHEL:
+		return m, nil
+	default:
+		return "", fmt.Errorf(
+			"unknown license model %q, must be one of %s, %s, %s or %s",
+			name,
+			LicenseModelNone,
+			LicenseModelBYOL,
+			LicenseModelWindows,
+			LicenseModelRHEL,
+		)
+	}
+}
+
+// IncludesLicense returns true if the price of the license model includes a license fee, so it is priced separately
+// from the regular on-demand prices.
+func (m LicenseModel) IncludesLicense() bool {
+	return m == LicenseModelWindows || m == LicenseModelRHEL
+}
+
+// operatingSystem returns the operatingSystem attribute of the license model in the AWS pricing API.
+func (m LicenseModel) operatingSystem() string {
+	switch m {
+	case LicenseModelWindows:
+		return "Windows"
+	case LicenseModelRHEL:
+		return "RHEL"
+	default:
+		return ""
+	}
+}
+
+// spotProductDescriptions returns the EC2-Classic and VPC product descriptions of the spot prices of the operating
+// system of the license model.
+func (m LicenseModel) spotProductDescriptions() (classic string, vpc string) {
+	switch m {
+	case LicenseModelWindows:
+		return "Windows", "Windows (Amazon VPC)"
+	case LicenseModelRHEL:
+		return "Red Hat Enterprise Linux", "Red Hat Enterprise Linux (Amazon VPC)"
+	default:
+		return "Linux/UNIX", "Linux/UNIX (Amazon VPC)"
+	}
+}
+
+// LicensedPricingProvider is implemented by providers which can return on-demand prices including the license fee
+// of a license model.
+type LicensedPricingProvider interface {
+	GetLicensedOnDemandPricing(context.Context, LicenseModel) (OnDemandPriceList, error)
+}
+
+// LicensedSpotPricingProvider is implemented by providers which can return the spot prices of the operating system of
+// a license model, which differ from the Linux spot prices.
+type LicensedSpotPricingProvider interface {
+	GetLicensedSpotPricing(context.Context, LicenseModel) (SpotPriceList, error)
+}
+
+// licensedOnDemandPricing returns the on-demand prices of provider for licenseModel, returning an error if provider
+// doesn't support license models.
+func licensedOnDemandPricing(
+	ctx context.Context,
+	provider Provider,
+	licenseModel LicenseModel,
+) (OnDemandPriceList, error) {
+	licensed, ok := provider.(LicensedPricingProvider)
+	if !ok {
+		return nil, fmt.Errorf("pricing provider does not support %s license pricing", licenseModel)
+	}
+	return licensed.GetLicensedOnDemandPricing(ctx, licenseModel)
+}
+
+// licensedSpotPricing returns the spot prices of provider for licenseModel, returning an error if provider doesn't
+// support license models.
+func licensedSpotPricing(ctx context.Context, provider Provider, licenseModel LicenseModel) (SpotPriceList, error) {
+	licensed, ok := provider.(LicensedSpotPricingProvider)
+	if !ok {
+		return nil, fmt.Errorf("pricing provider does not support %s spot pricing", licenseModel)
+	}
+	return licensed.GetLicensedSpotPricing(ctx, licenseModel)
+}
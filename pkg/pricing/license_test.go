@@ -0,0 +1,41 @@
+package pricing_test
+
+import (
+	"testing"
+
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing"
+)
+
+func TestParseLicenseModel(t *testing.T) {
+	for name, tc := range map[string]struct {
+		licenseModel    pricing.LicenseModel
+		includesLicense bool
+	}{
+		"":         {pricing.LicenseModelNone, false},
+		"none":     {pricing.LicenseModelNone, false},
+		"byol":     {pricing.LicenseModelBYOL, false},
+		" BYOL ":   {pricing.LicenseModelBYOL, false},
+		"windows":  {pricing.LicenseModelWindows, true},
+		"rhel":     {pricing.LicenseModelRHEL, true},
+		"RHEL":     {pricing.LicenseModelRHEL, true},
+		"included": {"", false},
+	} {
+		licenseModel, err := pricing.ParseLicenseModel(name)
+		if tc.licenseModel == "" {
+			if err == nil {
+				t.Errorf("%q: expected error, got %s", name, licenseModel)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", name, err)
+			continue
+		}
+		if exp, got := tc.licenseModel, licenseModel; exp != got {
+			t.Errorf("%q: expected license model == %s, got %s", name, exp, got)
+		}
+		if exp, got := tc.includesLicense, licenseModel.IncludesLicense(); exp != got {
+			t.Errorf("%q: expected IncludesLicense() == %t, got %t", name, exp, got)
+		}
+	}
+}
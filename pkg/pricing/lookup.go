@@ -0,0 +1,68 @@
+package pricing
+
+import (
+	"fmt"
+)
+
+// Capacity types understood by Repository.Lookup. These match the capacity types of nodes.
+const (
+	CapacityTypeOnDemand      = "on-demand"
+	CapacityTypeSpot          = "spot"
+	CapacityTypeCapacityBlock = "capacity-block"
+)
+
+// LookupRequest identifies an instance type to price.
+type LookupRequest struct {
+	InstanceType string `json:"type"`
+	CapacityType string `json:"capacityType"`
+	// Zone is only used for spot pricing.
+	Zone string `json:"zone,omitempty"`
+	// LicenseModel is only used for on-demand and spot pricing, defaults to LicenseModelNone.
+	LicenseModel LicenseModel `json:"licenseModel,omitempty"`
+}
+
+// LookupResult is the resolved price of a LookupRequest. Price is nil if no price was found, in which case Reason
+// describes why.
+type LookupResult struct {
+	LookupRequest
+	Price *float64 `json:"price"`
+	// Reason describes which lookup the price came from, or why no price was found.
+	Reason string `json:"reason"`
+}
+
+// Lookup resolves the hourly price of an instance type for a capacity type.
+func (pr *Repository) Lookup(req LookupRequest) LookupResult {
+	result := LookupResult{LookupRequest: req}
+	var price float64
+	var ok bool
+	switch req.CapacityType {
+	case CapacityTypeCapacityBlock:
+		price, ok = pr.CapacityBlockPrice(req.InstanceType)
+		result.Reason = fmt.Sprintf("capacity block price for %s", req.InstanceType)
+	case CapacityTypeOnDemand:
+		if req.LicenseModel.IncludesLicense() {
+			price, ok = pr.LicensedOnDemandPrice(req.InstanceType, req.LicenseModel)
+			result.Reason = fmt.Sprintf("on-demand %s license included price for %s", req.LicenseModel, req.InstanceType)
+			break
+		}
+		price, ok = pr.OnDemandPrice(req.InstanceType)
+		result.Reason = fmt.Sprintf("on-demand price for %s", req.InstanceType)
+	case CapacityTypeSpot:
+		if req.LicenseModel.IncludesLicense() {
+			price, ok = pr.LicensedSpotPrice(req.InstanceType, req.Zone, req.LicenseModel)
+			result.Reason = fmt.Sprintf("%s spot price for %s in %s", req.LicenseModel, req.InstanceType, req.Zone)
+			break
+		}
+		price, ok = pr.SpotPrice(req.InstanceType, req.Zone)
+		result.Reason = fmt.Sprintf("spot price for %s in %s", req.InstanceType, req.Zone)
+	default:
+		result.Reason = "unknown capacity type"
+		return result
+	}
+	if !ok {
+		result.Reason = "no " + result.Reason
+		return result
+	}
+	result.Price = &price
+	return result
+}
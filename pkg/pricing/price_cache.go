@@ -0,0 +1,130 @@
+package pricing
+
+import (
+	"sync"
+	"time"
+)
+
+type priceCacheEntry[V any] struct {
+	value   V
+	updated time.Time
+}
+
+// priceCache is a concurrency-safe map of prices where each entry expires after a TTL.
+type priceCache[K comparable, V any] struct {
+	mu          sync.RWMutex
+	ttl         time.Duration
+	now         func() time.Time
+	entries     map[K]priceCacheEntry[V]
+	lastUpdated time.Time
+}
+
+// newPriceCache returns an empty priceCache. A ttl of 0 means entries never expire.
+func newPriceCache[K comparable, V any](ttl time.Duration) *priceCache[K, V] {
+	return &priceCache[K, V]{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[K]priceCacheEntry[V]{},
+	}
+}
+
+// Replace replaces all entries in the cache with values.
+func (c *priceCache[K, V]) Replace(values map[K]V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	c.entries = make(map[K]priceCacheEntry[V], len(values))
+	for k, v := range values {
+		c.entries[k] = priceCacheEntry[V]{value: v, updated: now}
+	}
+	c.lastUpdated = now
+}
+
+// Set sets a single entry in the cache.
+func (c *priceCache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	c.entries[key] = priceCacheEntry[V]{value: value, updated: now}
+	c.lastUpdated = now
+}
+
+// Merge sets the entries in values, keeping other entries until they expire, and drops any expired entries.
+func (c *priceCache[K, V]) Merge(values map[K]V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for k, v := range values {
+		c.entries[k] = priceCacheEntry[V]{value: v, updated: now}
+	}
+	for k, entry := range c.entries {
+		if c.expired(entry) {
+			delete(c.entries, k)
+		}
+	}
+	c.lastUpdated = now
+}
+
+// Retain drops the entries whose keys aren't in keys.
+func (c *priceCache[K, V]) Retain(keys map[K]bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k := range c.entries {
+		if !keys[k] {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// Get returns the value for key, returning false if there is no entry or the entry has expired.
+func (c *priceCache[K, V]) Get(key K) (V, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[key]
+	if !ok || c.expired(entry) {
+		var zero V
+		return zero, false
+	}
+	return entry.value, true
+}
+
+// All returns a copy of all entries that have not expired.
+func (c *priceCache[K, V]) All() map[K]V {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	values := make(map[K]V, len(c.entries))
+	for k, entry := range c.entries {
+		if !c.expired(entry) {
+			values[k] = entry.value
+		}
+	}
+	return values
+}
+
+// LastUpdated returns the time that the cache was last written to.
+func (c *priceCache[K, V]) LastUpdated() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lastUpdated
+}
+
+// snapshot returns a copy of the cache frozen at the current time, so none of its entries expire later.
+func (c *priceCache[K, V]) snapshot() *priceCache[K, V] {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	now := c.now()
+	entries := make(map[K]priceCacheEntry[V], len(c.entries))
+	for k, entry := range c.entries {
+		entries[k] = entry
+	}
+	return &priceCache[K, V]{
+		ttl:         c.ttl,
+		now:         func() time.Time { return now },
+		entries:     entries,
+		lastUpdated: c.lastUpdated,
+	}
+}
+
+func (c *priceCache[K, V]) expired(entry priceCacheEntry[V]) bool {
+	return c.ttl > 0 && c.now().Sub(entry.updated) > c.ttl
+}
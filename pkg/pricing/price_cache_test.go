@@ -0,0 +1,124 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPriceCacheExpiry(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := newPriceCache[string, float64](time.Hour)
+	c.now = func() time.Time { return now }
+
+	c.Replace(map[string]float64{"m5.large": 0.096})
+	if exp, got := now, c.LastUpdated(); !exp.Equal(got) {
+		t.Errorf("expected LastUpdated == %s, got %s", exp, got)
+	}
+
+	now = now.Add(30 * time.Minute)
+	c.Set("m5.xlarge", 0.192)
+	if price, ok := c.Get("m5.large"); !ok || price != 0.096 {
+		t.Errorf("expected m5.large == 0.096 before expiry, got %f (ok=%v)", price, ok)
+	}
+
+	now = now.Add(31 * time.Minute)
+	if _, ok := c.Get("m5.large"); ok {
+		t.Errorf("expected m5.large to have expired")
+	}
+	if price, ok := c.Get("m5.xlarge"); !ok || price != 0.192 {
+		t.Errorf("expected m5.xlarge == 0.192 before expiry, got %f (ok=%v)", price, ok)
+	}
+	if exp, got := 1, len(c.All()); exp != got {
+		t.Errorf("expected %d unexpired entries, got %d", exp, got)
+	}
+}
+
+func TestPriceCacheNoTTL(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := newPriceCache[string, float64](0)
+	c.now = func() time.Time { return now }
+
+	c.Set("m5.large", 0.096)
+	now = now.Add(24 * 365 * time.Hour)
+	if _, ok := c.Get("m5.large"); !ok {
+		t.Errorf("expected m5.large to never expire")
+	}
+}
+
+// spotProvider returns spot from a StaticProvider.
+type spotProvider struct {
+	StaticProvider
+	spot SpotPriceList
+}
+
+func (p *spotProvider) GetSpotPricing(_ context.Context) (SpotPriceList, error) {
+	return p.spot, nil
+}
+
+func TestRepositorySpotPricesKeptUntilTTL(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	provider := &spotProvider{spot: SpotPriceList{
+		"m5.large":  {"us-east-1a": 0.035},
+		"m5.xlarge": {"us-east-1a": 0.07},
+	}}
+	pr := NewRepository(provider, WithSpotPriceTTL(3*time.Hour))
+	pr.spotPrices.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if err := pr.UpdateSpotPricing(ctx); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// a partial refresh which is missing m5.xlarge
+	provider.spot = SpotPriceList{"m5.large": {"us-east-1a": 0.04}}
+	for i := 0; i < 3; i++ {
+		now = now.Add(time.Hour)
+		if err := pr.UpdateSpotPricing(ctx); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if price, ok := pr.SpotPrice("m5.large", "us-east-1a"); !ok || price != 0.04 {
+			t.Errorf("after %d refreshes: expected m5.large == 0.04, got %f (ok=%v)", i+1, price, ok)
+		}
+		if price, ok := pr.SpotPrice("m5.xlarge", "us-east-1a"); !ok || price != 0.07 {
+			t.Errorf("after %d refreshes: expected m5.xlarge to keep 0.07, got %f (ok=%v)", i+1, price, ok)
+		}
+	}
+
+	now = now.Add(time.Hour)
+	if err := pr.UpdateSpotPricing(ctx); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, ok := pr.SpotPrice("m5.xlarge", "us-east-1a"); ok {
+		t.Errorf("expected m5.xlarge to have expired")
+	}
+	if exp, got := 1, len(pr.spotPrices.entries); exp != got {
+		t.Errorf("expected expired entries to be dropped, got %d entries", got)
+	}
+}
+
+func TestPriceCacheConcurrentAccess(t *testing.T) {
+	c := newPriceCache[string, float64](time.Hour)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		i := i
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			c.Replace(map[string]float64{fmt.Sprintf("type-%d", i): float64(i)})
+		}()
+		go func() {
+			defer wg.Done()
+			c.Set(fmt.Sprintf("other-%d", i), float64(i))
+		}()
+		go func() {
+			defer wg.Done()
+			c.Get(fmt.Sprintf("type-%d", i))
+			c.All()
+			c.LastUpdated()
+		}()
+	}
+	wg.Wait()
+}
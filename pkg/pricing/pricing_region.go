@@ -0,0 +1,116 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+	"github.com/aws/smithy-go"
+)
+
+// fallbackPricingAPIRegion is the pricing API region used when the one closest to the cluster is unreachable. It is
+// the region the pricing API has always been available in.
+const fallbackPricingAPIRegion = "us-east-1"
+
+// pricingRegionRetryInterval is how long requests are sent to the fallback pricing API region before the region
+// closest to the cluster is tried again, in case it was only unreachable for a while.
+const pricingRegionRetryInterval = time.Hour
+
+// PricingRegionError is returned when the pricing API region chosen for a region is unreachable, typically because it
+// is an opt-in region which isn't enabled in the account.
+type PricingRegionError struct {
+	// Region is the region prices were fetched for.
+	Region string
+	// PricingRegion is the region of the pricing API endpoint used for Region.
+	PricingRegion string
+	Err           error
+}
+
+func (e *PricingRegionError) Error() string {
+	return fmt.Sprintf(
+		"pricing API in %s, which serves the prices of %s, is unreachable and may be disabled in this account; "+
+			"enable %s or fall back to the %s pricing API: %s",
+		e.PricingRegion,
+		e.Region,
+		e.PricingRegion,
+		fallbackPricingAPIRegion,
+		e.Err,
+	)
+}
+
+func (e *PricingRegionError) Unwrap() error {
+	return e.Err
+}
+
+// WithPricingRegionFallback sends pricing API requests to us-east-1 for a while if the pricing API region chosen for
+// the region is unreachable. It has no effect with WithPricingEndpoint or in the China partition.
+func WithPricingRegionFallback(fallback bool) AWSProviderOption {
+	return func(o *awsProviderOptions) {
+		o.pricingRegionFallback = fallback
+	}
+}
+
+// pricingRegionClient turns the errors of a pricing API client for an unreachable region into a PricingRegionError,
+// or sends the request to fallback instead if it is set.
+type pricingRegionClient struct {
+	region   string
+	client   pricing.GetProductsAPIClient
+	fallback pricing.GetProductsAPIClient
+	// fellBackUntil is the unix time in nanoseconds until which requests are sent to fallback after the region was
+	// unreachable
+	fellBackUntil atomic.Int64
+}
+
+// NewPricingRegionClient returns a pricing API client which sends requests to client, the pricing API client for the
+// prices of region. If the pricing API region of client is unreachable, requests are sent to fallback for an hour
+// before trying the region again, or a PricingRegionError is returned if fallback is nil.
+func NewPricingRegionClient(
+	region string,
+	client pricing.GetProductsAPIClient,
+	fallback pricing.GetProductsAPIClient,
+) pricing.GetProductsAPIClient {
+	return &pricingRegionClient{region: region, client: client, fallback: fallback}
+}
+
+func (c *pricingRegionClient) GetProducts(
+	ctx context.Context,
+	input *pricing.GetProductsInput,
+	optFns ...func(*pricing.Options),
+) (*pricing.GetProductsOutput, error) {
+	if time.Now().UnixNano() < c.fellBackUntil.Load() {
+		return c.fallback.GetProducts(ctx, input, optFns...)
+	}
+	output, err := c.client.GetProducts(ctx, input, optFns...)
+	if err == nil || !regionUnreachable(err) {
+		return output, err
+	}
+	regionErr := &PricingRegionError{Region: c.region, PricingRegion: pricingAPIRegion(c.region), Err: err}
+	if c.fallback == nil {
+		return nil, regionErr
+	}
+	log.Printf(
+		"%s, using the %s pricing API for the next %s",
+		regionErr,
+		fallbackPricingAPIRegion,
+		pricingRegionRetryInterval,
+	)
+	c.fellBackUntil.Store(time.Now().Add(pricingRegionRetryInterval).UnixNano())
+	return c.fallback.GetProducts(ctx, input, optFns...)
+}
+
+// regionUnreachable returns true if err means the region of the endpoint can't be used, either because its endpoint
+// doesn't exist or because it is an opt-in region which isn't enabled in the account. Other network and credential
+// errors may well be transient or affect every region alike, so they don't count.
+func regionUnreachable(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return true
+	}
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "OptInRequired"
+}
@@ -0,0 +1,99 @@
+// Package pricingtest provides a fake pricing.Provider for tests.
+package pricingtest
+
+import (
+	"context"
+
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing"
+)
+
+// Provider is a pricing.Provider returning the pricing set on it. It also implements the license model, regional
+// Fargate pricing and pricing source extensions of pricing.Provider.
+type Provider struct {
+	OnDemand pricing.OnDemandPriceList
+	Spot     pricing.SpotPriceList
+	Fargate  pricing.FargatePrice
+	// RegionalFargate is the Fargate pricing of other regions by region
+	RegionalFargate map[string]pricing.FargatePrice
+	CapacityBlock   pricing.CapacityBlockPriceList
+	InstanceSpecs   pricing.InstanceSpecList
+	ControlPlane    float64
+	// Licensed and LicensedSpot are the on-demand and spot pricing of each license model
+	Licensed     map[pricing.LicenseModel]pricing.OnDemandPriceList
+	LicensedSpot map[pricing.LicenseModel]pricing.SpotPriceList
+	// Source is reported as the source of every pricing type, pricing.PricingSourceUnknown if unset
+	Source string
+
+	// SpotErrs are returned by successive calls of GetSpotPricing, with a nil error returning Spot, until they run out
+	SpotErrs []error
+	// LicensedSpotErr, ControlPlaneErr and InstanceSpecsErr are returned by GetLicensedSpotPricing,
+	// GetControlPlanePricing and GetInstanceSpecs if set
+	LicensedSpotErr  error
+	ControlPlaneErr  error
+	InstanceSpecsErr error
+}
+
+func (p *Provider) PricingSource(_ pricing.PricingType) string {
+	if p.Source == "" {
+		return pricing.PricingSourceUnknown
+	}
+	return p.Source
+}
+
+func (p *Provider) GetOnDemandPricing(_ context.Context) (pricing.OnDemandPriceList, error) {
+	return p.OnDemand, nil
+}
+
+func (p *Provider) GetLicensedOnDemandPricing(
+	_ context.Context,
+	licenseModel pricing.LicenseModel,
+) (pricing.OnDemandPriceList, error) {
+	return p.Licensed[licenseModel], nil
+}
+
+func (p *Provider) GetSpotPricing(_ context.Context) (pricing.SpotPriceList, error) {
+	if len(p.SpotErrs) != 0 {
+		err := p.SpotErrs[0]
+		p.SpotErrs = p.SpotErrs[1:]
+		if err != nil {
+			return nil, err
+		}
+	}
+	return p.Spot, nil
+}
+
+func (p *Provider) GetLicensedSpotPricing(
+	_ context.Context,
+	licenseModel pricing.LicenseModel,
+) (pricing.SpotPriceList, error) {
+	if p.LicensedSpotErr != nil {
+		return nil, p.LicensedSpotErr
+	}
+	return p.LicensedSpot[licenseModel], nil
+}
+
+func (p *Provider) GetFargatePricing(_ context.Context) (pricing.FargatePrice, error) {
+	return p.Fargate, nil
+}
+
+func (p *Provider) GetRegionalFargatePricing(_ context.Context, region string) (pricing.FargatePrice, error) {
+	return p.RegionalFargate[region], nil
+}
+
+func (p *Provider) GetCapacityBlockPricing(_ context.Context) (pricing.CapacityBlockPriceList, error) {
+	return p.CapacityBlock, nil
+}
+
+func (p *Provider) GetControlPlanePricing(_ context.Context) (float64, error) {
+	if p.ControlPlaneErr != nil {
+		return 0, p.ControlPlaneErr
+	}
+	return p.ControlPlane, nil
+}
+
+func (p *Provider) GetInstanceSpecs(_ context.Context) (pricing.InstanceSpecList, error) {
+	if p.InstanceSpecsErr != nil {
+		return nil, p.InstanceSpecsErr
+	}
+	return p.InstanceSpecs, nil
+}
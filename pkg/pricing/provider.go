@@ -10,10 +10,109 @@ type OnDemandPriceList map[string]float64
 // SpotPriceList is a map of instance type and zone to spot price.
 type SpotPriceList map[string]map[string]float64
 
+// CapacityBlockPriceList is a map of instance type to EC2 Capacity Block price.
+type CapacityBlockPriceList map[string]float64
+
 // FargatePrice is the price for Fargate.
 type FargatePrice struct {
 	VCPUPerHour float64
 	GBPerHour   float64
+	// WindowsVCPUPerHour and WindowsGBPerHour are the prices for Windows containers, which are additionally charged
+	// WindowsOSPerVCPUHour for the Windows license.
+	WindowsVCPUPerHour   float64
+	WindowsGBPerHour     float64
+	WindowsOSPerVCPUHour float64
+}
+
+// InstanceSpec is the hardware specification for an instance type.
+type InstanceSpec struct {
+	VCPUs     int32
+	MemoryMiB int64
+	// NetworkPerformance is the network performance EC2 describes the instance type with, e.g. "Up to 10 Gigabit".
+	NetworkPerformance string
+	// EBSBandwidthMbps is the maximum EBS bandwidth of EBS optimized instances, 0 if unknown.
+	EBSBandwidthMbps int32
+	// InstanceStorageGB is the total size of the local instance store volumes, e.g. the NVMe SSDs of i4i instances,
+	// whose price is included in the price of the instance. 0 for instance types without an instance store.
+	InstanceStorageGB int64
+	// Architectures are the processor architectures the instance type supports as EC2 names them, e.g. "arm64" or
+	// "x86_64".
+	Architectures []string
+	// GPUs is the number of physical GPUs of the instance type, 0 for instance types without GPUs.
+	GPUs int32
+}
+
+// ec2Architectures are the EC2 names of Kubernetes architectures which differ from them.
+var ec2Architectures = map[string]string{
+	"amd64": "x86_64",
+	"386":   "i386",
+}
+
+// SupportsArchitecture returns whether the instance type supports the Kubernetes architecture arch, e.g. "arm64" or
+// "amd64". Instance types with unknown architectures are assumed to support any.
+func (s InstanceSpec) SupportsArchitecture(arch string) bool {
+	if len(s.Architectures) == 0 {
+		return true
+	}
+	if ec2Arch, ok := ec2Architectures[arch]; ok {
+		arch = ec2Arch
+	}
+	for _, architecture := range s.Architectures {
+		if architecture == arch {
+			return true
+		}
+	}
+	return false
+}
+
+// InstanceSpecList is a map of instance type to hardware specification.
+type InstanceSpecList map[string]InstanceSpec
+
+// PricingType is a kind of pricing data fetched from a Provider.
+type PricingType string
+
+const (
+	PricingTypeOnDemand      PricingType = "on-demand"
+	PricingTypeSpot          PricingType = "spot"
+	PricingTypeFargate       PricingType = "fargate"
+	PricingTypeCapacityBlock PricingType = "capacity-block"
+	PricingTypeInstanceSpecs PricingType = "instance-specs"
+	PricingTypeControlPlane  PricingType = "control-plane"
+)
+
+// PricingTypes are all pricing types.
+var PricingTypes = []PricingType{
+	PricingTypeOnDemand,
+	PricingTypeSpot,
+	PricingTypeFargate,
+	PricingTypeCapacityBlock,
+	PricingTypeInstanceSpecs,
+	PricingTypeControlPlane,
+}
+
+const (
+	PricingSourceAWS          = "aws"
+	PricingSourceStatic       = "static"
+	PricingSourceCostExplorer = "cost-explorer"
+	PricingSourceUnknown      = "unknown"
+)
+
+// ZoneScopedProvider is implemented by providers which can restrict the spot prices they fetch to the zones in use.
+type ZoneScopedProvider interface {
+	ScopeToZones(zones []string)
+}
+
+// scopeToZones restricts the spot pricing of provider to zones if it supports it.
+func scopeToZones(provider Provider, zones []string) {
+	if scoped, ok := provider.(ZoneScopedProvider); ok {
+		scoped.ScopeToZones(zones)
+	}
+}
+
+// SourceReporter is implemented by providers which can report where the data they last returned for a pricing type
+// came from, e.g. PricingSourceAWS.
+type SourceReporter interface {
+	PricingSource(PricingType) string
 }
 
 // Provider is the interface used for provider implementation.
@@ -21,4 +120,8 @@ type Provider interface {
 	GetOnDemandPricing(context.Context) (OnDemandPriceList, error)
 	GetSpotPricing(context.Context) (SpotPriceList, error)
 	GetFargatePricing(context.Context) (FargatePrice, error)
+	GetCapacityBlockPricing(context.Context) (CapacityBlockPriceList, error)
+	GetInstanceSpecs(context.Context) (InstanceSpecList, error)
+	// GetControlPlanePricing returns the flat hourly fee charged per EKS cluster.
+	GetControlPlanePricing(context.Context) (float64, error)
 }
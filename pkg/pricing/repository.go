@@ -2,6 +2,8 @@ package pricing
 
 import (
 	"context"
+	"fmt"
+	"log"
 	"sync"
 	"time"
 
@@ -9,45 +11,202 @@ import (
 	"go.uber.org/multierr"
 )
 
+// licenseKey is the key for license included on-demand prices which vary by both license model and instance type.
+type licenseKey struct {
+	licenseModel LicenseModel
+	instanceType string
+}
+
+// spotKey is the key for spot prices which vary by both instance type and zone.
+type spotKey struct {
+	instanceType string
+	zone         string
+}
+
+// licensedSpotKey is the key for the spot prices of the operating system of a license model.
+type licensedSpotKey struct {
+	licenseModel LicenseModel
+	spotKey
+}
+
 type Repository struct {
-	mu                 sync.RWMutex
-	pricingProvider    Provider
-	onDemandUpdateTime time.Time
-	onDemandPrices     OnDemandPriceList
-	spotUpdateTime     time.Time
-	spotPrices         SpotPriceList
-	fargateUpdateTime  time.Time
-	fargatePrice       FargatePrice
-}
-
-func NewRepository(provider Provider) *Repository {
-	return &Repository{
+	mu              sync.RWMutex
+	pricingProvider Provider
+	onDemandPrices  *priceCache[string, float64]
+	licensedPrices  *priceCache[licenseKey, float64]
+	licenseModels   []LicenseModel
+	spotPrices      *priceCache[spotKey, float64]
+	licensedSpot    *priceCache[licensedSpotKey, float64]
+	fargatePrice    *priceCache[string, FargatePrice]
+	fargateRegions  []string
+	providerRegion  string
+	capacityBlock   *priceCache[string, float64]
+	instanceSpecs   *priceCache[string, InstanceSpec]
+	controlPlane    *priceCache[struct{}, float64]
+	updateErrors    int
+	spotWebhook     *SpotPriceChangeWebhook
+	driftReference  Provider
+	drift           *priceCache[string, float64]
+	// sources is where the current data of each pricing type came from
+	sources map[PricingType]string
+	// previousOnDemand and previousSpot are the prices before the last refresh, see PriceDiff
+	previousOnDemand map[string]float64
+	previousSpot     map[spotKey]float64
+	// smoothedSpot is the moving average of the spot prices with the weight spotSmoothing, see SmoothedSpotPrice
+	smoothedSpot  *priceCache[spotKey, float64]
+	spotSmoothing float64
+	// onDemandEffectiveDates are when the current on-demand prices took effect, see OnDemandEffectiveDates
+	onDemandEffectiveDates EffectiveDates
+	// commitMu is held while an update stores what it fetched, see Snapshot
+	commitMu sync.RWMutex
+}
+
+// RepositoryOption configures optional behavior of the Repository.
+type RepositoryOption func(*Repository)
+
+// WithSpotPriceChangeWebhook notifies webhook when a spot price changes significantly between refreshes.
+func WithSpotPriceChangeWebhook(webhook *SpotPriceChangeWebhook) RepositoryOption {
+	return func(pr *Repository) {
+		pr.spotWebhook = webhook
+	}
+}
+
+// WithLicenseModels additionally fetches on-demand prices including the license fee and the spot prices of each license
+// model, e.g. for nodes running license included Windows or RHEL. License models without a license fee are ignored, as
+// they use the regular on-demand and spot prices.
+func WithLicenseModels(licenseModels ...LicenseModel) RepositoryOption {
+	return func(pr *Repository) {
+		for _, licenseModel := range licenseModels {
+			if licenseModel.IncludesLicense() {
+				pr.licenseModels = append(pr.licenseModels, licenseModel)
+			}
+		}
+	}
+}
+
+// WithSpotPriceTTL expires the spot price of an instance type in a zone once it hasn't been returned by a refresh for
+// ttl. Spot prices missing from a refresh are otherwise kept indefinitely.
+func WithSpotPriceTTL(ttl time.Duration) RepositoryOption {
+	return func(pr *Repository) {
+		pr.spotPrices.ttl = ttl
+		pr.licensedSpot.ttl = ttl
+		pr.smoothedSpot.ttl = ttl
+	}
+}
+
+// WithClock timestamps updates with now instead of the current time, e.g. to test staleness.
+func WithClock(now func() time.Time) RepositoryOption {
+	return func(pr *Repository) {
+		pr.onDemandPrices.now = now
+		pr.licensedPrices.now = now
+		pr.spotPrices.now = now
+		pr.licensedSpot.now = now
+		pr.smoothedSpot.now = now
+		pr.fargatePrice.now = now
+		pr.capacityBlock.now = now
+		pr.instanceSpecs.now = now
+		pr.controlPlane.now = now
+		pr.drift.now = now
+	}
+}
+
+func NewRepository(provider Provider, opts ...RepositoryOption) *Repository {
+	pr := &Repository{
 		pricingProvider: provider,
+		onDemandPrices:  newPriceCache[string, float64](0),
+		licensedPrices:  newPriceCache[licenseKey, float64](0),
+		spotPrices:      newPriceCache[spotKey, float64](0),
+		licensedSpot:    newPriceCache[licensedSpotKey, float64](0),
+		smoothedSpot:    newPriceCache[spotKey, float64](0),
+		fargatePrice:    newPriceCache[string, FargatePrice](0),
+		capacityBlock:   newPriceCache[string, float64](0),
+		instanceSpecs:   newPriceCache[string, InstanceSpec](0),
+		controlPlane:    newPriceCache[struct{}, float64](0),
+		drift:           newPriceCache[string, float64](0),
+		sources:         map[PricingType]string{},
 	}
+	for _, opt := range opts {
+		opt(pr)
+	}
+	return pr
 }
 
 func (pr *Repository) UpdateOnDemandPricing(ctx context.Context) error {
-	pricing, err := pr.pricingProvider.GetOnDemandPricing(ctx)
+	pricing, effectiveDates, err := onDemandPricingWithEffectiveDates(ctx, pr.pricingProvider)
 	if err != nil {
 		return err
 	}
-	pr.mu.Lock()
-	defer pr.mu.Unlock()
-	pr.onDemandPrices = pricing
-	pr.onDemandUpdateTime = time.Now()
+	licensed := map[licenseKey]float64{}
+	for _, licenseModel := range pr.licenseModels {
+		prices, err := licensedOnDemandPricing(ctx, pr.pricingProvider, licenseModel)
+		if err != nil {
+			return fmt.Errorf("fetching %s license pricing: %w", licenseModel, err)
+		}
+		for instanceType, price := range prices {
+			licensed[licenseKey{licenseModel: licenseModel, instanceType: instanceType}] = price
+		}
+	}
+	pr.commit(func() {
+		pr.snapshotOnDemand()
+		pr.onDemandPrices.Replace(pricing)
+		pr.licensedPrices.Replace(licensed)
+		pr.setSource(PricingTypeOnDemand)
+		pr.mu.Lock()
+		defer pr.mu.Unlock()
+		pr.onDemandEffectiveDates = effectiveDates
+	})
 	return nil
 }
 
+// UpdateSpotPricing merges the current spot prices into the known spot prices, so a price missing from a partial
+// refresh is kept until it expires (see WithSpotPriceTTL).
 func (pr *Repository) UpdateSpotPricing(ctx context.Context) error {
 	pricing, err := pr.pricingProvider.GetSpotPricing(ctx)
 	if err != nil {
 		return err
 	}
-	pr.mu.Lock()
-	defer pr.mu.Unlock()
-	pr.spotPrices = pricing
-	pr.spotUpdateTime = time.Now()
-	return nil
+	// a license model failing to update keeps its last prices rather than holding back the Linux spot prices
+	var licensedErr error
+	licensed := map[licensedSpotKey]float64{}
+	for _, licenseModel := range pr.licenseModels {
+		prices, err := licensedSpotPricing(ctx, pr.pricingProvider, licenseModel)
+		if err != nil {
+			licensedErr = multierr.Append(licensedErr, fmt.Errorf("fetching %s spot pricing: %w", licenseModel, err))
+			continue
+		}
+		for instanceType, zones := range prices {
+			for zone, price := range zones {
+				key := spotKey{instanceType: instanceType, zone: zone}
+				licensed[licensedSpotKey{licenseModel: licenseModel, spotKey: key}] = price
+			}
+		}
+	}
+	previous := pr.SpotPrices()
+	prices := map[spotKey]float64{}
+	for instanceType, zones := range pricing {
+		for zone, price := range zones {
+			prices[spotKey{instanceType: instanceType, zone: zone}] = price
+		}
+	}
+	pr.commit(func() {
+		pr.snapshotSpot()
+		pr.spotPrices.Merge(prices)
+		pr.licensedSpot.Merge(licensed)
+		if pr.spotSmoothing > 0 {
+			pr.smoothedSpot.Merge(pr.smoothSpotPrices(prices))
+		}
+		pr.setSource(PricingTypeSpot)
+	})
+
+	if pr.spotWebhook != nil {
+		if changes := pr.spotWebhook.Changes(previous, pricing); len(changes) != 0 {
+			// a failed notification shouldn't fail the update
+			if err := pr.spotWebhook.Notify(ctx, changes); err != nil {
+				log.Printf("could not notify spot price change webhook: %s", err)
+			}
+		}
+	}
+	return licensedErr
 }
 
 func (pr *Repository) UpdateFargatePricing(ctx context.Context) error {
@@ -55,109 +214,331 @@ func (pr *Repository) UpdateFargatePricing(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	// the fargate pricing of the region of the provider is kept under the empty region
+	prices := map[string]FargatePrice{"": pricing}
+	for _, region := range pr.fargateRegions {
+		regionPricing, err := regionalFargatePricing(ctx, pr.pricingProvider, region)
+		if err != nil {
+			return fmt.Errorf("getting fargate pricing for %s: %w", region, err)
+		}
+		prices[region] = regionPricing
+	}
+	pr.commit(func() {
+		pr.fargatePrice.Replace(prices)
+		pr.setSource(PricingTypeFargate)
+	})
+	return nil
+}
+
+func (pr *Repository) UpdateCapacityBlockPricing(ctx context.Context) error {
+	pricing, err := pr.pricingProvider.GetCapacityBlockPricing(ctx)
+	if err != nil {
+		return err
+	}
+	pr.commit(func() {
+		pr.capacityBlock.Replace(pricing)
+		pr.setSource(PricingTypeCapacityBlock)
+	})
+	return nil
+}
+
+func (pr *Repository) UpdateInstanceSpecs(ctx context.Context) error {
+	specs, err := pr.pricingProvider.GetInstanceSpecs(ctx)
+	if err != nil {
+		return err
+	}
+	pr.commit(func() {
+		pr.instanceSpecs.Replace(specs)
+		pr.setSource(PricingTypeInstanceSpecs)
+	})
+	return nil
+}
+
+func (pr *Repository) UpdateControlPlanePricing(ctx context.Context) error {
+	price, err := pr.pricingProvider.GetControlPlanePricing(ctx)
+	if err != nil {
+		return err
+	}
+	pr.commit(func() {
+		pr.controlPlane.Replace(map[struct{}]float64{{}: price})
+		pr.setSource(PricingTypeControlPlane)
+	})
+	return nil
+}
+
+// Seed stores the on-demand and control plane prices of provider, e.g. the StaticProvider, until the first update of
+// each replaces them, so nodes have a price while the first update is still running. It must be called before the
+// first update.
+func (pr *Repository) Seed(ctx context.Context, provider Provider) error {
+	onDemand, err := provider.GetOnDemandPricing(ctx)
+	if err != nil {
+		return fmt.Errorf("seeding on-demand pricing: %w", err)
+	}
+	controlPlane, err := provider.GetControlPlanePricing(ctx)
+	if err != nil {
+		return fmt.Errorf("seeding control plane pricing: %w", err)
+	}
+	pr.commit(func() {
+		pr.onDemandPrices.Replace(onDemand)
+		pr.controlPlane.Replace(map[struct{}]float64{{}: controlPlane})
+		pr.mu.Lock()
+		defer pr.mu.Unlock()
+		pr.sources[PricingTypeOnDemand] = pricingSource(provider, PricingTypeOnDemand)
+		pr.sources[PricingTypeControlPlane] = pricingSource(provider, PricingTypeControlPlane)
+	})
+	return nil
+}
+
+// setSource records where the data just fetched for pricingType came from.
+func (pr *Repository) setSource(pricingType PricingType) {
+	source := pricingSource(pr.pricingProvider, pricingType)
 	pr.mu.Lock()
 	defer pr.mu.Unlock()
-	pr.fargatePrice = pricing
-	pr.fargateUpdateTime = time.Now()
-	return nil
+	pr.sources[pricingType] = source
+}
+
+// PricingSources returns where the current data of each pricing type which has been fetched came from.
+func (pr *Repository) PricingSources() map[PricingType]string {
+	pr.mu.RLock()
+	defer pr.mu.RUnlock()
+	sources := make(map[PricingType]string, len(pr.sources))
+	for pricingType, source := range pr.sources {
+		sources[pricingType] = source
+	}
+	return sources
 }
 
+// pricingSource returns where provider reports the data it last returned for pricingType came from, or
+// PricingSourceUnknown if it doesn't report it.
+func pricingSource(provider Provider, pricingType PricingType) string {
+	if reporter, ok := provider.(SourceReporter); ok {
+		return reporter.PricingSource(pricingType)
+	}
+	return PricingSourceUnknown
+}
+
+// informationalPricingTypes are the pricing types which only feed informational metrics. Failing to update them is
+// logged rather than failing the update, so e.g. a missing SKU or IAM permission doesn't stop the exporter from
+// starting. Without instance specs the per-vCPU, per-GB, per-GPU and hardware metrics are left out.
+var informationalPricingTypes = map[PricingType]bool{
+	PricingTypeInstanceSpecs: true,
+	PricingTypeControlPlane:  true,
+}
+
+// UpdatePricing updates all pricing types concurrently, returning the combined errors of any that failed. A failed
+// update leaves the previously known pricing in place and increments the update error count, unless it failed because
+// ctx was cancelled or its deadline was exceeded, e.g. during shutdown, in which case the error wraps ctx.Err().
+// Failures of informational pricing types, like instance specs and the control plane fee, are only logged.
 func (pr *Repository) UpdatePricing(ctx context.Context) error {
+	return pr.UpdatePricingTypes(ctx, PricingTypes...)
+}
+
+// UpdatePricingTypes updates the given pricing types concurrently like UpdatePricing, e.g. to refresh them on their own
+// schedule.
+func (pr *Repository) UpdatePricingTypes(ctx context.Context, pricingTypes ...PricingType) error {
+	var mu sync.Mutex
 	var errs []error
 	var wg sync.WaitGroup
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		err := pr.UpdateOnDemandPricing(ctx)
-		if err != nil {
-			errs = append(errs, err)
-		}
-	}()
-
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		err := pr.UpdateSpotPricing(ctx)
-		if err != nil {
-			errs = append(errs, err)
-		}
-	}()
-
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		err := pr.UpdateFargatePricing(ctx)
-		if err != nil {
-			errs = append(errs, err)
+	updates := map[PricingType]func(context.Context) error{
+		PricingTypeOnDemand:      pr.UpdateOnDemandPricing,
+		PricingTypeSpot:          pr.UpdateSpotPricing,
+		PricingTypeFargate:       pr.UpdateFargatePricing,
+		PricingTypeCapacityBlock: pr.UpdateCapacityBlockPricing,
+		PricingTypeInstanceSpecs: pr.UpdateInstanceSpecs,
+		PricingTypeControlPlane:  pr.UpdateControlPlanePricing,
+	}
+	for _, pricingType := range pricingTypes {
+		update, ok := updates[pricingType]
+		if !ok {
+			return fmt.Errorf("unknown pricing type %q", pricingType)
 		}
-	}()
+		pricingType := pricingType
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := update(ctx)
+			if err != nil && informationalPricingTypes[pricingType] && ctx.Err() == nil {
+				log.Printf("could not update %s pricing, leaving it unchanged: %s", pricingType, err)
+				return
+			}
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
 
 	if len(errs) != 0 {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("pricing update interrupted: %w", err)
+		}
+		pr.mu.Lock()
+		pr.updateErrors++
+		pr.mu.Unlock()
 		return multierr.Combine(errs...)
 	}
 	return nil
 }
 
-// InstanceTypes returns the list of all instance types for which either a spot or on-demand price is known.
-func (pr *Repository) InstanceTypes() []string {
+// UpdateErrors returns the number of times UpdatePricing has failed.
+func (pr *Repository) UpdateErrors() int {
 	pr.mu.RLock()
 	defer pr.mu.RUnlock()
-	return lo.Union(lo.Keys(pr.onDemandPrices), lo.Keys(pr.spotPrices))
+	return pr.updateErrors
+}
+
+// InstanceTypes returns the list of all instance types for which either a spot or on-demand price is known.
+func (pr *Repository) InstanceTypes() []string {
+	return lo.Union(lo.Keys(pr.onDemandPrices.All()), lo.Keys(pr.SpotPrices()))
+}
+
+// LastUpdated returns the time that pricingType was last updated, or the zero time if it never was.
+func (pr *Repository) LastUpdated(pricingType PricingType) time.Time {
+	switch pricingType {
+	case PricingTypeOnDemand:
+		return pr.OnDemandLastUpdated()
+	case PricingTypeSpot:
+		return pr.SpotLastUpdated()
+	case PricingTypeFargate:
+		return pr.FargateLastUpdated()
+	case PricingTypeCapacityBlock:
+		return pr.CapacityBlockLastUpdated()
+	case PricingTypeInstanceSpecs:
+		return pr.InstanceSpecsLastUpdated()
+	case PricingTypeControlPlane:
+		return pr.ControlPlaneLastUpdated()
+	default:
+		return time.Time{}
+	}
 }
 
 // OnDemandLastUpdated returns the time that the on-demand pricing was last updated.
 func (pr *Repository) OnDemandLastUpdated() time.Time {
-	pr.mu.RLock()
-	defer pr.mu.RUnlock()
-	return pr.onDemandUpdateTime
+	return pr.onDemandPrices.LastUpdated()
 }
 
 // SpotLastUpdated returns the time that the spot pricing was last updated.
 func (pr *Repository) SpotLastUpdated() time.Time {
-	pr.mu.RLock()
-	defer pr.mu.RUnlock()
-	return pr.spotUpdateTime
+	return pr.spotPrices.LastUpdated()
 }
 
 // FargateLastUpdated returns the time that the Fargate pricing was last updated.
 func (pr *Repository) FargateLastUpdated() time.Time {
-	pr.mu.RLock()
-	defer pr.mu.RUnlock()
-	return pr.fargateUpdateTime
+	return pr.fargatePrice.LastUpdated()
+}
+
+// CapacityBlockLastUpdated returns the time that the Capacity Block pricing was last updated.
+func (pr *Repository) CapacityBlockLastUpdated() time.Time {
+	return pr.capacityBlock.LastUpdated()
+}
+
+// InstanceSpecsLastUpdated returns the time that the instance type specifications were last updated.
+func (pr *Repository) InstanceSpecsLastUpdated() time.Time {
+	return pr.instanceSpecs.LastUpdated()
+}
+
+// ControlPlaneLastUpdated returns the time that the EKS control plane pricing was last updated.
+func (pr *Repository) ControlPlaneLastUpdated() time.Time {
+	return pr.controlPlane.LastUpdated()
+}
+
+// InstanceSpec returns the hardware specification for a given instance type, returning false if the instance type
+// is not known.
+func (pr *Repository) InstanceSpec(instanceType string) (InstanceSpec, bool) {
+	return pr.instanceSpecs.Get(instanceType)
 }
 
 // OnDemandPrice returns the last known on-demand price for a given instance type, returning an error if there is no
 // known on-demand pricing for the instance type.
 func (pr *Repository) OnDemandPrice(instanceType string) (float64, bool) {
-	pr.mu.RLock()
-	defer pr.mu.RUnlock()
-	price, ok := pr.onDemandPrices[instanceType]
-	if !ok {
-		return 0.0, false
+	return pr.onDemandPrices.Get(instanceType)
+}
+
+// LicensedOnDemandPrice returns the on-demand price of an instance type including the license fee of licenseModel,
+// returning false if it is unknown. License models without a license fee use the regular on-demand price.
+func (pr *Repository) LicensedOnDemandPrice(instanceType string, licenseModel LicenseModel) (float64, bool) {
+	if !licenseModel.IncludesLicense() {
+		return pr.OnDemandPrice(instanceType)
+	}
+	return pr.licensedPrices.Get(licenseKey{licenseModel: licenseModel, instanceType: instanceType})
+}
+
+// CapacityBlockPrice returns the last known Capacity Block price for a given instance type, returning false if there
+// is no known Capacity Block pricing for the instance type.
+func (pr *Repository) CapacityBlockPrice(instanceType string) (float64, bool) {
+	return pr.capacityBlock.Get(instanceType)
+}
+
+// ControlPlanePrice returns the last known hourly fee charged per EKS cluster, returning false if it is not known.
+func (pr *Repository) ControlPlanePrice() (float64, bool) {
+	price, ok := pr.controlPlane.Get(struct{}{})
+	if !ok || price == 0 {
+		return 0, false
 	}
 	return price, true
 }
 
-func (pr *Repository) FargatePrice(cpu, memory float64) (float64, bool) {
-	pr.mu.RLock()
-	defer pr.mu.RUnlock()
-	if pr.fargatePrice.GBPerHour == 0 || pr.fargatePrice.VCPUPerHour == 0 {
+// FargatePrice returns the hourly price of a Fargate pod with the given vCPUs and GB of memory in region, returning
+// false if there is no known Fargate pricing. Regions not passed to WithFargateRegions use the pricing of the region of
+// the provider.
+func (pr *Repository) FargatePrice(region string, cpu, memory float64) (float64, bool) {
+	fargatePrice, ok := pr.regionFargatePrice(region)
+	if !ok || fargatePrice.GBPerHour == 0 || fargatePrice.VCPUPerHour == 0 {
 		return 0, false
 	}
-	return cpu*pr.fargatePrice.VCPUPerHour + memory*pr.fargatePrice.GBPerHour, true
+	return cpu*fargatePrice.VCPUPerHour + memory*fargatePrice.GBPerHour, true
+}
+
+// FargateWindowsPrice returns the hourly price of a Windows Fargate pod with the given vCPUs and GB of memory,
+// including the Windows license fee, returning false if there is no known Windows Fargate pricing.
+func (pr *Repository) FargateWindowsPrice(region string, cpu, memory float64) (float64, bool) {
+	fargatePrice, ok := pr.regionFargatePrice(region)
+	if !ok || fargatePrice.WindowsGBPerHour == 0 || fargatePrice.WindowsVCPUPerHour == 0 {
+		return 0, false
+	}
+	return cpu*(fargatePrice.WindowsVCPUPerHour+fargatePrice.WindowsOSPerVCPUHour) +
+		memory*fargatePrice.WindowsGBPerHour, true
+}
+
+// regionFargatePrice returns the Fargate pricing of region, falling back to that of the region of the provider.
+func (pr *Repository) regionFargatePrice(region string) (FargatePrice, bool) {
+	if fargatePrice, ok := pr.fargatePrice.Get(region); ok {
+		return fargatePrice, true
+	}
+	// the prices of the provider's region would be wrong for other regions
+	if region != "" && pr.providerRegion != "" && region != pr.providerRegion {
+		return FargatePrice{}, false
+	}
+	return pr.fargatePrice.Get("")
 }
 
 // SpotPrice returns the last known spot price for a given instance type and zone, returning an error
 // if there is no known spot pricing for that instance type or zone.
 func (pr *Repository) SpotPrice(instanceType string, zone string) (float64, bool) {
-	pr.mu.RLock()
-	defer pr.mu.RUnlock()
-	if _, ok := pr.spotPrices[instanceType]; ok {
-		if price, ok := pr.spotPrices[instanceType][zone]; ok {
-			return price, true
+	return pr.spotPrices.Get(spotKey{instanceType: instanceType, zone: zone})
+}
+
+// LicensedSpotPrice returns the spot price of an instance type in a zone for the operating system of licenseModel,
+// returning false if it is unknown. License models without a license fee use the regular spot price.
+func (pr *Repository) LicensedSpotPrice(instanceType string, zone string, licenseModel LicenseModel) (float64, bool) {
+	if !licenseModel.IncludesLicense() {
+		return pr.SpotPrice(instanceType, zone)
+	}
+	key := spotKey{instanceType: instanceType, zone: zone}
+	return pr.licensedSpot.Get(licensedSpotKey{licenseModel: licenseModel, spotKey: key})
+}
+
+// SpotPrices returns all known spot prices.
+func (pr *Repository) SpotPrices() SpotPriceList {
+	prices := SpotPriceList{}
+	for k, price := range pr.spotPrices.All() {
+		if _, ok := prices[k.instanceType]; !ok {
+			prices[k.instanceType] = map[string]float64{}
 		}
-		return 0.0, false
+		prices[k.instanceType][k.zone] = price
 	}
-	return 0.0, false
+	return prices
 }
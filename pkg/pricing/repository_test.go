@@ -0,0 +1,237 @@
+package pricing_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/aws/smithy-go"
+
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing"
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing/pricingtest"
+)
+
+func TestRepositoryUpdatePricingRecoversFromExpiredCredentials(t *testing.T) {
+	provider := &pricingtest.Provider{
+		OnDemand: pricing.OnDemandPriceList{"m5.large": 0.096},
+		Spot: pricing.SpotPriceList{
+			"m5.large": {"us-east-1a": 0.035},
+		},
+		SpotErrs: []error{
+			&smithy.GenericAPIError{
+				Code:    "ExpiredTokenException",
+				Message: "The security token included in the request is expired",
+			},
+		},
+	}
+	pr := pricing.NewRepository(provider)
+	ctx := context.Background()
+
+	if err := pr.UpdatePricing(ctx); err == nil {
+		t.Fatalf("expected first update to fail with expired credentials")
+	}
+	if exp, got := 1, pr.UpdateErrors(); exp != got {
+		t.Errorf("expected UpdateErrors == %d, got %d", exp, got)
+	}
+	if _, ok := pr.SpotPrice("m5.large", "us-east-1a"); ok {
+		t.Errorf("expected spot price to be unknown after failed update")
+	}
+	if _, ok := pr.OnDemandPrice("m5.large"); !ok {
+		t.Errorf("expected on-demand price to be updated despite spot failure")
+	}
+
+	if err := pr.UpdatePricing(ctx); err != nil {
+		t.Fatalf("expected second update to succeed, got %s", err)
+	}
+	if exp, got := 1, pr.UpdateErrors(); exp != got {
+		t.Errorf("expected UpdateErrors == %d, got %d", exp, got)
+	}
+	if price, ok := pr.SpotPrice("m5.large", "us-east-1a"); !ok || price != 0.035 {
+		t.Errorf("expected spot price 0.035 after recovery, got %f (ok=%v)", price, ok)
+	}
+}
+
+func TestRepositoryUpdatePricingInstanceSpecsFailureNotFatal(t *testing.T) {
+	pr := pricing.NewRepository(&pricingtest.Provider{
+		OnDemand:         pricing.OnDemandPriceList{"m5.large": 0.096},
+		InstanceSpecsErr: errors.New("UnauthorizedOperation: not authorized to perform ec2:DescribeInstanceTypes"),
+	})
+	if err := pr.UpdatePricing(context.Background()); err != nil {
+		t.Fatalf("expected an instance specs failure not to fail the update, got %s", err)
+	}
+	if spec, ok := pr.InstanceSpec("m5.large"); ok {
+		t.Errorf("expected the instance spec to be unknown, got %+v", spec)
+	}
+}
+
+func TestRepositoryUpdateSpotPricingLicensedFailure(t *testing.T) {
+	pr := pricing.NewRepository(&pricingtest.Provider{
+		Spot:            pricing.SpotPriceList{"m5.large": {"us-east-1a": 0.035}},
+		LicensedSpotErr: errors.New("no windows spot pricing found"),
+	}, pricing.WithLicenseModels(pricing.LicenseModelWindows))
+	if err := pr.UpdateSpotPricing(context.Background()); err == nil {
+		t.Errorf("expected an error fetching the windows spot prices")
+	}
+	if price, ok := pr.SpotPrice("m5.large", "us-east-1a"); !ok || price != 0.035 {
+		t.Errorf("expected the linux spot price to be updated anyway, got %v (%v)", price, ok)
+	}
+}
+
+func TestRepositoryUpdatePricingControlPlaneFailureNotFatal(t *testing.T) {
+	pr := pricing.NewRepository(&pricingtest.Provider{
+		OnDemand:        pricing.OnDemandPriceList{"m5.large": 0.096},
+		ControlPlaneErr: errors.New("no EKS control plane pricing found"),
+	})
+	if err := pr.UpdatePricing(context.Background()); err != nil {
+		t.Fatalf("expected a control plane pricing failure not to fail the update, got %s", err)
+	}
+	if exp, got := 0, pr.UpdateErrors(); exp != got {
+		t.Errorf("expected UpdateErrors == %d, got %d", exp, got)
+	}
+	if price, ok := pr.ControlPlanePrice(); ok {
+		t.Errorf("expected the control plane price to be unknown, got %v", price)
+	}
+	if _, ok := pr.OnDemandPrice("m5.large"); !ok {
+		t.Errorf("expected on-demand price to be updated")
+	}
+}
+
+func TestRepositoryFargatePriceUnlistedRegion(t *testing.T) {
+	provider := &pricingtest.Provider{
+		Fargate: pricing.FargatePrice{VCPUPerHour: 0.5, GBPerHour: 0.25},
+	}
+	for name, tc := range map[string]struct {
+		providerRegion string
+		region         string
+		known          bool
+	}{
+		"provider region":         {providerRegion: "us-east-1", region: "us-east-1", known: true},
+		"node without region":     {providerRegion: "us-east-1", region: "", known: true},
+		"unlisted region":         {providerRegion: "us-east-1", region: "eu-west-1", known: false},
+		"unknown provider region": {providerRegion: "", region: "eu-west-1", known: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			pr := pricing.NewRepository(provider, pricing.WithProviderRegion(tc.providerRegion))
+			if err := pr.UpdateFargatePricing(context.Background()); err != nil {
+				t.Fatalf("unexpected error updating fargate pricing: %s", err)
+			}
+			if _, ok := pr.FargatePrice(tc.region, 1, 2); ok != tc.known {
+				t.Errorf("expected the fargate price in %q to be known == %v", tc.region, tc.known)
+			}
+		})
+	}
+}
+
+// generationProvider returns the generation as both the on-demand and the license included price of m5.large,
+// bumping the generation on every on-demand refresh.
+type generationProvider struct {
+	pricingtest.Provider
+	generation float64
+}
+
+func (p *generationProvider) GetOnDemandPricing(_ context.Context) (pricing.OnDemandPriceList, error) {
+	p.generation++
+	return pricing.OnDemandPriceList{"m5.large": p.generation}, nil
+}
+
+func (p *generationProvider) GetLicensedOnDemandPricing(
+	_ context.Context,
+	_ pricing.LicenseModel,
+) (pricing.OnDemandPriceList, error) {
+	return pricing.OnDemandPriceList{"m5.large": p.generation}, nil
+}
+
+func TestRepositorySnapshotConsistent(t *testing.T) {
+	pr := pricing.NewRepository(&generationProvider{}, pricing.WithLicenseModels(pricing.LicenseModelWindows))
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := pr.UpdateOnDemandPricing(ctx); err != nil {
+			t.Fatalf("unexpected error updating on-demand pricing: %s", err)
+		}
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if err := pr.UpdateOnDemandPricing(ctx); err != nil {
+				t.Errorf("unexpected error updating on-demand pricing: %s", err)
+				return
+			}
+		}
+	}()
+	defer func() {
+		close(done)
+		wg.Wait()
+	}()
+
+	for i := 0; i < 10000; i++ {
+		snapshot := pr.Snapshot()
+		onDemand, _ := snapshot.OnDemandPrice("m5.large")
+		licensed, _ := snapshot.LicensedOnDemandPrice("m5.large", pricing.LicenseModelWindows)
+		if onDemand != licensed {
+			t.Fatalf("expected the on-demand and license included price of one refresh, got %v and %v", onDemand, licensed)
+		}
+		// every refresh bumps the price, so the previous refresh was one less
+		diff := snapshot.PriceDiff()
+		if len(diff) != 1 || *diff[0].OldPrice != onDemand-1 || *diff[0].NewPrice != onDemand {
+			t.Fatalf("expected the price diff of the refresh of %v, got %+v", onDemand, diff)
+		}
+		if again, _ := snapshot.OnDemandPrice("m5.large"); again != onDemand {
+			t.Fatalf("expected the snapshot on-demand price to stay %v, got %v", onDemand, again)
+		}
+	}
+}
+
+func TestRepositorySpotPriceSmoothingConverges(t *testing.T) {
+	provider := &pricingtest.Provider{
+		Spot: pricing.SpotPriceList{"m5.large": {"us-east-1a": 1}},
+	}
+	pr := pricing.NewRepository(provider, pricing.WithSpotPriceSmoothing(0.5))
+	ctx := context.Background()
+	if err := pr.UpdateSpotPricing(ctx); err != nil {
+		t.Fatalf("unexpected error updating spot pricing: %s", err)
+	}
+	if smoothed, ok := pr.SmoothedSpotPrice("m5.large", "us-east-1a"); !ok || smoothed != 1 {
+		t.Fatalf("expected the first price to start the moving average, got %v (%v)", smoothed, ok)
+	}
+
+	provider.Spot = pricing.SpotPriceList{"m5.large": {"us-east-1a": 0.5}}
+	for i := 1; i <= 10; i++ {
+		if err := pr.UpdateSpotPricing(ctx); err != nil {
+			t.Fatalf("unexpected error updating spot pricing: %s", err)
+		}
+		// each refresh halves the distance to the steady price
+		exp := 0.5 + 0.5/math.Pow(2, float64(i))
+		if smoothed, _ := pr.SmoothedSpotPrice("m5.large", "us-east-1a"); smoothed != exp {
+			t.Errorf("refresh %d: expected smoothed price == %v, got %v", i, exp, smoothed)
+		}
+	}
+	if smoothed, _ := pr.SmoothedSpotPrice("m5.large", "us-east-1a"); math.Abs(smoothed-0.5) > 0.001 {
+		t.Errorf("expected the smoothed price to converge to 0.5, got %v", smoothed)
+	}
+	if raw, _ := pr.SpotPrice("m5.large", "us-east-1a"); raw != 0.5 {
+		t.Errorf("expected the raw spot price to be kept, got %v", raw)
+	}
+}
+
+func TestRepositorySpotPriceSmoothingDisabled(t *testing.T) {
+	pr := pricing.NewRepository(&pricingtest.Provider{
+		Spot: pricing.SpotPriceList{"m5.large": {"us-east-1a": 1}},
+	})
+	if err := pr.UpdateSpotPricing(context.Background()); err != nil {
+		t.Fatalf("unexpected error updating spot pricing: %s", err)
+	}
+	if smoothed, ok := pr.SmoothedSpotPrice("m5.large", "us-east-1a"); ok {
+		t.Errorf("expected no smoothed price without smoothing, got %v", smoothed)
+	}
+}
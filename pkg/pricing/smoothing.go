@@ -0,0 +1,30 @@
+package pricing
+
+// WithSpotPriceSmoothing keeps an exponentially weighted moving average of the spot price of each instance type in
+// each zone across refreshes, see SmoothedSpotPrice. alpha is the weight of the newest price between 0 and 1, so
+// lower values smooth more. A price seen for the first time starts the average. An alpha of 0 disables smoothing.
+func WithSpotPriceSmoothing(alpha float64) RepositoryOption {
+	return func(pr *Repository) {
+		pr.spotSmoothing = alpha
+	}
+}
+
+// smoothSpotPrices returns the moving averages of the spot prices after a refresh returned prices. It must be called
+// while committing the refresh.
+func (pr *Repository) smoothSpotPrices(prices map[spotKey]float64) map[spotKey]float64 {
+	smoothed := make(map[spotKey]float64, len(prices))
+	for key, price := range prices {
+		if previous, ok := pr.smoothedSpot.Get(key); ok {
+			price = pr.spotSmoothing*price + (1-pr.spotSmoothing)*previous
+		}
+		smoothed[key] = price
+	}
+	return smoothed
+}
+
+// SmoothedSpotPrice returns the moving average of the spot price of an instance type in a zone, returning false if it
+// is unknown or smoothing isn't enabled with WithSpotPriceSmoothing. Like SpotPrice, it is the price without any
+// license fee.
+func (pr *Repository) SmoothedSpotPrice(instanceType string, zone string) (float64, bool) {
+	return pr.smoothedSpot.Get(spotKey{instanceType: instanceType, zone: zone})
+}
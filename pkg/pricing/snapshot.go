@@ -0,0 +1,47 @@
+package pricing
+
+// Snapshot returns a copy of the repository as of now, e.g. to price every node of a collection against the same
+// pricing. Updates to the repository are stored atomically with respect to Snapshot, so a snapshot never sees part of
+// an update, like the on-demand prices of a refresh alongside the license included prices of the one before. Prices
+// in the snapshot don't expire. The snapshot must not be updated itself.
+func (pr *Repository) Snapshot() *Repository {
+	pr.commitMu.RLock()
+	defer pr.commitMu.RUnlock()
+	pr.mu.RLock()
+	defer pr.mu.RUnlock()
+
+	sources := make(map[PricingType]string, len(pr.sources))
+	for pricingType, source := range pr.sources {
+		sources[pricingType] = source
+	}
+	return &Repository{
+		onDemandPrices: pr.onDemandPrices.snapshot(),
+		licensedPrices: pr.licensedPrices.snapshot(),
+		licenseModels:  pr.licenseModels,
+		spotPrices:     pr.spotPrices.snapshot(),
+		licensedSpot:   pr.licensedSpot.snapshot(),
+		smoothedSpot:   pr.smoothedSpot.snapshot(),
+		spotSmoothing:  pr.spotSmoothing,
+		fargatePrice:   pr.fargatePrice.snapshot(),
+		fargateRegions: pr.fargateRegions,
+		providerRegion: pr.providerRegion,
+		capacityBlock:  pr.capacityBlock.snapshot(),
+		instanceSpecs:  pr.instanceSpecs.snapshot(),
+		controlPlane:   pr.controlPlane.snapshot(),
+		updateErrors:   pr.updateErrors,
+		drift:          pr.drift.snapshot(),
+		sources:        sources,
+		// the previous prices are replaced rather than modified, so can be shared
+		previousOnDemand: pr.previousOnDemand,
+		previousSpot:     pr.previousSpot,
+		// as are the effective dates
+		onDemandEffectiveDates: pr.onDemandEffectiveDates,
+	}
+}
+
+// commit stores the results of an update with store, which a Snapshot sees either all or none of.
+func (pr *Repository) commit(store func()) {
+	pr.commitMu.Lock()
+	defer pr.commitMu.Unlock()
+	store()
+}
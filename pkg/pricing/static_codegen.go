@@ -0,0 +1,84 @@
+package pricing
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const staticPricesHeader = `/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package pricing
+
+import "time"
+`
+
+// staticPricesLineWidth is the width after which a line of the generated source is wrapped, measured without the
+// spacing added by gofmt.
+const staticPricesLineWidth = 80
+
+// WriteStaticOnDemandPrices writes Go source for the on-demand price snapshot used by the StaticProvider, i.e. the
+// contents of zz_generated.pricing.go.
+func WriteStaticOnDemandPrices(w io.Writer, region string, generatedAt time.Time, prices OnDemandPriceList) error {
+	instanceTypes := make([]string, 0, len(prices))
+	for instanceType := range prices {
+		instanceTypes = append(instanceTypes, instanceType)
+	}
+	sort.Strings(instanceTypes)
+
+	// group instance types by family, which are already in order
+	var families [][]string
+	lastFamily := ""
+	for _, instanceType := range instanceTypes {
+		family, _, _ := strings.Cut(instanceType, ".")
+		if len(families) == 0 || family != lastFamily {
+			families = append(families, nil)
+			lastFamily = family
+		}
+		families[len(families)-1] = append(families[len(families)-1], instanceType)
+	}
+
+	generated := generatedAt.UTC().Format(time.RFC3339)
+	bw := bufio.NewWriter(w)
+	fmt.Fprint(bw, staticPricesHeader)
+	fmt.Fprintf(bw, "\n// generated at %s for %s\n\n", generated, region)
+	fmt.Fprintf(bw, "var initialPriceUpdate, _ = time.Parse(time.RFC3339, %q)\n", generated)
+	fmt.Fprintln(bw, "var initialOnDemandPrices = map[string]float64{")
+	for _, family := range families {
+		name, _, _ := strings.Cut(family[0], ".")
+		fmt.Fprintf(bw, "\t// %s family\n", name)
+		var line []string
+		lineWidth := 0
+		for _, instanceType := range family {
+			// the shortest representation that parses back to the same price, as %f would round it to 6 decimals
+			price := strconv.FormatFloat(prices[instanceType], 'f', -1, 64)
+			line = append(line, fmt.Sprintf("%q: %s", instanceType, price))
+			lineWidth += len(fmt.Sprintf("%q:%s, ", instanceType, price))
+			if lineWidth > staticPricesLineWidth {
+				fmt.Fprintf(bw, "\t%s,\n", strings.Join(line, ", "))
+				line = nil
+				lineWidth = 0
+			}
+		}
+		if len(line) != 0 {
+			fmt.Fprintf(bw, "\t%s,\n", strings.Join(line, ", "))
+		}
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
@@ -0,0 +1,23 @@
+package pricing
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestWriteStaticOnDemandPricesRoundTrip(t *testing.T) {
+	expected, err := os.ReadFile("zz_generated.pricing.go")
+	if err != nil {
+		t.Fatalf("unexpected error reading generated prices: %s", err)
+	}
+
+	var out bytes.Buffer
+	err = WriteStaticOnDemandPrices(&out, "us-east-1", initialPriceUpdate, initialOnDemandPrices)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !bytes.Equal(expected, out.Bytes()) {
+		t.Errorf("expected regenerating the static prices to reproduce zz_generated.pricing.go, got:\n%s", out.String())
+	}
+}
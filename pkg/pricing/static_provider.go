@@ -10,6 +10,10 @@ func NewStaticProvider() *StaticProvider {
 	return &StaticProvider{}
 }
 
+func (p *StaticProvider) PricingSource(_ PricingType) string {
+	return PricingSourceStatic
+}
+
 func (p *StaticProvider) GetOnDemandPricing(_ context.Context) (OnDemandPriceList, error) {
 	return initialOnDemandPrices, nil
 }
@@ -21,3 +25,15 @@ func (p *StaticProvider) GetSpotPricing(_ context.Context) (SpotPriceList, error
 func (p *StaticProvider) GetFargatePricing(_ context.Context) (FargatePrice, error) {
 	return FargatePrice{}, nil
 }
+
+func (p *StaticProvider) GetCapacityBlockPricing(_ context.Context) (CapacityBlockPriceList, error) {
+	return make(CapacityBlockPriceList), nil
+}
+
+func (p *StaticProvider) GetControlPlanePricing(_ context.Context) (float64, error) {
+	return 0.10, nil
+}
+
+func (p *StaticProvider) GetInstanceSpecs(_ context.Context) (InstanceSpecList, error) {
+	return make(InstanceSpecList), nil
+}
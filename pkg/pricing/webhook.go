@@ -0,0 +1,97 @@
+package pricing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// SpotPriceChange is a change in the spot price of an instance type in a zone between two refreshes.
+type SpotPriceChange struct {
+	InstanceType  string  `json:"instanceType"`
+	Zone          string  `json:"zone"`
+	OldPrice      float64 `json:"oldPrice"`
+	NewPrice      float64 `json:"newPrice"`
+	ChangePercent float64 `json:"changePercent"`
+}
+
+// SpotPriceChangeWebhook POSTs spot price changes larger than a threshold to a URL.
+type SpotPriceChangeWebhook struct {
+	URL string
+	// ThresholdPercent is the minimum absolute change, in percent of the old price, to notify about.
+	ThresholdPercent float64
+	Client           *http.Client
+}
+
+// NewSpotPriceChangeWebhook returns a SpotPriceChangeWebhook for url that notifies about changes larger than
+// thresholdPercent.
+func NewSpotPriceChangeWebhook(url string, thresholdPercent float64) *SpotPriceChangeWebhook {
+	return &SpotPriceChangeWebhook{
+		URL:              url,
+		ThresholdPercent: thresholdPercent,
+		Client:           &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Changes returns the spot prices that changed by more than the threshold between oldPrices and newPrices. Prices
+// that are only present in one of the lists are not considered changes.
+func (w *SpotPriceChangeWebhook) Changes(oldPrices, newPrices SpotPriceList) []SpotPriceChange {
+	var changes []SpotPriceChange
+	for instanceType, zones := range newPrices {
+		for zone, newPrice := range zones {
+			oldPrice, ok := oldPrices[instanceType][zone]
+			if !ok || oldPrice == 0 {
+				continue
+			}
+			changePercent := (newPrice - oldPrice) / oldPrice * 100
+			if math.Abs(changePercent) <= w.ThresholdPercent {
+				continue
+			}
+			changes = append(changes, SpotPriceChange{
+				InstanceType:  instanceType,
+				Zone:          zone,
+				OldPrice:      oldPrice,
+				NewPrice:      newPrice,
+				ChangePercent: changePercent,
+			})
+		}
+	}
+	sort.Slice(changes, func(a, b int) bool {
+		if changes[a].InstanceType == changes[b].InstanceType {
+			return changes[a].Zone < changes[b].Zone
+		}
+		return changes[a].InstanceType < changes[b].InstanceType
+	})
+	return changes
+}
+
+// Notify POSTs the changes to the webhook URL as JSON.
+func (w *SpotPriceChangeWebhook) Notify(ctx context.Context, changes []SpotPriceChange) error {
+	body, err := json.Marshal(struct {
+		Changes []SpotPriceChange `json:"changes"`
+	}{
+		Changes: changes,
+	})
+	if err != nil {
+		return fmt.Errorf("encoding spot price changes: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("spot price change webhook returned %s", resp.Status)
+	}
+	return nil
+}
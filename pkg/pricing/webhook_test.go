@@ -0,0 +1,77 @@
+package pricing_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing"
+	"github.com/sapslaj/eks-pricing-exporter/pkg/pricing/pricingtest"
+)
+
+func TestSpotPriceChangeWebhook(t *testing.T) {
+	var received []pricing.SpotPriceChange
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		var body struct {
+			Changes []pricing.SpotPriceChange `json:"changes"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding webhook body: %s", err)
+		}
+		received = body.Changes
+	}))
+	defer server.Close()
+
+	provider := &pricingtest.Provider{
+		Spot: pricing.SpotPriceList{
+			"m5.large":  {"us-east-1a": 0.04},
+			"m5.xlarge": {"us-east-1a": 0.08},
+		},
+	}
+	pr := pricing.NewRepository(
+		provider,
+		pricing.WithSpotPriceChangeWebhook(pricing.NewSpotPriceChangeWebhook(server.URL, 20)),
+	)
+	ctx := context.Background()
+
+	if err := pr.UpdateSpotPricing(ctx); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp, got := 0, calls; exp != got {
+		t.Fatalf("expected %d webhook calls on first refresh, got %d", exp, got)
+	}
+
+	// m5.large moves 10% which is under the threshold, m5.xlarge moves 50%
+	provider.Spot = pricing.SpotPriceList{
+		"m5.large":  {"us-east-1a": 0.044},
+		"m5.xlarge": {"us-east-1a": 0.12},
+	}
+	if err := pr.UpdateSpotPricing(ctx); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp, got := 1, calls; exp != got {
+		t.Fatalf("expected %d webhook calls, got %d", exp, got)
+	}
+	if exp, got := 1, len(received); exp != got {
+		t.Fatalf("expected %d changes, got %d", exp, got)
+	}
+	change := received[0]
+	if change.InstanceType != "m5.xlarge" || change.Zone != "us-east-1a" {
+		t.Errorf("expected change for m5.xlarge in us-east-1a, got %s in %s", change.InstanceType, change.Zone)
+	}
+	if change.OldPrice != 0.08 || change.NewPrice != 0.12 {
+		t.Errorf("expected change from 0.08 to 0.12, got %f to %f", change.OldPrice, change.NewPrice)
+	}
+
+	// no changes
+	if err := pr.UpdateSpotPricing(ctx); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp, got := 1, calls; exp != got {
+		t.Errorf("expected %d webhook calls, got %d", exp, got)
+	}
+}
@@ -20,301 +20,287 @@ import "time"
 var initialPriceUpdate, _ = time.Parse(time.RFC3339, "2022-12-05T13:09:08Z")
 var initialOnDemandPrices = map[string]float64{
 	// a1 family
-	"a1.2xlarge": 0.204000, "a1.4xlarge": 0.408000, "a1.large": 0.051000, "a1.medium": 0.025500,
-	"a1.metal": 0.408000, "a1.xlarge": 0.102000,
+	"a1.2xlarge": 0.204, "a1.4xlarge": 0.408, "a1.large": 0.051, "a1.medium": 0.0255, "a1.metal": 0.408,
+	"a1.xlarge": 0.102,
 	// c1 family
-	"c1.medium": 0.130000, "c1.xlarge": 0.520000,
+	"c1.medium": 0.13, "c1.xlarge": 0.52,
 	// c3 family
-	"c3.2xlarge": 0.420000, "c3.4xlarge": 0.840000, "c3.8xlarge": 1.680000, "c3.large": 0.105000,
-	"c3.xlarge": 0.210000,
+	"c3.2xlarge": 0.42, "c3.4xlarge": 0.84, "c3.8xlarge": 1.68, "c3.large": 0.105, "c3.xlarge": 0.21,
 	// c4 family
-	"c4.2xlarge": 0.398000, "c4.4xlarge": 0.796000, "c4.8xlarge": 1.591000, "c4.large": 0.100000,
-	"c4.xlarge": 0.199000,
+	"c4.2xlarge": 0.398, "c4.4xlarge": 0.796, "c4.8xlarge": 1.591, "c4.large": 0.1, "c4.xlarge": 0.199,
 	// c5 family
-	"c5.12xlarge": 2.040000, "c5.18xlarge": 3.060000, "c5.24xlarge": 4.080000, "c5.2xlarge": 0.340000,
-	"c5.4xlarge": 0.680000, "c5.9xlarge": 1.530000, "c5.large": 0.085000, "c5.metal": 4.080000,
-	"c5.xlarge": 0.170000,
+	"c5.12xlarge": 2.04, "c5.18xlarge": 3.06, "c5.24xlarge": 4.08, "c5.2xlarge": 0.34, "c5.4xlarge": 0.68,
+	"c5.9xlarge": 1.53, "c5.large": 0.085, "c5.metal": 4.08, "c5.xlarge": 0.17,
 	// c5a family
-	"c5a.12xlarge": 1.848000, "c5a.16xlarge": 2.464000, "c5a.24xlarge": 3.696000, "c5a.2xlarge": 0.308000,
-	"c5a.4xlarge": 0.616000, "c5a.8xlarge": 1.232000, "c5a.large": 0.077000, "c5a.xlarge": 0.154000,
+	"c5a.12xlarge": 1.848, "c5a.16xlarge": 2.464, "c5a.24xlarge": 3.696, "c5a.2xlarge": 0.308,
+	"c5a.4xlarge": 0.616, "c5a.8xlarge": 1.232, "c5a.large": 0.077, "c5a.xlarge": 0.154,
 	// c5ad family
-	"c5ad.12xlarge": 2.064000, "c5ad.16xlarge": 2.752000, "c5ad.24xlarge": 4.128000, "c5ad.2xlarge": 0.344000,
-	"c5ad.4xlarge": 0.688000, "c5ad.8xlarge": 1.376000, "c5ad.large": 0.086000, "c5ad.xlarge": 0.172000,
+	"c5ad.12xlarge": 2.064, "c5ad.16xlarge": 2.752, "c5ad.24xlarge": 4.128, "c5ad.2xlarge": 0.344,
+	"c5ad.4xlarge": 0.688, "c5ad.8xlarge": 1.376, "c5ad.large": 0.086, "c5ad.xlarge": 0.172,
 	// c5d family
-	"c5d.12xlarge": 2.304000, "c5d.18xlarge": 3.456000, "c5d.24xlarge": 4.608000, "c5d.2xlarge": 0.384000,
-	"c5d.4xlarge": 0.768000, "c5d.9xlarge": 1.728000, "c5d.large": 0.096000, "c5d.metal": 4.608000,
-	"c5d.xlarge": 0.192000,
+	"c5d.12xlarge": 2.304, "c5d.18xlarge": 3.456, "c5d.24xlarge": 4.608, "c5d.2xlarge": 0.384,
+	"c5d.4xlarge": 0.768, "c5d.9xlarge": 1.728, "c5d.large": 0.096, "c5d.metal": 4.608, "c5d.xlarge": 0.192,
 	// c5n family
-	"c5n.18xlarge": 3.888000, "c5n.2xlarge": 0.432000, "c5n.4xlarge": 0.864000, "c5n.9xlarge": 1.944000,
-	"c5n.large": 0.108000, "c5n.metal": 3.888000, "c5n.xlarge": 0.216000,
+	"c5n.18xlarge": 3.888, "c5n.2xlarge": 0.432, "c5n.4xlarge": 0.864, "c5n.9xlarge": 1.944,
+	"c5n.large": 0.108, "c5n.metal": 3.888, "c5n.xlarge": 0.216,
 	// c6a family
-	"c6a.12xlarge": 1.836000, "c6a.16xlarge": 2.448000, "c6a.24xlarge": 3.672000, "c6a.2xlarge": 0.306000,
-	"c6a.32xlarge": 4.896000, "c6a.48xlarge": 7.344000, "c6a.4xlarge": 0.612000, "c6a.8xlarge": 1.224000,
-	"c6a.large": 0.076500, "c6a.metal": 7.344000, "c6a.xlarge": 0.153000,
+	"c6a.12xlarge": 1.836, "c6a.16xlarge": 2.448, "c6a.24xlarge": 3.672, "c6a.2xlarge": 0.306,
+	"c6a.32xlarge": 4.896, "c6a.48xlarge": 7.344, "c6a.4xlarge": 0.612, "c6a.8xlarge": 1.224,
+	"c6a.large": 0.0765, "c6a.metal": 7.344, "c6a.xlarge": 0.153,
 	// c6g family
-	"c6g.12xlarge": 1.632000, "c6g.16xlarge": 2.176000, "c6g.2xlarge": 0.272000, "c6g.4xlarge": 0.544000,
-	"c6g.8xlarge": 1.088000, "c6g.large": 0.068000, "c6g.medium": 0.034000, "c6g.metal": 2.306600,
-	"c6g.xlarge": 0.136000,
+	"c6g.12xlarge": 1.632, "c6g.16xlarge": 2.176, "c6g.2xlarge": 0.272, "c6g.4xlarge": 0.544,
+	"c6g.8xlarge": 1.088, "c6g.large": 0.068, "c6g.medium": 0.034, "c6g.metal": 2.3066, "c6g.xlarge": 0.136,
 	// c6gd family
-	"c6gd.12xlarge": 1.843200, "c6gd.16xlarge": 2.457600, "c6gd.2xlarge": 0.307200, "c6gd.4xlarge": 0.614400,
-	"c6gd.8xlarge": 1.228800, "c6gd.large": 0.076800, "c6gd.medium": 0.038400, "c6gd.metal": 2.605100,
-	"c6gd.xlarge": 0.153600,
+	"c6gd.12xlarge": 1.8432, "c6gd.16xlarge": 2.4576, "c6gd.2xlarge": 0.3072, "c6gd.4xlarge": 0.6144,
+	"c6gd.8xlarge": 1.2288, "c6gd.large": 0.0768, "c6gd.medium": 0.0384, "c6gd.metal": 2.6051,
+	"c6gd.xlarge": 0.1536,
 	// c6gn family
-	"c6gn.12xlarge": 2.073600, "c6gn.16xlarge": 2.764800, "c6gn.2xlarge": 0.345600, "c6gn.4xlarge": 0.691200,
-	"c6gn.8xlarge": 1.382400, "c6gn.large": 0.086400, "c6gn.medium": 0.043200, "c6gn.xlarge": 0.172800,
+	"c6gn.12xlarge": 2.0736, "c6gn.16xlarge": 2.7648, "c6gn.2xlarge": 0.3456, "c6gn.4xlarge": 0.6912,
+	"c6gn.8xlarge": 1.3824, "c6gn.large": 0.0864, "c6gn.medium": 0.0432, "c6gn.xlarge": 0.1728,
 	// c6i family
-	"c6i.12xlarge": 2.040000, "c6i.16xlarge": 2.720000, "c6i.24xlarge": 4.080000, "c6i.2xlarge": 0.340000,
-	"c6i.32xlarge": 5.440000, "c6i.4xlarge": 0.680000, "c6i.8xlarge": 1.360000, "c6i.large": 0.085000,
-	"c6i.metal": 5.440000, "c6i.xlarge": 0.170000,
+	"c6i.12xlarge": 2.04, "c6i.16xlarge": 2.72, "c6i.24xlarge": 4.08, "c6i.2xlarge": 0.34,
+	"c6i.32xlarge": 5.44, "c6i.4xlarge": 0.68, "c6i.8xlarge": 1.36, "c6i.large": 0.085, "c6i.metal": 5.44,
+	"c6i.xlarge": 0.17,
 	// c6id family
-	"c6id.12xlarge": 2.419200, "c6id.16xlarge": 3.225600, "c6id.24xlarge": 4.838400, "c6id.2xlarge": 0.403200,
-	"c6id.32xlarge": 6.451200, "c6id.4xlarge": 0.806400, "c6id.8xlarge": 1.612800, "c6id.large": 0.100800,
-	"c6id.metal": 6.451200, "c6id.xlarge": 0.201600,
+	"c6id.12xlarge": 2.4192, "c6id.16xlarge": 3.2256, "c6id.24xlarge": 4.8384, "c6id.2xlarge": 0.4032,
+	"c6id.32xlarge": 6.4512, "c6id.4xlarge": 0.8064, "c6id.8xlarge": 1.6128, "c6id.large": 0.1008,
+	"c6id.metal": 6.4512, "c6id.xlarge": 0.2016,
 	// c6in family
-	"c6in.12xlarge": 2.721600, "c6in.16xlarge": 3.628800, "c6in.24xlarge": 5.443200, "c6in.2xlarge": 0.453600,
-	"c6in.32xlarge": 7.257600, "c6in.4xlarge": 0.907200, "c6in.8xlarge": 1.814400, "c6in.large": 0.113400,
-	"c6in.xlarge": 0.226800,
+	"c6in.12xlarge": 2.7216, "c6in.16xlarge": 3.6288, "c6in.24xlarge": 5.4432, "c6in.2xlarge": 0.4536,
+	"c6in.32xlarge": 7.2576, "c6in.4xlarge": 0.9072, "c6in.8xlarge": 1.8144, "c6in.large": 0.1134,
+	"c6in.xlarge": 0.2268,
 	// c7g family
-	"c7g.12xlarge": 1.740000, "c7g.16xlarge": 2.320000, "c7g.2xlarge": 0.290000, "c7g.4xlarge": 0.580000,
-	"c7g.8xlarge": 1.160000, "c7g.large": 0.072500, "c7g.medium": 0.036300, "c7g.xlarge": 0.145000,
+	"c7g.12xlarge": 1.74, "c7g.16xlarge": 2.32, "c7g.2xlarge": 0.29, "c7g.4xlarge": 0.58,
+	"c7g.8xlarge": 1.16, "c7g.large": 0.0725, "c7g.medium": 0.0363, "c7g.xlarge": 0.145,
 	// cc2 family
-	"cc2.8xlarge": 2.000000,
+	"cc2.8xlarge": 2,
 	// cr1 family
-	"cr1.8xlarge": 3.500000,
+	"cr1.8xlarge": 3.5,
 	// d2 family
-	"d2.2xlarge": 1.380000, "d2.4xlarge": 2.760000, "d2.8xlarge": 5.520000, "d2.xlarge": 0.690000,
+	"d2.2xlarge": 1.38, "d2.4xlarge": 2.76, "d2.8xlarge": 5.52, "d2.xlarge": 0.69,
 	// d3 family
-	"d3.2xlarge": 0.999000, "d3.4xlarge": 1.998000, "d3.8xlarge": 3.995520, "d3.xlarge": 0.499000,
+	"d3.2xlarge": 0.999, "d3.4xlarge": 1.998, "d3.8xlarge": 3.99552, "d3.xlarge": 0.499,
 	// d3en family
-	"d3en.12xlarge": 6.308640, "d3en.2xlarge": 1.051000, "d3en.4xlarge": 2.103000, "d3en.6xlarge": 3.154000,
-	"d3en.8xlarge": 4.205760, "d3en.xlarge": 0.526000,
+	"d3en.12xlarge": 6.30864, "d3en.2xlarge": 1.051, "d3en.4xlarge": 2.103, "d3en.6xlarge": 3.154,
+	"d3en.8xlarge": 4.20576, "d3en.xlarge": 0.526,
 	// dl1 family
-	"dl1.24xlarge": 13.109040,
+	"dl1.24xlarge": 13.10904,
 	// f1 family
-	"f1.16xlarge": 13.200000, "f1.2xlarge": 1.650000, "f1.4xlarge": 3.300000,
+	"f1.16xlarge": 13.2, "f1.2xlarge": 1.65, "f1.4xlarge": 3.3,
 	// g2 family
-	"g2.2xlarge": 0.650000, "g2.8xlarge": 2.600000,
+	"g2.2xlarge": 0.65, "g2.8xlarge": 2.6,
 	// g3 family
-	"g3.16xlarge": 4.560000, "g3.4xlarge": 1.140000, "g3.8xlarge": 2.280000,
+	"g3.16xlarge": 4.56, "g3.4xlarge": 1.14, "g3.8xlarge": 2.28,
 	// g3s family
-	"g3s.xlarge": 0.750000,
+	"g3s.xlarge": 0.75,
 	// g4ad family
-	"g4ad.16xlarge": 3.468000, "g4ad.2xlarge": 0.541170, "g4ad.4xlarge": 0.867000, "g4ad.8xlarge": 1.734000,
-	"g4ad.xlarge": 0.378530,
+	"g4ad.16xlarge": 3.468, "g4ad.2xlarge": 0.54117, "g4ad.4xlarge": 0.867, "g4ad.8xlarge": 1.734,
+	"g4ad.xlarge": 0.37853,
 	// g4dn family
-	"g4dn.12xlarge": 3.912000, "g4dn.16xlarge": 4.352000, "g4dn.2xlarge": 0.752000, "g4dn.4xlarge": 1.204000,
-	"g4dn.8xlarge": 2.176000, "g4dn.metal": 7.824000, "g4dn.xlarge": 0.526000,
+	"g4dn.12xlarge": 3.912, "g4dn.16xlarge": 4.352, "g4dn.2xlarge": 0.752, "g4dn.4xlarge": 1.204,
+	"g4dn.8xlarge": 2.176, "g4dn.metal": 7.824, "g4dn.xlarge": 0.526,
 	// g5 family
-	"g5.12xlarge": 5.672000, "g5.16xlarge": 4.096000, "g5.24xlarge": 8.144000, "g5.2xlarge": 1.212000,
-	"g5.48xlarge": 16.288000, "g5.4xlarge": 1.624000, "g5.8xlarge": 2.448000, "g5.xlarge": 1.006000,
+	"g5.12xlarge": 5.672, "g5.16xlarge": 4.096, "g5.24xlarge": 8.144, "g5.2xlarge": 1.212,
+	"g5.48xlarge": 16.288, "g5.4xlarge": 1.624, "g5.8xlarge": 2.448, "g5.xlarge": 1.006,
 	// g5g family
-	"g5g.16xlarge": 2.744000, "g5g.2xlarge": 0.556000, "g5g.4xlarge": 0.828000, "g5g.8xlarge": 1.372000,
-	"g5g.metal": 2.744000, "g5g.xlarge": 0.420000,
+	"g5g.16xlarge": 2.744, "g5g.2xlarge": 0.556, "g5g.4xlarge": 0.828, "g5g.8xlarge": 1.372,
+	"g5g.metal": 2.744, "g5g.xlarge": 0.42,
 	// h1 family
-	"h1.16xlarge": 3.744000, "h1.2xlarge": 0.468000, "h1.4xlarge": 0.936000, "h1.8xlarge": 1.872000,
+	"h1.16xlarge": 3.744, "h1.2xlarge": 0.468, "h1.4xlarge": 0.936, "h1.8xlarge": 1.872,
 	// hs1 family
-	"hs1.8xlarge": 4.600000,
+	"hs1.8xlarge": 4.6,
 	// i2 family
-	"i2.2xlarge": 1.705000, "i2.4xlarge": 3.410000, "i2.8xlarge": 6.820000, "i2.xlarge": 0.853000,
+	"i2.2xlarge": 1.705, "i2.4xlarge": 3.41, "i2.8xlarge": 6.82, "i2.xlarge": 0.853,
 	// i3 family
-	"i3.16xlarge": 4.992000, "i3.2xlarge": 0.624000, "i3.4xlarge": 1.248000, "i3.8xlarge": 2.496000,
-	"i3.large": 0.156000, "i3.metal": 4.992000, "i3.xlarge": 0.312000,
+	"i3.16xlarge": 4.992, "i3.2xlarge": 0.624, "i3.4xlarge": 1.248, "i3.8xlarge": 2.496,
+	"i3.large": 0.156, "i3.metal": 4.992, "i3.xlarge": 0.312,
 	// i3en family
-	"i3en.12xlarge": 5.424000, "i3en.24xlarge": 10.848000, "i3en.2xlarge": 0.904000, "i3en.3xlarge": 1.356000,
-	"i3en.6xlarge": 2.712000, "i3en.large": 0.226000, "i3en.metal": 10.848000, "i3en.xlarge": 0.452000,
+	"i3en.12xlarge": 5.424, "i3en.24xlarge": 10.848, "i3en.2xlarge": 0.904, "i3en.3xlarge": 1.356,
+	"i3en.6xlarge": 2.712, "i3en.large": 0.226, "i3en.metal": 10.848, "i3en.xlarge": 0.452,
 	// i4i family
-	"i4i.16xlarge": 5.491000, "i4i.2xlarge": 0.686000, "i4i.32xlarge": 10.982400, "i4i.4xlarge": 1.373000,
-	"i4i.8xlarge": 2.746000, "i4i.large": 0.172000, "i4i.metal": 10.982000, "i4i.xlarge": 0.343000,
+	"i4i.16xlarge": 5.491, "i4i.2xlarge": 0.686, "i4i.32xlarge": 10.9824, "i4i.4xlarge": 1.373,
+	"i4i.8xlarge": 2.746, "i4i.large": 0.172, "i4i.metal": 10.982, "i4i.xlarge": 0.343,
 	// im4gn family
-	"im4gn.16xlarge": 5.820670, "im4gn.2xlarge": 0.727580, "im4gn.4xlarge": 1.455170, "im4gn.8xlarge": 2.910340,
-	"im4gn.large": 0.181900, "im4gn.xlarge": 0.363790,
+	"im4gn.16xlarge": 5.82067, "im4gn.2xlarge": 0.72758, "im4gn.4xlarge": 1.45517, "im4gn.8xlarge": 2.91034,
+	"im4gn.large": 0.1819, "im4gn.xlarge": 0.36379,
 	// inf1 family
-	"inf1.24xlarge": 4.721000, "inf1.2xlarge": 0.362000, "inf1.6xlarge": 1.180000, "inf1.xlarge": 0.228000,
+	"inf1.24xlarge": 4.721, "inf1.2xlarge": 0.362, "inf1.6xlarge": 1.18, "inf1.xlarge": 0.228,
 	// is4gen family
-	"is4gen.2xlarge": 1.152600, "is4gen.4xlarge": 2.305200, "is4gen.8xlarge": 4.610400,
-	"is4gen.large": 0.288150, "is4gen.medium": 0.144080, "is4gen.xlarge": 0.576300,
+	"is4gen.2xlarge": 1.1526, "is4gen.4xlarge": 2.3052, "is4gen.8xlarge": 4.6104, "is4gen.large": 0.28815,
+	"is4gen.medium": 0.14408, "is4gen.xlarge": 0.5763,
 	// m1 family
-	"m1.large": 0.175000, "m1.medium": 0.087000, "m1.small": 0.044000, "m1.xlarge": 0.350000,
+	"m1.large": 0.175, "m1.medium": 0.087, "m1.small": 0.044, "m1.xlarge": 0.35,
 	// m2 family
-	"m2.2xlarge": 0.490000, "m2.4xlarge": 0.980000, "m2.xlarge": 0.245000,
+	"m2.2xlarge": 0.49, "m2.4xlarge": 0.98, "m2.xlarge": 0.245,
 	// m3 family
-	"m3.2xlarge": 0.532000, "m3.large": 0.133000, "m3.medium": 0.067000, "m3.xlarge": 0.266000,
+	"m3.2xlarge": 0.532, "m3.large": 0.133, "m3.medium": 0.067, "m3.xlarge": 0.266,
 	// m4 family
-	"m4.10xlarge": 2.000000, "m4.16xlarge": 3.200000, "m4.2xlarge": 0.400000, "m4.4xlarge": 0.800000,
-	"m4.large": 0.100000, "m4.xlarge": 0.200000,
+	"m4.10xlarge": 2, "m4.16xlarge": 3.2, "m4.2xlarge": 0.4, "m4.4xlarge": 0.8, "m4.large": 0.1,
+	"m4.xlarge": 0.2,
 	// m5 family
-	"m5.12xlarge": 2.304000, "m5.16xlarge": 3.072000, "m5.24xlarge": 4.608000, "m5.2xlarge": 0.384000,
-	"m5.4xlarge": 0.768000, "m5.8xlarge": 1.536000, "m5.large": 0.096000, "m5.metal": 4.608000,
-	"m5.xlarge": 0.192000,
+	"m5.12xlarge": 2.304, "m5.16xlarge": 3.072, "m5.24xlarge": 4.608, "m5.2xlarge": 0.384,
+	"m5.4xlarge": 0.768, "m5.8xlarge": 1.536, "m5.large": 0.096, "m5.metal": 4.608, "m5.xlarge": 0.192,
 	// m5a family
-	"m5a.12xlarge": 2.064000, "m5a.16xlarge": 2.752000, "m5a.24xlarge": 4.128000, "m5a.2xlarge": 0.344000,
-	"m5a.4xlarge": 0.688000, "m5a.8xlarge": 1.376000, "m5a.large": 0.086000, "m5a.xlarge": 0.172000,
+	"m5a.12xlarge": 2.064, "m5a.16xlarge": 2.752, "m5a.24xlarge": 4.128, "m5a.2xlarge": 0.344,
+	"m5a.4xlarge": 0.688, "m5a.8xlarge": 1.376, "m5a.large": 0.086, "m5a.xlarge": 0.172,
 	// m5ad family
-	"m5ad.12xlarge": 2.472000, "m5ad.16xlarge": 3.296000, "m5ad.24xlarge": 4.944000, "m5ad.2xlarge": 0.412000,
-	"m5ad.4xlarge": 0.824000, "m5ad.8xlarge": 1.648000, "m5ad.large": 0.103000, "m5ad.xlarge": 0.206000,
+	"m5ad.12xlarge": 2.472, "m5ad.16xlarge": 3.296, "m5ad.24xlarge": 4.944, "m5ad.2xlarge": 0.412,
+	"m5ad.4xlarge": 0.824, "m5ad.8xlarge": 1.648, "m5ad.large": 0.103, "m5ad.xlarge": 0.206,
 	// m5d family
-	"m5d.12xlarge": 2.712000, "m5d.16xlarge": 3.616000, "m5d.24xlarge": 5.424000, "m5d.2xlarge": 0.452000,
-	"m5d.4xlarge": 0.904000, "m5d.8xlarge": 1.808000, "m5d.large": 0.113000, "m5d.metal": 5.424000,
-	"m5d.xlarge": 0.226000,
+	"m5d.12xlarge": 2.712, "m5d.16xlarge": 3.616, "m5d.24xlarge": 5.424, "m5d.2xlarge": 0.452,
+	"m5d.4xlarge": 0.904, "m5d.8xlarge": 1.808, "m5d.large": 0.113, "m5d.metal": 5.424, "m5d.xlarge": 0.226,
 	// m5dn family
-	"m5dn.12xlarge": 3.264000, "m5dn.16xlarge": 4.352000, "m5dn.24xlarge": 6.528000, "m5dn.2xlarge": 0.544000,
-	"m5dn.4xlarge": 1.088000, "m5dn.8xlarge": 2.176000, "m5dn.large": 0.136000, "m5dn.metal": 6.528000,
-	"m5dn.xlarge": 0.272000,
+	"m5dn.12xlarge": 3.264, "m5dn.16xlarge": 4.352, "m5dn.24xlarge": 6.528, "m5dn.2xlarge": 0.544,
+	"m5dn.4xlarge": 1.088, "m5dn.8xlarge": 2.176, "m5dn.large": 0.136, "m5dn.metal": 6.528,
+	"m5dn.xlarge": 0.272,
 	// m5n family
-	"m5n.12xlarge": 2.856000, "m5n.16xlarge": 3.808000, "m5n.24xlarge": 5.712000, "m5n.2xlarge": 0.476000,
-	"m5n.4xlarge": 0.952000, "m5n.8xlarge": 1.904000, "m5n.large": 0.119000, "m5n.metal": 5.712000,
-	"m5n.xlarge": 0.238000,
+	"m5n.12xlarge": 2.856, "m5n.16xlarge": 3.808, "m5n.24xlarge": 5.712, "m5n.2xlarge": 0.476,
+	"m5n.4xlarge": 0.952, "m5n.8xlarge": 1.904, "m5n.large": 0.119, "m5n.metal": 5.712, "m5n.xlarge": 0.238,
 	// m5zn family
-	"m5zn.12xlarge": 3.964100, "m5zn.2xlarge": 0.660700, "m5zn.3xlarge": 0.991000, "m5zn.6xlarge": 1.982000,
-	"m5zn.large": 0.165200, "m5zn.metal": 4.360500, "m5zn.xlarge": 0.330300,
+	"m5zn.12xlarge": 3.9641, "m5zn.2xlarge": 0.6607, "m5zn.3xlarge": 0.991, "m5zn.6xlarge": 1.982,
+	"m5zn.large": 0.1652, "m5zn.metal": 4.3605, "m5zn.xlarge": 0.3303,
 	// m6a family
-	"m6a.12xlarge": 2.073600, "m6a.16xlarge": 2.764800, "m6a.24xlarge": 4.147200, "m6a.2xlarge": 0.345600,
-	"m6a.32xlarge": 5.529600, "m6a.48xlarge": 8.294400, "m6a.4xlarge": 0.691200, "m6a.8xlarge": 1.382400,
-	"m6a.large": 0.086400, "m6a.metal": 8.294400, "m6a.xlarge": 0.172800,
+	"m6a.12xlarge": 2.0736, "m6a.16xlarge": 2.7648, "m6a.24xlarge": 4.1472, "m6a.2xlarge": 0.3456,
+	"m6a.32xlarge": 5.5296, "m6a.48xlarge": 8.2944, "m6a.4xlarge": 0.6912, "m6a.8xlarge": 1.3824,
+	"m6a.large": 0.0864, "m6a.metal": 8.2944, "m6a.xlarge": 0.1728,
 	// m6g family
-	"m6g.12xlarge": 1.848000, "m6g.16xlarge": 2.464000, "m6g.2xlarge": 0.308000, "m6g.4xlarge": 0.616000,
-	"m6g.8xlarge": 1.232000, "m6g.large": 0.077000, "m6g.medium": 0.038500, "m6g.metal": 2.611200,
-	"m6g.xlarge": 0.154000,
+	"m6g.12xlarge": 1.848, "m6g.16xlarge": 2.464, "m6g.2xlarge": 0.308, "m6g.4xlarge": 0.616,
+	"m6g.8xlarge": 1.232, "m6g.large": 0.077, "m6g.medium": 0.0385, "m6g.metal": 2.6112,
+	"m6g.xlarge": 0.154,
 	// m6gd family
-	"m6gd.12xlarge": 2.169600, "m6gd.16xlarge": 2.892800, "m6gd.2xlarge": 0.361600, "m6gd.4xlarge": 0.723200,
-	"m6gd.8xlarge": 1.446400, "m6gd.large": 0.090400, "m6gd.medium": 0.045200, "m6gd.metal": 3.066400,
-	"m6gd.xlarge": 0.180800,
+	"m6gd.12xlarge": 2.1696, "m6gd.16xlarge": 2.8928, "m6gd.2xlarge": 0.3616, "m6gd.4xlarge": 0.7232,
+	"m6gd.8xlarge": 1.4464, "m6gd.large": 0.0904, "m6gd.medium": 0.0452, "m6gd.metal": 3.0664,
+	"m6gd.xlarge": 0.1808,
 	// m6i family
-	"m6i.12xlarge": 2.304000, "m6i.16xlarge": 3.072000, "m6i.24xlarge": 4.608000, "m6i.2xlarge": 0.384000,
-	"m6i.32xlarge": 6.144000, "m6i.4xlarge": 0.768000, "m6i.8xlarge": 1.536000, "m6i.large": 0.096000,
-	"m6i.metal": 6.144000, "m6i.xlarge": 0.192000,
+	"m6i.12xlarge": 2.304, "m6i.16xlarge": 3.072, "m6i.24xlarge": 4.608, "m6i.2xlarge": 0.384,
+	"m6i.32xlarge": 6.144, "m6i.4xlarge": 0.768, "m6i.8xlarge": 1.536, "m6i.large": 0.096,
+	"m6i.metal": 6.144, "m6i.xlarge": 0.192,
 	// m6id family
-	"m6id.12xlarge": 2.847600, "m6id.16xlarge": 3.796800, "m6id.24xlarge": 5.695200, "m6id.2xlarge": 0.474600,
-	"m6id.32xlarge": 7.593600, "m6id.4xlarge": 0.949200, "m6id.8xlarge": 1.898400, "m6id.large": 0.118650,
-	"m6id.metal": 7.593600, "m6id.xlarge": 0.237300,
+	"m6id.12xlarge": 2.8476, "m6id.16xlarge": 3.7968, "m6id.24xlarge": 5.6952, "m6id.2xlarge": 0.4746,
+	"m6id.32xlarge": 7.5936, "m6id.4xlarge": 0.9492, "m6id.8xlarge": 1.8984, "m6id.large": 0.11865,
+	"m6id.metal": 7.5936, "m6id.xlarge": 0.2373,
 	// m6idn family
-	"m6idn.12xlarge": 3.818880, "m6idn.16xlarge": 5.091840, "m6idn.24xlarge": 7.637760,
-	"m6idn.2xlarge": 0.636480, "m6idn.32xlarge": 10.183680, "m6idn.4xlarge": 1.272960, "m6idn.8xlarge": 2.545920,
-	"m6idn.large": 0.159120, "m6idn.xlarge": 0.318240,
+	"m6idn.12xlarge": 3.81888, "m6idn.16xlarge": 5.09184, "m6idn.24xlarge": 7.63776, "m6idn.2xlarge": 0.63648,
+	"m6idn.32xlarge": 10.18368, "m6idn.4xlarge": 1.27296, "m6idn.8xlarge": 2.54592, "m6idn.large": 0.15912,
+	"m6idn.xlarge": 0.31824,
 	// m6in family
-	"m6in.12xlarge": 3.341520, "m6in.16xlarge": 4.455360, "m6in.24xlarge": 6.683040, "m6in.2xlarge": 0.556920,
-	"m6in.32xlarge": 8.910720, "m6in.4xlarge": 1.113840, "m6in.8xlarge": 2.227680, "m6in.large": 0.139230,
-	"m6in.xlarge": 0.278460,
+	"m6in.12xlarge": 3.34152, "m6in.16xlarge": 4.45536, "m6in.24xlarge": 6.68304, "m6in.2xlarge": 0.55692,
+	"m6in.32xlarge": 8.91072, "m6in.4xlarge": 1.11384, "m6in.8xlarge": 2.22768, "m6in.large": 0.13923,
+	"m6in.xlarge": 0.27846,
 	// p2 family
-	"p2.16xlarge": 14.400000, "p2.8xlarge": 7.200000, "p2.xlarge": 0.900000,
+	"p2.16xlarge": 14.4, "p2.8xlarge": 7.2, "p2.xlarge": 0.9,
 	// p3 family
-	"p3.16xlarge": 24.480000, "p3.2xlarge": 3.060000, "p3.8xlarge": 12.240000,
+	"p3.16xlarge": 24.48, "p3.2xlarge": 3.06, "p3.8xlarge": 12.24,
 	// p3dn family
-	"p3dn.24xlarge": 31.212000,
+	"p3dn.24xlarge": 31.212,
 	// p4d family
-	"p4d.24xlarge": 32.772600,
+	"p4d.24xlarge": 32.7726,
 	// p4de family
-	"p4de.24xlarge": 40.965750,
+	"p4de.24xlarge": 40.96575,
 	// r3 family
-	"r3.2xlarge": 0.665000, "r3.4xlarge": 1.330000, "r3.8xlarge": 2.660000, "r3.large": 0.166000,
-	"r3.xlarge": 0.333000,
+	"r3.2xlarge": 0.665, "r3.4xlarge": 1.33, "r3.8xlarge": 2.66, "r3.large": 0.166, "r3.xlarge": 0.333,
 	// r4 family
-	"r4.16xlarge": 4.256000, "r4.2xlarge": 0.532000, "r4.4xlarge": 1.064000, "r4.8xlarge": 2.128000,
-	"r4.large": 0.133000, "r4.xlarge": 0.266000,
+	"r4.16xlarge": 4.256, "r4.2xlarge": 0.532, "r4.4xlarge": 1.064, "r4.8xlarge": 2.128,
+	"r4.large": 0.133, "r4.xlarge": 0.266,
 	// r5 family
-	"r5.12xlarge": 3.024000, "r5.16xlarge": 4.032000, "r5.24xlarge": 6.048000, "r5.2xlarge": 0.504000,
-	"r5.4xlarge": 1.008000, "r5.8xlarge": 2.016000, "r5.large": 0.126000, "r5.metal": 6.048000,
-	"r5.xlarge": 0.252000,
+	"r5.12xlarge": 3.024, "r5.16xlarge": 4.032, "r5.24xlarge": 6.048, "r5.2xlarge": 0.504,
+	"r5.4xlarge": 1.008, "r5.8xlarge": 2.016, "r5.large": 0.126, "r5.metal": 6.048, "r5.xlarge": 0.252,
 	// r5a family
-	"r5a.12xlarge": 2.712000, "r5a.16xlarge": 3.616000, "r5a.24xlarge": 5.424000, "r5a.2xlarge": 0.452000,
-	"r5a.4xlarge": 0.904000, "r5a.8xlarge": 1.808000, "r5a.large": 0.113000, "r5a.xlarge": 0.226000,
+	"r5a.12xlarge": 2.712, "r5a.16xlarge": 3.616, "r5a.24xlarge": 5.424, "r5a.2xlarge": 0.452,
+	"r5a.4xlarge": 0.904, "r5a.8xlarge": 1.808, "r5a.large": 0.113, "r5a.xlarge": 0.226,
 	// r5ad family
-	"r5ad.12xlarge": 3.144000, "r5ad.16xlarge": 4.192000, "r5ad.24xlarge": 6.288000, "r5ad.2xlarge": 0.524000,
-	"r5ad.4xlarge": 1.048000, "r5ad.8xlarge": 2.096000, "r5ad.large": 0.131000, "r5ad.xlarge": 0.262000,
+	"r5ad.12xlarge": 3.144, "r5ad.16xlarge": 4.192, "r5ad.24xlarge": 6.288, "r5ad.2xlarge": 0.524,
+	"r5ad.4xlarge": 1.048, "r5ad.8xlarge": 2.096, "r5ad.large": 0.131, "r5ad.xlarge": 0.262,
 	// r5b family
-	"r5b.12xlarge": 3.576000, "r5b.16xlarge": 4.768000, "r5b.24xlarge": 7.152000, "r5b.2xlarge": 0.596000,
-	"r5b.4xlarge": 1.192000, "r5b.8xlarge": 2.384000, "r5b.large": 0.149000, "r5b.metal": 7.867200,
-	"r5b.xlarge": 0.298000,
+	"r5b.12xlarge": 3.576, "r5b.16xlarge": 4.768, "r5b.24xlarge": 7.152, "r5b.2xlarge": 0.596,
+	"r5b.4xlarge": 1.192, "r5b.8xlarge": 2.384, "r5b.large": 0.149, "r5b.metal": 7.8672,
+	"r5b.xlarge": 0.298,
 	// r5d family
-	"r5d.12xlarge": 3.456000, "r5d.16xlarge": 4.608000, "r5d.24xlarge": 6.912000, "r5d.2xlarge": 0.576000,
-	"r5d.4xlarge": 1.152000, "r5d.8xlarge": 2.304000, "r5d.large": 0.144000, "r5d.metal": 6.912000,
-	"r5d.xlarge": 0.288000,
+	"r5d.12xlarge": 3.456, "r5d.16xlarge": 4.608, "r5d.24xlarge": 6.912, "r5d.2xlarge": 0.576,
+	"r5d.4xlarge": 1.152, "r5d.8xlarge": 2.304, "r5d.large": 0.144, "r5d.metal": 6.912, "r5d.xlarge": 0.288,
 	// r5dn family
-	"r5dn.12xlarge": 4.008000, "r5dn.16xlarge": 5.344000, "r5dn.24xlarge": 8.016000, "r5dn.2xlarge": 0.668000,
-	"r5dn.4xlarge": 1.336000, "r5dn.8xlarge": 2.672000, "r5dn.large": 0.167000, "r5dn.metal": 8.016000,
-	"r5dn.xlarge": 0.334000,
+	"r5dn.12xlarge": 4.008, "r5dn.16xlarge": 5.344, "r5dn.24xlarge": 8.016, "r5dn.2xlarge": 0.668,
+	"r5dn.4xlarge": 1.336, "r5dn.8xlarge": 2.672, "r5dn.large": 0.167, "r5dn.metal": 8.016,
+	"r5dn.xlarge": 0.334,
 	// r5n family
-	"r5n.12xlarge": 3.576000, "r5n.16xlarge": 4.768000, "r5n.24xlarge": 7.152000, "r5n.2xlarge": 0.596000,
-	"r5n.4xlarge": 1.192000, "r5n.8xlarge": 2.384000, "r5n.large": 0.149000, "r5n.metal": 7.152000,
-	"r5n.xlarge": 0.298000,
+	"r5n.12xlarge": 3.576, "r5n.16xlarge": 4.768, "r5n.24xlarge": 7.152, "r5n.2xlarge": 0.596,
+	"r5n.4xlarge": 1.192, "r5n.8xlarge": 2.384, "r5n.large": 0.149, "r5n.metal": 7.152, "r5n.xlarge": 0.298,
 	// r6a family
-	"r6a.12xlarge": 2.721600, "r6a.16xlarge": 3.628800, "r6a.24xlarge": 5.443200, "r6a.2xlarge": 0.453600,
-	"r6a.32xlarge": 7.257600, "r6a.48xlarge": 10.886400, "r6a.4xlarge": 0.907200, "r6a.8xlarge": 1.814400,
-	"r6a.large": 0.113400, "r6a.metal": 10.886400, "r6a.xlarge": 0.226800,
+	"r6a.12xlarge": 2.7216, "r6a.16xlarge": 3.6288, "r6a.24xlarge": 5.4432, "r6a.2xlarge": 0.4536,
+	"r6a.32xlarge": 7.2576, "r6a.48xlarge": 10.8864, "r6a.4xlarge": 0.9072, "r6a.8xlarge": 1.8144,
+	"r6a.large": 0.1134, "r6a.metal": 10.8864, "r6a.xlarge": 0.2268,
 	// r6g family
-	"r6g.12xlarge": 2.419200, "r6g.16xlarge": 3.225600, "r6g.2xlarge": 0.403200, "r6g.4xlarge": 0.806400,
-	"r6g.8xlarge": 1.612800, "r6g.large": 0.100800, "r6g.medium": 0.050400, "r6g.metal": 3.419100,
-	"r6g.xlarge": 0.201600,
+	"r6g.12xlarge": 2.4192, "r6g.16xlarge": 3.2256, "r6g.2xlarge": 0.4032, "r6g.4xlarge": 0.8064,
+	"r6g.8xlarge": 1.6128, "r6g.large": 0.1008, "r6g.medium": 0.0504, "r6g.metal": 3.4191,
+	"r6g.xlarge": 0.2016,
 	// r6gd family
-	"r6gd.12xlarge": 2.764800, "r6gd.16xlarge": 3.686400, "r6gd.2xlarge": 0.460800, "r6gd.4xlarge": 0.921600,
-	"r6gd.8xlarge": 1.843200, "r6gd.large": 0.115200, "r6gd.medium": 0.057600, "r6gd.metal": 3.907600,
-	"r6gd.xlarge": 0.230400,
+	"r6gd.12xlarge": 2.7648, "r6gd.16xlarge": 3.6864, "r6gd.2xlarge": 0.4608, "r6gd.4xlarge": 0.9216,
+	"r6gd.8xlarge": 1.8432, "r6gd.large": 0.1152, "r6gd.medium": 0.0576, "r6gd.metal": 3.9076,
+	"r6gd.xlarge": 0.2304,
 	// r6i family
-	"r6i.12xlarge": 3.024000, "r6i.16xlarge": 4.032000, "r6i.24xlarge": 6.048000, "r6i.2xlarge": 0.504000,
-	"r6i.32xlarge": 8.064000, "r6i.4xlarge": 1.008000, "r6i.8xlarge": 2.016000, "r6i.large": 0.126000,
-	"r6i.metal": 8.064000, "r6i.xlarge": 0.252000,
+	"r6i.12xlarge": 3.024, "r6i.16xlarge": 4.032, "r6i.24xlarge": 6.048, "r6i.2xlarge": 0.504,
+	"r6i.32xlarge": 8.064, "r6i.4xlarge": 1.008, "r6i.8xlarge": 2.016, "r6i.large": 0.126,
+	"r6i.metal": 8.064, "r6i.xlarge": 0.252,
 	// r6id family
-	"r6id.12xlarge": 3.628800, "r6id.16xlarge": 4.838400, "r6id.24xlarge": 7.257600, "r6id.2xlarge": 0.604800,
-	"r6id.32xlarge": 9.676800, "r6id.4xlarge": 1.209600, "r6id.8xlarge": 2.419200, "r6id.large": 0.151200,
-	"r6id.metal": 9.676800, "r6id.xlarge": 0.302400,
+	"r6id.12xlarge": 3.6288, "r6id.16xlarge": 4.8384, "r6id.24xlarge": 7.2576, "r6id.2xlarge": 0.6048,
+	"r6id.32xlarge": 9.6768, "r6id.4xlarge": 1.2096, "r6id.8xlarge": 2.4192, "r6id.large": 0.1512,
+	"r6id.metal": 9.6768, "r6id.xlarge": 0.3024,
 	// r6idn family
-	"r6idn.12xlarge": 4.689360, "r6idn.16xlarge": 6.252480, "r6idn.24xlarge": 9.378720,
-	"r6idn.2xlarge": 0.781560, "r6idn.32xlarge": 12.504960, "r6idn.4xlarge": 1.563120, "r6idn.8xlarge": 3.126240,
-	"r6idn.large": 0.195390, "r6idn.xlarge": 0.390780,
+	"r6idn.12xlarge": 4.68936, "r6idn.16xlarge": 6.25248, "r6idn.24xlarge": 9.37872, "r6idn.2xlarge": 0.78156,
+	"r6idn.32xlarge": 12.50496, "r6idn.4xlarge": 1.56312, "r6idn.8xlarge": 3.12624, "r6idn.large": 0.19539,
+	"r6idn.xlarge": 0.39078,
 	// r6in family
-	"r6in.12xlarge": 4.183920, "r6in.16xlarge": 5.578560, "r6in.24xlarge": 8.367840, "r6in.2xlarge": 0.697320,
-	"r6in.32xlarge": 11.157120, "r6in.4xlarge": 1.394640, "r6in.8xlarge": 2.789280, "r6in.large": 0.174330,
-	"r6in.xlarge": 0.348660,
+	"r6in.12xlarge": 4.18392, "r6in.16xlarge": 5.57856, "r6in.24xlarge": 8.36784, "r6in.2xlarge": 0.69732,
+	"r6in.32xlarge": 11.15712, "r6in.4xlarge": 1.39464, "r6in.8xlarge": 2.78928, "r6in.large": 0.17433,
+	"r6in.xlarge": 0.34866,
 	// t1 family
-	"t1.micro": 0.020000,
+	"t1.micro": 0.02,
 	// t2 family
-	"t2.2xlarge": 0.371200, "t2.large": 0.092800, "t2.medium": 0.046400, "t2.micro": 0.011600,
-	"t2.nano": 0.005800, "t2.small": 0.023000, "t2.xlarge": 0.185600,
+	"t2.2xlarge": 0.3712, "t2.large": 0.0928, "t2.medium": 0.0464, "t2.micro": 0.0116, "t2.nano": 0.0058,
+	"t2.small": 0.023, "t2.xlarge": 0.1856,
 	// t3 family
-	"t3.2xlarge": 0.332800, "t3.large": 0.083200, "t3.medium": 0.041600, "t3.micro": 0.010400,
-	"t3.nano": 0.005200, "t3.small": 0.020800, "t3.xlarge": 0.166400,
+	"t3.2xlarge": 0.3328, "t3.large": 0.0832, "t3.medium": 0.0416, "t3.micro": 0.0104, "t3.nano": 0.0052,
+	"t3.small": 0.0208, "t3.xlarge": 0.1664,
 	// t3a family
-	"t3a.2xlarge": 0.300800, "t3a.large": 0.075200, "t3a.medium": 0.037600, "t3a.micro": 0.009400,
-	"t3a.nano": 0.004700, "t3a.small": 0.018800, "t3a.xlarge": 0.150400,
+	"t3a.2xlarge": 0.3008, "t3a.large": 0.0752, "t3a.medium": 0.0376, "t3a.micro": 0.0094,
+	"t3a.nano": 0.0047, "t3a.small": 0.0188, "t3a.xlarge": 0.1504,
 	// t4g family
-	"t4g.2xlarge": 0.268800, "t4g.large": 0.067200, "t4g.medium": 0.033600, "t4g.micro": 0.008400,
-	"t4g.nano": 0.004200, "t4g.small": 0.016800, "t4g.xlarge": 0.134400,
+	"t4g.2xlarge": 0.2688, "t4g.large": 0.0672, "t4g.medium": 0.0336, "t4g.micro": 0.0084,
+	"t4g.nano": 0.0042, "t4g.small": 0.0168, "t4g.xlarge": 0.1344,
 	// trn1 family
-	"trn1.2xlarge": 1.343750, "trn1.32xlarge": 21.500000,
+	"trn1.2xlarge": 1.34375, "trn1.32xlarge": 21.5,
 	// u-12tb1 family
-	"u-12tb1.112xlarge": 109.200000,
+	"u-12tb1.112xlarge": 109.2,
 	// u-18tb1 family
-	"u-18tb1.112xlarge": 163.800000,
+	"u-18tb1.112xlarge": 163.8,
 	// u-24tb1 family
-	"u-24tb1.112xlarge": 218.400000,
+	"u-24tb1.112xlarge": 218.4,
 	// u-3tb1 family
-	"u-3tb1.56xlarge": 27.300000,
+	"u-3tb1.56xlarge": 27.3,
 	// u-6tb1 family
-	"u-6tb1.112xlarge": 54.600000, "u-6tb1.56xlarge": 46.403910,
+	"u-6tb1.112xlarge": 54.6, "u-6tb1.56xlarge": 46.40391,
 	// u-9tb1 family
-	"u-9tb1.112xlarge": 81.900000,
+	"u-9tb1.112xlarge": 81.9,
 	// vt1 family
-	"vt1.24xlarge": 5.200000, "vt1.3xlarge": 0.650000, "vt1.6xlarge": 1.300000,
+	"vt1.24xlarge": 5.2, "vt1.3xlarge": 0.65, "vt1.6xlarge": 1.3,
 	// x1 family
-	"x1.16xlarge": 6.669000, "x1.32xlarge": 13.338000,
+	"x1.16xlarge": 6.669, "x1.32xlarge": 13.338,
 	// x1e family
-	"x1e.16xlarge": 13.344000, "x1e.2xlarge": 1.668000, "x1e.32xlarge": 26.688000, "x1e.4xlarge": 3.336000,
-	"x1e.8xlarge": 6.672000, "x1e.xlarge": 0.834000,
+	"x1e.16xlarge": 13.344, "x1e.2xlarge": 1.668, "x1e.32xlarge": 26.688, "x1e.4xlarge": 3.336,
+	"x1e.8xlarge": 6.672, "x1e.xlarge": 0.834,
 	// x2gd family
-	"x2gd.12xlarge": 4.008000, "x2gd.16xlarge": 5.344000, "x2gd.2xlarge": 0.668000, "x2gd.4xlarge": 1.336000,
-	"x2gd.8xlarge": 2.672000, "x2gd.large": 0.167000, "x2gd.medium": 0.083500, "x2gd.metal": 5.878400,
-	"x2gd.xlarge": 0.334000,
+	"x2gd.12xlarge": 4.008, "x2gd.16xlarge": 5.344, "x2gd.2xlarge": 0.668, "x2gd.4xlarge": 1.336,
+	"x2gd.8xlarge": 2.672, "x2gd.large": 0.167, "x2gd.medium": 0.0835, "x2gd.metal": 5.8784,
+	"x2gd.xlarge": 0.334,
 	// x2idn family
-	"x2idn.16xlarge": 6.669000, "x2idn.24xlarge": 10.003500, "x2idn.32xlarge": 13.338000,
-	"x2idn.metal": 13.338000,
+	"x2idn.16xlarge": 6.669, "x2idn.24xlarge": 10.0035, "x2idn.32xlarge": 13.338, "x2idn.metal": 13.338,
 	// x2iedn family
-	"x2iedn.16xlarge": 13.338000, "x2iedn.24xlarge": 20.007000, "x2iedn.2xlarge": 1.667250,
-	"x2iedn.32xlarge": 26.676000, "x2iedn.4xlarge": 3.334500, "x2iedn.8xlarge": 6.669000,
-	"x2iedn.metal": 26.676000, "x2iedn.xlarge": 0.833630,
+	"x2iedn.16xlarge": 13.338, "x2iedn.24xlarge": 20.007, "x2iedn.2xlarge": 1.66725, "x2iedn.32xlarge": 26.676,
+	"x2iedn.4xlarge": 3.3345, "x2iedn.8xlarge": 6.669, "x2iedn.metal": 26.676, "x2iedn.xlarge": 0.83363,
 	// x2iezn family
-	"x2iezn.12xlarge": 10.008000, "x2iezn.2xlarge": 1.668000, "x2iezn.4xlarge": 3.336000,
-	"x2iezn.6xlarge": 5.004000, "x2iezn.8xlarge": 6.672000, "x2iezn.metal": 10.008000,
+	"x2iezn.12xlarge": 10.008, "x2iezn.2xlarge": 1.668, "x2iezn.4xlarge": 3.336, "x2iezn.6xlarge": 5.004,
+	"x2iezn.8xlarge": 6.672, "x2iezn.metal": 10.008,
 	// z1d family
-	"z1d.12xlarge": 4.464000, "z1d.2xlarge": 0.744000, "z1d.3xlarge": 1.116000, "z1d.6xlarge": 2.232000,
-	"z1d.large": 0.186000, "z1d.metal": 4.464000, "z1d.xlarge": 0.372000,
+	"z1d.12xlarge": 4.464, "z1d.2xlarge": 0.744, "z1d.3xlarge": 1.116, "z1d.6xlarge": 2.232,
+	"z1d.large": 0.186, "z1d.metal": 4.464, "z1d.xlarge": 0.372,
 }